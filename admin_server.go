@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	healthcheck "github.com/sliide/service-healthcheck"
+	"github.com/sliide/template-grpc-service/internal/admin"
+	"github.com/sliide/template-grpc-service/internal/configs"
+	"github.com/sliide/template-grpc-service/internal/grpcd"
+)
+
+// operations holds the state of the running instance shared by the monitoring endpoints and the admin server.
+type operations struct {
+	// isReady is the readiness reported to k8s.
+	isReady *atomic.Value
+	// hc runs the health checks of the instance.
+	hc healthcheck.HealthChecker
+
+	// drained is closed when the drain is triggered by admin.
+	drained   chan struct{}
+	drainOnce sync.Once
+}
+
+func newOperations(sys configs.Config, s *grpcd.Server) *operations {
+	isReady := &atomic.Value{}
+	isReady.Store(false)
+
+	hc := healthcheck.New(healthcheck.Params{
+		Service:     sys.Service,
+		Environment: sys.Env,
+		Version:     Version,
+	})
+	hc.AddCheck("http server", healthcheck.DaemonServingCheck(s))
+
+	return &operations{
+		isReady: isReady,
+		hc:      hc,
+		drained: make(chan struct{}),
+	}
+}
+
+// drain stops the instance gracefully, it can be called several times.
+func (o *operations) drain() {
+	o.drainOnce.Do(func() {
+		close(o.drained)
+	})
+}
+
+// initAdminServer returns the server of the Admin gRPC service, or nil if no admin token is set.
+func initAdminServer(sys configs.Config, res *resources, ops *operations) *admin.Server {
+	if sys.AdminToken == "" {
+		return nil
+	}
+
+	service := admin.NewService(admin.Params{
+		BuildInfo: admin.BuildInfo{
+			Version:     Version,
+			GitRevision: GitRevision,
+			GitBranch:   GitBranch,
+			GoVersion:   runtime.Version(),
+		},
+		Config:        sys.Redacted(),
+		Logger:        logrus.StandardLogger(),
+		Readiness:     ops.isReady,
+		Drain:         ops.drain,
+		HealthChecker: ops.hc,
+		Registry:      res.inflight,
+		Tenancy:       res.tenancy,
+		Chaos:         res.chaos,
+	})
+
+	l := logrus.NewEntry(logrus.StandardLogger())
+	logrus.WithField("listen_addr", sys.AdminListenAddr).Info("Starting admin server")
+
+	return admin.NewServer(admin.ServerParams{
+		ListenAddr: sys.AdminListenAddr,
+		Token:      sys.AdminToken,
+		Logger:     l.WithField("service_version", fmt.Sprintf("%s (%s)", Version, runtime.Version())),
+	}, service)
+}
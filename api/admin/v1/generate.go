@@ -0,0 +1,4 @@
+// Package adminv1 contains the generated code of the Admin service.
+package adminv1
+
+//go:generate protoc -I ../.. --go_out=paths=source_relative:../.. --go-grpc_out=paths=source_relative:../.. admin/v1/admin.proto
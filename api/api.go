@@ -0,0 +1,43 @@
+// Package api embeds the descriptors of the proto API of the service, generated with `make descriptors`.
+package api
+
+import (
+	_ "embed" // Embeds the descriptor set
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	// Registers the generated types of the API resolved by Services
+	_ "github.com/sliide/template-grpc-service/api/admin/v1"
+	_ "github.com/sliide/template-grpc-service/api/template/v1"
+	_ "github.com/sliide/template-grpc-service/api/template/v2"
+)
+
+// Descriptors is the serialized FileDescriptorSet of the proto files of the API, without their imports.
+//
+//go:embed descriptors.pb
+var Descriptors []byte
+
+// Services returns the services of the API, resolved from the registered Go types of the proto files.
+func Services() ([]protoreflect.ServiceDescriptor, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(Descriptors, set); err != nil {
+		return nil, fmt.Errorf("malformed descriptor set: %w", err)
+	}
+
+	var services []protoreflect.ServiceDescriptor
+	for _, f := range set.GetFile() {
+		fd, err := protoregistry.GlobalFiles.FindFileByPath(f.GetName())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", f.GetName(), err)
+		}
+		for i := 0; i < fd.Services().Len(); i++ {
+			services = append(services, fd.Services().Get(i))
+		}
+	}
+
+	return services, nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	adminv1 "github.com/sliide/template-grpc-service/api/admin/v1"
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+	"github.com/sliide/template-grpc-service/internal/breaking"
+)
+
+// compiled returns the descriptor set of the proto files compiled into the generated Go types.
+func compiled() *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range []protoreflect.FileDescriptor{
+		adminv1.File_admin_v1_admin_proto,
+		templatev1.File_template_v1_template_proto,
+		templatev2.File_template_v2_template_proto,
+	} {
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+
+	return set
+}
+
+func TestDescriptorsUpToDate(t *testing.T) {
+	set, err := breaking.Unmarshal(Descriptors)
+	require.NoError(t, err)
+
+	// The descriptor set is regenerated with `make descriptors` when the proto files change
+	assert.True(t, proto.Equal(compiled(), set), "descriptors.pb differs from the generated code, run `make descriptors`")
+}
+
+func TestNoBreakingChanges(t *testing.T) {
+	b, err := os.ReadFile("baseline/descriptors.pb")
+	require.NoError(t, err)
+	baseline, err := breaking.Unmarshal(b)
+	require.NoError(t, err)
+
+	// The generated code is checked rather than descriptors.pb, which could be stale
+	for _, c := range breaking.Check(baseline, compiled()) {
+		t.Errorf("breaking change since the last release: %s", c)
+	}
+}
+
+func TestServices(t *testing.T) {
+	services, err := Services()
+	require.NoError(t, err)
+
+	var names []string
+	for _, s := range services {
+		names = append(names, string(s.FullName()))
+	}
+	assert.ElementsMatch(t, []string{"admin.v1.Admin", "template.v1.Echo", "template.v2.Echo"}, names)
+}
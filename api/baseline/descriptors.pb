@@ -0,0 +1,151 @@
+
+
+admin/v1/admin.protoadmin.v1google/protobuf/duration.protogoogle/protobuf/timestamp.proto"
+GetBuildInfoRequest"
+GetBuildInfoResponse
+version (	Rversion!
+git_revision (	RgitRevision
+
+git_branch (	R	gitBranch
+
+go_version (	R	goVersion"
+GetConfigRequest"
+GetConfigResponse?
+config (2'.admin.v1.GetConfigResponse.ConfigEntryRconfig9
+ConfigEntry
+key (	Rkey
+value (	Rvalue:8"*
+SetLogLevelRequest
+level (	Rlevel"<
+SetLogLevelResponse%
+previous_level (	RpreviousLevel"+
+SetReadinessRequest
+ready (Rready"
+SetReadinessResponse"
+DrainRequest"
+DrainResponse"
+InFlightRequest
+
+request_id (	R	requestId
+method (	Rmethod
+kind (	Rkind9
+
+start_time (2.google.protobuf.TimestampR	startTime3
+elapsed (2.google.protobuf.DurationRelapsed
+trace_id (	RtraceId
+caller (	Rcaller"
+ListInFlightRequest"M
+ListInFlightResponse5
+requests (2.admin.v1.InFlightRequestRrequests"5
+CancelRequestRequest
+
+request_id (	R	requestId"
+CancelRequestResponse"
+RunHealthChecksRequest"
+HealthCheck
+name (	Rname
+state (	Rstate
+output (	Routput5
+duration (2.google.protobuf.DurationRduration"^
+RunHealthChecksResponse
+state (	Rstate-
+checks (2.admin.v1.HealthCheckRchecks"1
+GetTenantUsageRequest
+tenants (	Rtenants"
+TenantUsage
+tenant (	Rtenant
+day (	Rday%
+daily_requests (RdailyRequests
+daily_quota (R
+dailyQuota
+month (	Rmonth)
+monthly_requests (RmonthlyRequests#
+monthly_quota (RmonthlyQuota"E
+GetTenantUsageResponse+
+usage (2.admin.v1.TenantUsageRusage"
+	ChaosRule
+name (	Rname
+enabled (Renabled
+methods (	Rmethods
+tenants (	Rtenants
+
+percentage (R
+percentage3
+latency (2.google.protobuf.DurationRlatency
+code (	Rcode*
+drop_stream_after (RdropStreamAfter
+panic	 (Rpanic"
+ListChaosRulesRequest"C
+ListChaosRulesResponse)
+rules (2.admin.v1.ChaosRuleRrules"J
+SetChaosRuleEnabledRequest
+name (	Rname
+enabled (Renabled"F
+SetChaosRuleEnabledResponse'
+rule (2.admin.v1.ChaosRuleRrule2
+AdminM
+GetBuildInfo.admin.v1.GetBuildInfoRequest.admin.v1.GetBuildInfoResponseD
+	GetConfig.admin.v1.GetConfigRequest.admin.v1.GetConfigResponseJ
+SetLogLevel.admin.v1.SetLogLevelRequest.admin.v1.SetLogLevelResponseM
+SetReadiness.admin.v1.SetReadinessRequest.admin.v1.SetReadinessResponse8
+Drain.admin.v1.DrainRequest.admin.v1.DrainResponseM
+ListInFlight.admin.v1.ListInFlightRequest.admin.v1.ListInFlightResponseP
+CancelRequest.admin.v1.CancelRequestRequest.admin.v1.CancelRequestResponseV
+RunHealthChecks .admin.v1.RunHealthChecksRequest!.admin.v1.RunHealthChecksResponseS
+GetTenantUsage.admin.v1.GetTenantUsageRequest .admin.v1.GetTenantUsageResponseS
+ListChaosRules.admin.v1.ListChaosRulesRequest .admin.v1.ListChaosRulesResponseb
+SetChaosRuleEnabled$.admin.v1.SetChaosRuleEnabledRequest%.admin.v1.SetChaosRuleEnabledResponseB>Z<github.com/sliide/template-grpc-service/api/admin/v1;adminv1bproto3
+
+template/v1/template.prototemplate.v1",
+UnaryEchoRequest
+message (	Rmessage"-
+UnaryEchoResponse
+message (	Rmessage"6
+ServerStreamingEchoRequest
+message (	Rmessage"7
+ServerStreamingEchoResponse
+message (	Rmessage"6
+ClientStreamingEchoRequest
+message (	Rmessage"7
+ClientStreamingEchoResponse
+message (	Rmessage"=
+!BidirectionalStreamingEchoRequest
+message (	Rmessage">
+"BidirectionalStreamingEchoResponse
+message (	Rmessage2
+EchoJ
+	UnaryEcho.template.v1.UnaryEchoRequest.template.v1.UnaryEchoResponsej
+ServerStreamingEcho'.template.v1.ServerStreamingEchoRequest(.template.v1.ServerStreamingEchoResponse0j
+ClientStreamingEcho'.template.v1.ClientStreamingEchoRequest(.template.v1.ClientStreamingEchoResponse(
+BidirectionalStreamingEcho..template.v1.BidirectionalStreamingEchoRequest/.template.v1.BidirectionalStreamingEchoResponse(0BDZBgithub.com/sliide/template-grpc-service/api/template/v1;templatev1bproto3
+
+template/v2/template.prototemplate.v2"K
+UnaryEchoRequest
+message (	Rmessage
+
+upper_case (R	upperCase"-
+UnaryEchoResponse
+message (	Rmessage"U
+ServerStreamingEchoRequest
+message (	Rmessage
+
+upper_case (R	upperCase"7
+ServerStreamingEchoResponse
+message (	Rmessage"U
+ClientStreamingEchoRequest
+message (	Rmessage
+
+upper_case (R	upperCase"7
+ClientStreamingEchoResponse
+message (	Rmessage"\
+!BidirectionalStreamingEchoRequest
+message (	Rmessage
+
+upper_case (R	upperCase">
+"BidirectionalStreamingEchoResponse
+message (	Rmessage2
+EchoJ
+	UnaryEcho.template.v2.UnaryEchoRequest.template.v2.UnaryEchoResponsej
+ServerStreamingEcho'.template.v2.ServerStreamingEchoRequest(.template.v2.ServerStreamingEchoResponse0j
+ClientStreamingEcho'.template.v2.ClientStreamingEchoRequest(.template.v2.ClientStreamingEchoResponse(
+BidirectionalStreamingEcho..template.v2.BidirectionalStreamingEchoRequest/.template.v2.BidirectionalStreamingEchoResponse(0BDZBgithub.com/sliide/template-grpc-service/api/template/v2;templatev2bproto3
\ No newline at end of file
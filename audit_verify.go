@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sliide/template-grpc-service/internal/audit"
+)
+
+const (
+	// auditVerifyCommand is the command verifying the chain of an audit file or the audit records in the DB.
+	auditVerifyCommand = "audit-verify"
+
+	// envAuditKey and envRdsURL are the env variables of the audit key and the DB, shared with the server config,
+	// so the secrets are not passed in the command line.
+	envAuditKey = "AUDIT_KEY"
+	envRdsURL   = "RDS_URL"
+)
+
+// runAuditVerify verifies the audit file or the DB given in the args, e.g. `audit-verify -file /var/log/audit.log`
+// or `audit-verify -db`, returns an error if any record is missing or tampered with.
+// The records are verified with the key of the AUDIT_KEY env variable, and the DB is the one of RDS_URL.
+func runAuditVerify(args []string) error {
+	fs := flag.NewFlagSet(auditVerifyCommand, flag.ContinueOnError)
+	file := fs.String("file", "", "path of the audit file to verify")
+	db := fs.Bool("db", false, "verify the audit records in the DB of the "+envRdsURL+" env variable")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (*file == "") == !*db {
+		return fmt.Errorf("%s: exactly one of the -file or -db flags is required", auditVerifyCommand)
+	}
+	key := []byte(os.Getenv(envAuditKey))
+
+	var (
+		n   int
+		err error
+	)
+	if *db {
+		n, err = verifyAuditDB(key)
+	} else {
+		n, err = audit.VerifyFile(*file, key)
+	}
+	if errors.Is(err, audit.ErrChainBroken) {
+		return fmt.Errorf("%s: %w, after %d valid records", auditVerifyCommand, err, n)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", auditVerifyCommand, err)
+	}
+	fmt.Printf("%d records verified\n", n)
+
+	return nil
+}
+
+func verifyAuditDB(key []byte) (int, error) {
+	url := os.Getenv(envRdsURL)
+	if url == "" {
+		return 0, fmt.Errorf("the %s env variable is required with -db", envRdsURL)
+	}
+
+	db, err := openDB(url)
+	if err != nil {
+		return 0, err
+	}
+
+	return audit.VerifyDB(context.Background(), db, key)
+}
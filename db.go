@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// openDB opens the postgres DB of the URL, e.g. the RDS_URL.
+func openDB(url string) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the DB: %w", err)
+	}
+
+	return db, nil
+}
@@ -2,17 +2,29 @@ module github.com/sliide/template-grpc-service
 
 go 1.16
 
+// google.golang.org/grpc/examples is not imported by this module anymore, it's still in go.sum and vendor/ because
+// github.com/sliide/service-healthcheck v1.0.3 imports its Echo proto in grpc.go, go mod tidy keeps it until the
+// healthcheck drops it.
+
 require (
 	github.com/caarlos0/env/v6 v6.6.2
+	github.com/golang/protobuf v1.4.2
+	github.com/google/uuid v1.1.2
 	github.com/gorilla/mux v1.8.0
 	github.com/grpc-ecosystem/go-grpc-middleware v1.0.1-0.20190717153623-606c73359dba
 	github.com/ory/dockertest/v3 v3.7.0 // indirect
+	github.com/oschwald/maxminddb-golang v1.3.1
+	github.com/prometheus/client_golang v1.3.0
+	github.com/prometheus/client_model v0.1.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/sliide/logstash v1.0.0
 	github.com/sliide/service-healthcheck v1.0.3
 	github.com/sliide/shared-go-libs v1.20.5
 	github.com/stretchr/testify v1.7.0
+	google.golang.org/genproto v0.0.0-20200624020401-64a14ca9d1ad
 	google.golang.org/grpc v1.35.0
-	google.golang.org/grpc/examples v0.0.0-20200805004648-5f7b337d951f
+	google.golang.org/protobuf v1.25.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
+	gorm.io/driver/postgres v1.1.0
 	gorm.io/gorm v1.21.10
 )
@@ -0,0 +1,148 @@
+// Package accesslog writes one line per RPC and per monitoring HTTP request into a dedicated stream,
+// separated from the application logs and independent of the log level.
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Formats of the access log lines.
+const (
+	// FormatJSON writes the entries as JSON objects.
+	FormatJSON = "json"
+	// FormatText writes the entries in the combined log format, with the duration in seconds appended.
+	FormatText = "text"
+)
+
+// Protocols of the entries.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// combinedTimeFormat is the time format of the combined log format.
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// Entry is an access log entry.
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Protocol string    `json:"protocol"`
+	// Method is the HTTP method, or "POST" for the RPCs.
+	Method string `json:"method"`
+	// Path is the HTTP path, or the full method name of the RPCs.
+	Path string `json:"path"`
+	// Status is the HTTP status code, or the gRPC status code name.
+	Status     string  `json:"status"`
+	Failed     bool    `json:"failed"`
+	Bytes      int64   `json:"bytes"`
+	Duration   float64 `json:"duration"`
+	RemoteAddr string  `json:"remote_addr"`
+	UserAgent  string  `json:"user_agent"`
+	RequestID  string  `json:"request_id,omitempty"`
+	TraceID    string  `json:"trace_id,omitempty"`
+}
+
+// Params represents the parameters of an access logger.
+type Params struct {
+	// Output is the writer of the lines, see rotate.Open.
+	Output io.Writer
+	// Format is either FormatJSON (default) or FormatText.
+	Format string
+	// SampleRate is the rate (0 to 1) of the successful requests written, the failed ones are always written.
+	SampleRate float64
+}
+
+// Logger writes the access log entries.
+type Logger struct {
+	output     io.Writer
+	format     func(e Entry) ([]byte, error)
+	sampleRate float64
+
+	m      sync.Mutex
+	random func() float64
+}
+
+// NewLogger returns a new access logger of the given params.
+func NewLogger(p Params) (*Logger, error) {
+	var format func(e Entry) ([]byte, error)
+	switch p.Format {
+	case "", FormatJSON:
+		format = formatJSON
+	case FormatText:
+		format = formatText
+	default:
+		return nil, fmt.Errorf("unsupported access log format: %s", p.Format)
+	}
+
+	return &Logger{
+		output:     p.Output,
+		format:     format,
+		sampleRate: p.SampleRate,
+		random:     rand.New(rand.NewSource(rand.Int63())).Float64, // nolint: gosec
+	}, nil
+}
+
+// Log writes the entry if it's sampled.
+func (l *Logger) Log(e Entry) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if !e.Failed && (l.sampleRate <= 0 || (l.sampleRate < 1 && l.random() >= l.sampleRate)) {
+		return nil
+	}
+
+	b, err := l.format(e)
+	if err != nil {
+		return err
+	}
+	_, err = l.output.Write(b)
+
+	return err
+}
+
+func formatJSON(e Entry) ([]byte, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// formatText formats the entry in the combined log format, e.g.
+//
+//	192.0.2.1 - - [10/Oct/2020:13:55:36 +0000] "POST /template.v2.Echo/UnaryEcho grpc" OK 0 "-" "grpc-go/1.35.0" 0.000312
+func formatText(e Entry) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(orDash(e.RemoteAddr))
+	sb.WriteString(" - - [")
+	sb.WriteString(e.Time.Format(combinedTimeFormat))
+	sb.WriteString("] ")
+	sb.WriteString(strconv.Quote(e.Method + " " + e.Path + " " + e.Protocol))
+	sb.WriteString(" ")
+	sb.WriteString(e.Status)
+	sb.WriteString(" ")
+	sb.WriteString(strconv.FormatInt(e.Bytes, 10))
+	sb.WriteString(` "-" `)
+	sb.WriteString(strconv.Quote(orDash(e.UserAgent)))
+	sb.WriteString(" ")
+	sb.WriteString(strconv.FormatFloat(e.Duration, 'f', 6, 64))
+	sb.WriteString("\n")
+
+	return []byte(sb.String()), nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,76 @@
+package accesslog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntry = Entry{
+	Time:       time.Date(2020, 10, 10, 13, 55, 36, 0, time.UTC),
+	Protocol:   ProtocolGRPC,
+	Method:     "POST",
+	Path:       "/template.v1.Echo/UnaryEcho",
+	Status:     "OK",
+	Duration:   0.000312,
+	RemoteAddr: "192.0.2.1",
+	UserAgent:  "grpc-go/1.35.0",
+	RequestID:  "request-id",
+	TraceID:    "trace-id",
+}
+
+func TestLoggerFormats(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: FormatJSON,
+			expected: `{"time":"2020-10-10T13:55:36Z","protocol":"grpc","method":"POST",` +
+				`"path":"/template.v1.Echo/UnaryEcho","status":"OK","failed":false,"bytes":0,"duration":0.000312,` +
+				`"remote_addr":"192.0.2.1","user_agent":"grpc-go/1.35.0","request_id":"request-id","trace_id":"trace-id"}` + "\n",
+		},
+		{
+			format: FormatText,
+			expected: `192.0.2.1 - - [10/Oct/2020:13:55:36 +0000] "POST /template.v1.Echo/UnaryEcho grpc" OK 0 "-" ` +
+				`"grpc-go/1.35.0" 0.000312` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l, err := NewLogger(Params{Output: buf, Format: tt.format, SampleRate: 1})
+			require.NoError(t, err)
+
+			require.NoError(t, l.Log(testEntry))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	_, err := NewLogger(Params{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestLoggerSampling(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := NewLogger(Params{Output: buf, Format: FormatText, SampleRate: 0.5})
+	require.NoError(t, err)
+
+	l.random = func() float64 { return 0.6 }
+	require.NoError(t, l.Log(testEntry))
+	assert.Empty(t, buf.String(), "Must skip the entry out of the sample")
+
+	failed := testEntry
+	failed.Status, failed.Failed = "Internal", true
+	require.NoError(t, l.Log(failed))
+	assert.Contains(t, buf.String(), " Internal ", "Must always write the failed entries")
+
+	buf.Reset()
+	l.random = func() float64 { return 0.4 }
+	require.NoError(t, l.Log(testEntry))
+	assert.NotEmpty(t, buf.String())
+}
@@ -0,0 +1,126 @@
+package accesslog
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+)
+
+// UnaryServerInterceptor returns a unary interceptor that writes an entry per call,
+// the interceptor does nothing if the logger is nil.
+//
+// NOTE: Should be chained before the grpcerr interceptor, so the entries have the codes returned to the callers.
+func (l *Logger) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if l == nil {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		l.logRPC(ctx, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that writes an entry per call,
+// the interceptor does nothing if the logger is nil.
+func (l *Logger) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if l == nil {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		err := handler(srv, ss)
+		l.logRPC(ss.Context(), info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func (l *Logger) logRPC(ctx context.Context, fullMethod string, start time.Time, err error) {
+	code := status.Code(err)
+	reqCtx := coremiddleware.RequestContext(ctx)
+
+	lErr := l.Log(Entry{
+		Time:       start,
+		Protocol:   ProtocolGRPC,
+		Method:     http.MethodPost,
+		Path:       fullMethod,
+		Status:     code.String(),
+		Failed:     code != codes.OK,
+		Duration:   time.Since(start).Seconds(),
+		RemoteAddr: reqCtx.RemoteAddr(),
+		UserAgent:  reqCtx.UserAgent(),
+		RequestID:  reqCtx.RequestID(),
+		TraceID:    reqCtx.TraceID(),
+	})
+	if lErr != nil {
+		coremiddleware.Logger(ctx).WithError(lErr).Warn("Failed to write the access log")
+	}
+}
+
+// Middleware returns an HTTP middleware that writes an entry per request,
+// the middleware does nothing if the logger is nil.
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		remoteAddr := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+			remoteAddr = host
+		}
+
+		lErr := l.Log(Entry{
+			Time:       start,
+			Protocol:   ProtocolHTTP,
+			Method:     r.Method,
+			Path:       r.URL.RequestURI(),
+			Status:     strconv.Itoa(rw.status),
+			Failed:     rw.status >= http.StatusInternalServerError,
+			Bytes:      rw.bytes,
+			Duration:   time.Since(start).Seconds(),
+			RemoteAddr: remoteAddr,
+			UserAgent:  r.UserAgent(),
+		})
+		if lErr != nil {
+			coremiddleware.Logger(r.Context()).WithError(lErr).Warn("Failed to write the access log")
+		}
+	})
+}
+
+// responseWriter records the status and the size of the response.
+type responseWriter struct {
+	http.ResponseWriter
+
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,75 @@
+package accesslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoggerUnaryServerInterceptor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := NewLogger(Params{Output: buf, SampleRate: 1})
+	require.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/template.v1.Echo/UnaryEcho"}
+	_, err = l.UnaryServerInterceptor()(context.Background(), "req", info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "not found")
+		})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	var e Entry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &e))
+	assert.Equal(t, ProtocolGRPC, e.Protocol)
+	assert.Equal(t, "/template.v1.Echo/UnaryEcho", e.Path)
+	assert.Equal(t, "NotFound", e.Status)
+	assert.True(t, e.Failed)
+	assert.NotEmpty(t, e.RequestID)
+
+	t.Run("Nil logger", func(t *testing.T) {
+		var l *Logger
+		resp, err := l.UnaryServerInterceptor()(context.Background(), "req", info,
+			func(ctx context.Context, req interface{}) (interface{}, error) { return "resp", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+	})
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l, err := NewLogger(Params{Output: buf, SampleRate: 1})
+	require.NoError(t, err)
+
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("not ready"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/ready?verbose=1", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "kube-probe/1.18")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var e Entry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &e))
+	assert.Equal(t, ProtocolHTTP, e.Protocol)
+	assert.Equal(t, http.MethodGet, e.Method)
+	assert.Equal(t, "/ready?verbose=1", e.Path)
+	assert.Equal(t, "503", e.Status)
+	assert.True(t, e.Failed)
+	assert.Equal(t, int64(9), e.Bytes)
+	assert.Equal(t, "192.0.2.1", e.RemoteAddr)
+	assert.Equal(t, "kube-probe/1.18", e.UserAgent)
+
+	var nilLogger *Logger
+	assert.NotNil(t, nilLogger.Middleware(h))
+}
@@ -0,0 +1,95 @@
+// Package admin implements the endpoints for operating a running instance, protected by an admin token.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+)
+
+const (
+	// MetaKeyAuthorization represents the meta key to get the admin token from client's request,
+	// in the `Bearer <token>` format.
+	MetaKeyAuthorization = "authorization"
+
+	bearerPrefix = "Bearer "
+)
+
+// validToken compares the token of the `Bearer <token>` header value in constant time.
+func validToken(expected, header string) bool {
+	if expected == "" || !strings.HasPrefix(header, bearerPrefix) {
+		return false
+	}
+	token := strings.TrimPrefix(header, bearerPrefix)
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
+
+// RequireToken returns an HTTP middleware that rejects the requests without the admin token.
+func RequireToken(token string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !validToken(token, r.Header.Get("Authorization")) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UnaryServerInterceptor returns a unary interceptor that rejects the calls of the methods with the given prefix
+// (e.g. "/admin.v1.Admin/") without the admin token.
+func UnaryServerInterceptor(token, methodPrefix string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, methodPrefix) {
+			return handler(ctx, req)
+		}
+
+		if err := authorize(ctx, token); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that rejects the streams of the methods with the given prefix
+// without the admin token.
+func StreamServerInterceptor(token, methodPrefix string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasPrefix(info.FullMethod, methodPrefix) {
+			return handler(srv, ss)
+		}
+
+		if err := authorize(ss.Context(), token); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// authorize returns an Unauthenticated error if the metadata of the context doesn't contain the admin token.
+func authorize(ctx context.Context, token string) error {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(MetaKeyAuthorization); len(v) > 0 {
+			header = v[0]
+		}
+	}
+	if !validToken(token, header) {
+		return grpcerr.New(grpcerr.ErrUnauthenticated, "ADMIN_TOKEN_INVALID", "Invalid admin token")
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/sliide/template-grpc-service/internal/inflight"
+)
+
+// RegisterHTTPHandlers registers the admin HTTP endpoints into the router, protected by the admin token:
+//
+//	GET  /admin/inflight                      lists the calls currently running
+//	POST /admin/inflight/{request_id}/cancel  cancels a call currently running
+func RegisterHTTPHandlers(r *mux.Router, token string, registry *inflight.Registry) {
+	sr := r.PathPrefix("/admin").Subrouter()
+	sr.Use(RequireToken(token))
+
+	sr.HandleFunc("/inflight", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(registry.List())
+	}).Methods(http.MethodGet)
+
+	sr.HandleFunc("/inflight/{request_id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if !registry.Cancel(mux.Vars(r)["request_id"]) {
+			http.Error(w, "request is not in flight", http.StatusNotFound)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodPost)
+}
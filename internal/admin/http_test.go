@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/sliide/template-grpc-service/internal/inflight"
+)
+
+// startCall starts a call in the registry, returns its request ID and a channel closed when the call is cancelled.
+func startCall(t *testing.T, registry *inflight.Registry) (string, <-chan struct{}) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	go func() {
+		_, _ = registry.UnaryServerInterceptor()(context.Background(), "req",
+			&grpc.UnaryServerInfo{FullMethod: "/test.Service/Slow"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				close(started)
+				<-ctx.Done()
+				close(cancelled)
+
+				return nil, ctx.Err()
+			})
+	}()
+	<-started
+
+	list := registry.List()
+	require.Len(t, list, 1)
+
+	return list[0].RequestID, cancelled
+}
+
+func TestRegisterHTTPHandlers(t *testing.T) {
+	registry := inflight.NewRegistry()
+	r := mux.NewRouter()
+	RegisterHTTPHandlers(r, "secret", registry)
+
+	requestID, cancelled := startCall(t, registry)
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/admin/inflight", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/admin/inflight", "wrong").Code)
+
+	rec := serve(http.MethodGet, "/admin/inflight", "secret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list []inflight.Request
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "/test.Service/Slow", list[0].Method)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/admin/inflight/unknown/cancel", "secret").Code)
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodPost, "/admin/inflight/"+requestID+"/cancel", "secret").Code)
+	<-cancelled
+}
+
+func TestRequireTokenEmpty(t *testing.T) {
+	h := RequireToken("")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "Must reject all the requests without a configured token")
+}
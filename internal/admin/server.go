@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	adminv1 "github.com/sliide/template-grpc-service/api/admin/v1"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+)
+
+// errorDomain is the domain of the errors returned by the Admin server.
+const errorDomain = "admin"
+
+// ServerParams represents the parameters of the Admin server.
+type ServerParams struct {
+	// ListenAddr is the address of the internal-only listener, it must not be exposed publicly.
+	ListenAddr string
+	// Token is the admin token required by every call of the server.
+	Token  string
+	Logger *logrus.Entry
+}
+
+// Server serves the Admin service on its own listener, with its own interceptor chain,
+// separated from the public server.
+type Server struct {
+	s          *grpc.Server
+	listenAddr string
+
+	m       sync.Mutex
+	serving int32
+}
+
+// NewServer returns a new Admin server of the given service.
+func NewServer(p ServerParams, service *Service) *Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(newUnaryInterceptor(p)),
+		grpc.StreamInterceptor(StreamServerInterceptor(p.Token, "/")),
+	)
+
+	adminv1.RegisterAdminServer(server, service)
+	reflection.Register(server)
+
+	return &Server{
+		s:          server,
+		listenAddr: p.ListenAddr,
+	}
+}
+
+// newUnaryInterceptor returns the interceptor of the Admin server, every call requires the admin token.
+func newUnaryInterceptor(p ServerParams) grpc.UnaryServerInterceptor {
+	return grpcmiddleware.ChainUnaryServer(
+		coremiddleware.Recovery(),
+		coremiddleware.Logging(p.Logger),
+		coremiddleware.Entry(coremiddleware.EntryConfigs{
+			AllowTraceIDFromRequest: true,
+			ReturnRequestIDInHeader: false,
+		}),
+		coremiddleware.EntryLogs(),
+		coremiddleware.Prometheus(),
+		grpcerr.UnaryServerInterceptor(errorDomain),
+		UnaryServerInterceptor(p.Token, "/"),
+		coremiddleware.Recovery(),
+	)
+}
+
+// ListenAndServe starts the server and listens to the tcp port defined in the params.
+func (s *Server) ListenAndServe() error {
+	lis, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = lis.Close()
+	}()
+
+	return s.serve(lis)
+}
+
+func (s *Server) serve(lis net.Listener) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	atomic.StoreInt32(&s.serving, 1)
+	defer func() {
+		atomic.StoreInt32(&s.serving, 0)
+	}()
+
+	return s.s.Serve(lis)
+}
+
+// Serving returns true if the server is serving.
+func (s *Server) Serving() bool {
+	return atomic.LoadInt32(&s.serving) == 1
+}
+
+// GracefulStop gracefully stops the running server.
+func (s *Server) GracefulStop() {
+	s.s.GracefulStop()
+}
@@ -0,0 +1,86 @@
+package admin
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	adminv1 "github.com/sliide/template-grpc-service/api/admin/v1"
+)
+
+func TestServer(t *testing.T) {
+	l := logrus.New()
+	l.SetOutput(ioutil.Discard)
+
+	s := NewServer(ServerParams{
+		Token:  "secret",
+		Logger: logrus.NewEntry(l),
+	}, NewService(Params{BuildInfo: BuildInfo{Version: "v1.0.0"}}))
+	assert.False(t, s.Serving())
+
+	listener := bufconn.Listen(1024 * 1024)
+	defer listener.Close()
+
+	ch := make(chan interface{})
+	go func() {
+		assert.NoError(t, s.serve(listener))
+		ch <- true
+	}()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	client := adminv1.NewAdminClient(conn)
+
+	_, err = client.GetBuildInfo(ctx, &adminv1.GetBuildInfoRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.GetBuildInfo(metadata.AppendToOutgoingContext(ctx, MetaKeyAuthorization, "Bearer wrong"), &adminv1.GetBuildInfoRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authCtx := metadata.AppendToOutgoingContext(ctx, MetaKeyAuthorization, "Bearer secret")
+	info, err := client.GetBuildInfo(authCtx, &adminv1.GetBuildInfoRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", info.GetVersion())
+	assert.True(t, s.Serving())
+
+	t.Run("Streams require the token", func(t *testing.T) {
+		reflectionClient := grpc_reflection_v1alpha.NewServerReflectionClient(conn)
+
+		stream, err := reflectionClient.ServerReflectionInfo(ctx)
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		stream, err = reflectionClient.ServerReflectionInfo(authCtx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_ListServices{},
+		}))
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.GetListServicesResponse().GetService())
+		require.NoError(t, stream.CloseSend())
+	})
+
+	s.GracefulStop()
+	<-ch
+	assert.False(t, s.Serving())
+}
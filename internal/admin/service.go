@@ -0,0 +1,291 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/sliide/logstash"
+	healthcheck "github.com/sliide/service-healthcheck"
+	adminv1 "github.com/sliide/template-grpc-service/api/admin/v1"
+	"github.com/sliide/template-grpc-service/internal/chaos"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/inflight"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+)
+
+// MethodPrefix is the prefix of the full method names of the Admin service.
+const MethodPrefix = "/admin.v1.Admin/"
+
+// BuildInfo represents the build info of the running instance.
+type BuildInfo struct {
+	Version     string
+	GitRevision string
+	GitBranch   string
+	GoVersion   string
+}
+
+// Params represents the parameters of the Admin service,
+// the operations depending on a nil parameter return the Unimplemented code.
+type Params struct {
+	BuildInfo BuildInfo
+	// Config is the effective config by the env variable names, with the secrets redacted.
+	Config map[string]string
+	// Logger is the logger whose level is set by the SetLogLevel operation.
+	Logger *logrus.Logger
+	// Readiness is the readiness reported to k8s, see healthcheck.Readiness.
+	Readiness *atomic.Value
+	// Drain is called once the instance is marked not ready by the Drain operation,
+	// and must gracefully stop the server without blocking.
+	Drain func()
+	// HealthChecker runs the health checks on demand.
+	HealthChecker healthcheck.HealthChecker
+	// Registry keeps the calls of the server currently running.
+	Registry *inflight.Registry
+	// Tenancy reports the usage and the quotas of the tenants.
+	Tenancy *tenant.Tenancy
+	// Chaos injects the faults of the rules toggled by the chaos operations.
+	Chaos *chaos.Injector
+}
+
+// Service implements the Admin gRPC service.
+type Service struct {
+	adminv1.UnimplementedAdminServer
+
+	p Params
+}
+
+// NewService returns a new Admin service.
+func NewService(p Params) *Service {
+	return &Service{
+		p: p,
+	}
+}
+
+// GetBuildInfo implements the Admin service.
+func (s *Service) GetBuildInfo(context.Context, *adminv1.GetBuildInfoRequest) (*adminv1.GetBuildInfoResponse, error) {
+	return &adminv1.GetBuildInfoResponse{
+		Version:     s.p.BuildInfo.Version,
+		GitRevision: s.p.BuildInfo.GitRevision,
+		GitBranch:   s.p.BuildInfo.GitBranch,
+		GoVersion:   s.p.BuildInfo.GoVersion,
+	}, nil
+}
+
+// GetConfig implements the Admin service.
+func (s *Service) GetConfig(context.Context, *adminv1.GetConfigRequest) (*adminv1.GetConfigResponse, error) {
+	return &adminv1.GetConfigResponse{
+		Config: s.p.Config,
+	}, nil
+}
+
+// SetLogLevel implements the Admin service.
+func (s *Service) SetLogLevel(ctx context.Context, req *adminv1.SetLogLevelRequest) (*adminv1.SetLogLevelResponse, error) {
+	if s.p.Logger == nil {
+		return s.UnimplementedAdminServer.SetLogLevel(ctx, req)
+	}
+
+	level, ok := logstash.LogLevels[req.GetLevel()]
+	if !ok {
+		return nil, grpcerr.NewBadRequest("Unsupported log level", grpcerr.FieldViolation{
+			Field:       "level",
+			Description: "must be one of DEBUG, INFO, WARNING, ERROR, FATAL or PANIC",
+		})
+	}
+
+	previous := s.p.Logger.GetLevel()
+	s.p.Logger.SetLevel(level)
+	logrus.WithFields(logrus.Fields{
+		"previous_level": previous.String(),
+		"level":          level.String(),
+	}).Warn("Log level changed by admin")
+
+	return &adminv1.SetLogLevelResponse{
+		PreviousLevel: levelName(previous),
+	}, nil
+}
+
+// SetReadiness implements the Admin service.
+func (s *Service) SetReadiness(ctx context.Context, req *adminv1.SetReadinessRequest) (*adminv1.SetReadinessResponse, error) {
+	if s.p.Readiness == nil {
+		return s.UnimplementedAdminServer.SetReadiness(ctx, req)
+	}
+
+	s.p.Readiness.Store(req.GetReady())
+	logrus.WithField("ready", req.GetReady()).Warn("Readiness changed by admin")
+
+	return &adminv1.SetReadinessResponse{}, nil
+}
+
+// Drain implements the Admin service.
+func (s *Service) Drain(ctx context.Context, req *adminv1.DrainRequest) (*adminv1.DrainResponse, error) {
+	if s.p.Drain == nil {
+		return s.UnimplementedAdminServer.Drain(ctx, req)
+	}
+
+	if s.p.Readiness != nil {
+		s.p.Readiness.Store(false)
+	}
+	logrus.Warn("Draining triggered by admin")
+	s.p.Drain()
+
+	return &adminv1.DrainResponse{}, nil
+}
+
+// ListInFlight implements the Admin service.
+func (s *Service) ListInFlight(ctx context.Context, req *adminv1.ListInFlightRequest) (*adminv1.ListInFlightResponse, error) {
+	if s.p.Registry == nil {
+		return s.UnimplementedAdminServer.ListInFlight(ctx, req)
+	}
+
+	resp := &adminv1.ListInFlightResponse{}
+	for _, r := range s.p.Registry.List() {
+		resp.Requests = append(resp.Requests, &adminv1.InFlightRequest{
+			RequestId: r.RequestID,
+			Method:    r.Method,
+			Kind:      r.Kind,
+			StartTime: timestamppb.New(r.StartTime),
+			Elapsed:   durationpb.New(r.Elapsed),
+			TraceId:   r.TraceID,
+			Caller:    r.Caller,
+		})
+	}
+
+	return resp, nil
+}
+
+// CancelRequest implements the Admin service.
+func (s *Service) CancelRequest(ctx context.Context, req *adminv1.CancelRequestRequest) (*adminv1.CancelRequestResponse, error) {
+	if s.p.Registry == nil {
+		return s.UnimplementedAdminServer.CancelRequest(ctx, req)
+	}
+	if req.GetRequestId() == "" {
+		return nil, grpcerr.NewBadRequest("Request ID is required", grpcerr.FieldViolation{
+			Field:       "request_id",
+			Description: "is required",
+		})
+	}
+
+	if !s.p.Registry.Cancel(req.GetRequestId()) {
+		return nil, grpcerr.New(grpcerr.ErrNotFound, "REQUEST_NOT_FOUND", "Request is not in flight")
+	}
+
+	return &adminv1.CancelRequestResponse{}, nil
+}
+
+// RunHealthChecks implements the Admin service.
+func (s *Service) RunHealthChecks(ctx context.Context, req *adminv1.RunHealthChecksRequest) (*adminv1.RunHealthChecksResponse, error) {
+	if s.p.HealthChecker == nil {
+		return s.UnimplementedAdminServer.RunHealthChecks(ctx, req)
+	}
+
+	results := s.p.HealthChecker.RunChecks(ctx)
+	resp := &adminv1.RunHealthChecksResponse{
+		State: results.GetState().String(),
+	}
+	for _, c := range results.Checks {
+		resp.Checks = append(resp.Checks, &adminv1.HealthCheck{
+			Name:     c.Name,
+			State:    c.State.String(),
+			Output:   c.Output,
+			Duration: durationpb.New(c.Duration),
+		})
+	}
+
+	return resp, nil
+}
+
+// GetTenantUsage implements the Admin service.
+func (s *Service) GetTenantUsage(ctx context.Context, req *adminv1.GetTenantUsageRequest) (*adminv1.GetTenantUsageResponse, error) {
+	if s.p.Tenancy == nil {
+		return s.UnimplementedAdminServer.GetTenantUsage(ctx, req)
+	}
+
+	usages, err := s.p.Tenancy.Usage(ctx, req.GetTenants()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the tenant usage: %w", err)
+	}
+
+	resp := &adminv1.GetTenantUsageResponse{}
+	for _, u := range usages {
+		resp.Usage = append(resp.Usage, &adminv1.TenantUsage{
+			Tenant:          u.Tenant,
+			Day:             u.Day,
+			DailyRequests:   u.Daily,
+			DailyQuota:      u.Quota.Daily,
+			Month:           u.Month,
+			MonthlyRequests: u.Monthly,
+			MonthlyQuota:    u.Quota.Monthly,
+		})
+	}
+
+	return resp, nil
+}
+
+// ListChaosRules implements the Admin service.
+func (s *Service) ListChaosRules(ctx context.Context, req *adminv1.ListChaosRulesRequest) (*adminv1.ListChaosRulesResponse, error) {
+	if s.p.Chaos == nil {
+		return s.UnimplementedAdminServer.ListChaosRules(ctx, req)
+	}
+
+	resp := &adminv1.ListChaosRulesResponse{}
+	for _, r := range s.p.Chaos.Rules() {
+		resp.Rules = append(resp.Rules, chaosRule(r))
+	}
+
+	return resp, nil
+}
+
+// SetChaosRuleEnabled implements the Admin service.
+func (s *Service) SetChaosRuleEnabled(ctx context.Context, req *adminv1.SetChaosRuleEnabledRequest) (*adminv1.SetChaosRuleEnabledResponse, error) {
+	if s.p.Chaos == nil {
+		return s.UnimplementedAdminServer.SetChaosRuleEnabled(ctx, req)
+	}
+
+	r, err := s.p.Chaos.SetEnabled(req.GetName(), req.GetEnabled())
+	if errors.Is(err, chaos.ErrRuleNotFound) {
+		return nil, grpcerr.New(grpcerr.ErrNotFound, "CHAOS_RULE_NOT_FOUND", "Chaos rule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{
+		"chaos_rule": r.Name,
+		"enabled":    r.Enabled,
+	}).Warn("Chaos rule changed by admin")
+
+	return &adminv1.SetChaosRuleEnabledResponse{
+		Rule: chaosRule(r),
+	}, nil
+}
+
+// chaosRule returns the message of a chaos rule.
+func chaosRule(r chaos.Rule) *adminv1.ChaosRule {
+	return &adminv1.ChaosRule{
+		Name:            r.Name,
+		Enabled:         r.Enabled,
+		Methods:         r.Methods,
+		Tenants:         r.Tenants,
+		Percentage:      r.Percentage,
+		Latency:         durationpb.New(r.Latency),
+		Code:            r.Code,
+		DropStreamAfter: int32(r.DropStreamAfter),
+		Panic:           r.Panic,
+	}
+}
+
+// levelName returns the name of the level used in the LOG_LEVEL env variable.
+func levelName(level logrus.Level) string {
+	for name, l := range logstash.LogLevels {
+		if l == level {
+			return name
+		}
+	}
+
+	return level.String()
+}
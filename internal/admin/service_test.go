@@ -0,0 +1,203 @@
+package admin
+
+import (
+	"context"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	healthcheck "github.com/sliide/service-healthcheck"
+	adminv1 "github.com/sliide/template-grpc-service/api/admin/v1"
+	"github.com/sliide/template-grpc-service/internal/chaos"
+	"github.com/sliide/template-grpc-service/internal/inflight"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+)
+
+func TestServiceInFlight(t *testing.T) {
+	registry := inflight.NewRegistry()
+	s := NewService(Params{Registry: registry})
+	ctx := context.Background()
+
+	requestID, cancelled := startCall(t, registry)
+
+	resp, err := s.ListInFlight(ctx, &adminv1.ListInFlightRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetRequests(), 1)
+	assert.Equal(t, requestID, resp.GetRequests()[0].GetRequestId())
+	assert.Equal(t, inflight.KindUnary, resp.GetRequests()[0].GetKind())
+
+	_, err = s.CancelRequest(ctx, &adminv1.CancelRequestRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.CancelRequest(ctx, &adminv1.CancelRequestRequest{RequestId: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = s.CancelRequest(ctx, &adminv1.CancelRequestRequest{RequestId: requestID})
+	require.NoError(t, err)
+	<-cancelled
+}
+
+func TestServiceOperations(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.SetLevel(logrus.InfoLevel)
+	isReady := &atomic.Value{}
+	isReady.Store(true)
+	drained := 0
+	hc := healthcheck.New(healthcheck.Params{})
+	hc.AddCheck("test", func(context.Context) (*healthcheck.CheckingState, error) {
+		return &healthcheck.CheckingState{State: healthcheck.StateDegraded, Output: "slow"}, nil
+	})
+
+	s := NewService(Params{
+		BuildInfo:     BuildInfo{Version: "v1.0.0", GitRevision: "abc", GitBranch: "main", GoVersion: "go1.16"},
+		Config:        map[string]string{"ADMIN_TOKEN": "[REDACTED]"},
+		Logger:        logger,
+		Readiness:     isReady,
+		Drain:         func() { drained++ },
+		HealthChecker: hc,
+	})
+
+	t.Run("Build info and config", func(t *testing.T) {
+		info, err := s.GetBuildInfo(ctx, &adminv1.GetBuildInfoRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "v1.0.0", info.GetVersion())
+		assert.Equal(t, "abc", info.GetGitRevision())
+
+		cfg, err := s.GetConfig(ctx, &adminv1.GetConfigRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"ADMIN_TOKEN": "[REDACTED]"}, cfg.GetConfig())
+	})
+
+	t.Run("Log level", func(t *testing.T) {
+		_, err := s.SetLogLevel(ctx, &adminv1.SetLogLevelRequest{Level: "VERBOSE"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, logrus.InfoLevel, logger.GetLevel())
+
+		resp, err := s.SetLogLevel(ctx, &adminv1.SetLogLevelRequest{Level: "DEBUG"})
+		require.NoError(t, err)
+		assert.Equal(t, "INFO", resp.GetPreviousLevel())
+		assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+	})
+
+	t.Run("Readiness and drain", func(t *testing.T) {
+		_, err := s.SetReadiness(ctx, &adminv1.SetReadinessRequest{Ready: false})
+		require.NoError(t, err)
+		assert.Equal(t, false, isReady.Load())
+
+		_, err = s.SetReadiness(ctx, &adminv1.SetReadinessRequest{Ready: true})
+		require.NoError(t, err)
+		assert.Equal(t, true, isReady.Load())
+
+		_, err = s.Drain(ctx, &adminv1.DrainRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, false, isReady.Load(), "Must be marked not ready before draining")
+		assert.Equal(t, 1, drained)
+	})
+
+	t.Run("Health checks", func(t *testing.T) {
+		resp, err := s.RunHealthChecks(ctx, &adminv1.RunHealthChecksRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "degraded", resp.GetState())
+		require.Len(t, resp.GetChecks(), 1)
+		assert.Equal(t, "test", resp.GetChecks()[0].GetName())
+		assert.Equal(t, "slow", resp.GetChecks()[0].GetOutput())
+	})
+
+	t.Run("Unimplemented without params", func(t *testing.T) {
+		s := NewService(Params{})
+
+		_, err := s.SetLogLevel(ctx, &adminv1.SetLogLevelRequest{Level: "DEBUG"})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		_, err = s.Drain(ctx, &adminv1.DrainRequest{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		_, err = s.ListInFlight(ctx, &adminv1.ListInFlightRequest{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		_, err = s.GetTenantUsage(ctx, &adminv1.GetTenantUsageRequest{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		_, err = s.ListChaosRules(ctx, &adminv1.ListChaosRulesRequest{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
+func TestServiceTenantUsage(t *testing.T) {
+	store := tenant.NewMemoryStore()
+	s := NewService(Params{Tenancy: tenant.New(tenant.Params{
+		Quotas: tenant.Quotas{Tenants: map[string]tenant.Quota{"team-a": {Daily: 10}}},
+		Store:  store,
+	})})
+	ctx := context.Background()
+
+	_, err := store.Consume(ctx, "team-a", time.Now(), tenant.Quota{})
+	require.NoError(t, err)
+	_, err = store.Consume(ctx, "team-b", time.Now(), tenant.Quota{})
+	require.NoError(t, err)
+
+	resp, err := s.GetTenantUsage(ctx, &adminv1.GetTenantUsageRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsage(), 2)
+	assert.Equal(t, "team-a", resp.GetUsage()[0].GetTenant())
+	assert.Equal(t, int64(1), resp.GetUsage()[0].GetDailyRequests())
+	assert.Equal(t, int64(10), resp.GetUsage()[0].GetDailyQuota())
+	assert.Equal(t, int64(1), resp.GetUsage()[0].GetMonthlyRequests())
+	assert.Equal(t, time.Now().UTC().Format("2006-01"), resp.GetUsage()[0].GetMonth())
+
+	resp, err = s.GetTenantUsage(ctx, &adminv1.GetTenantUsageRequest{Tenants: []string{"team-b"}})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsage(), 1)
+	assert.Equal(t, "team-b", resp.GetUsage()[0].GetTenant())
+	assert.Equal(t, int64(0), resp.GetUsage()[0].GetDailyQuota())
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor("secret", MethodPrefix)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "resp", nil }
+	adminInfo := &grpc.UnaryServerInfo{FullMethod: MethodPrefix + "ListInFlight"}
+
+	_, err := interceptor(context.Background(), "req", adminInfo, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetaKeyAuthorization, "Bearer secret"))
+	resp, err := interceptor(ctx, "req", adminInfo, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+
+	resp, err = interceptor(context.Background(), "req", &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}, handler)
+	require.NoError(t, err, "Must not require the token for the other services")
+	assert.Equal(t, "resp", resp)
+}
+
+func TestServiceChaos(t *testing.T) {
+	injector, err := chaos.New(chaos.Params{Config: chaos.Config{Rules: []chaos.Rule{
+		{Name: "slow", Methods: []string{"/test.Service/Get"}, Latency: time.Second},
+	}}})
+	require.NoError(t, err)
+	s := NewService(Params{Chaos: injector})
+	ctx := context.Background()
+
+	resp, err := s.ListChaosRules(ctx, &adminv1.ListChaosRulesRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetRules(), 1)
+	assert.Equal(t, "slow", resp.GetRules()[0].GetName())
+	assert.False(t, resp.GetRules()[0].GetEnabled())
+	assert.Equal(t, time.Second, resp.GetRules()[0].GetLatency().AsDuration())
+	assert.Equal(t, float64(100), resp.GetRules()[0].GetPercentage())
+
+	setResp, err := s.SetChaosRuleEnabled(ctx, &adminv1.SetChaosRuleEnabledRequest{Name: "slow", Enabled: true})
+	require.NoError(t, err)
+	assert.True(t, setResp.GetRule().GetEnabled())
+	assert.True(t, injector.Rules()[0].Enabled)
+
+	_, err = s.SetChaosRuleEnabled(ctx, &adminv1.SetChaosRuleEnabledRequest{Name: "unknown", Enabled: true})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
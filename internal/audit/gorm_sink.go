@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// verifyBatchSize is the number of the records read at once by the verification.
+	verifyBatchSize = 1000
+
+	// auditLockID is the key of the advisory lock serializing the writes of the records.
+	auditLockID = 0x61756469
+)
+
+// auditRecord is the database model of a Record.
+type auditRecord struct {
+	Seq        uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Time       time.Time `gorm:"not null"`
+	Caller     string    `gorm:"not null"`
+	Method     string    `gorm:"not null;index"`
+	Decision   string    `gorm:"size:16;not null"`
+	TraceID    string
+	RemoteAddr string
+	Code       string `gorm:"size:32;not null"`
+	PrevHash   string `gorm:"size:64;not null"`
+	Hash       string `gorm:"size:64;not null"`
+}
+
+func (auditRecord) TableName() string {
+	return "audit_records"
+}
+
+// GormSink writes the records into the database, the instances of the service share the chain of the table.
+//
+// NOTE: The writes are serialized across the instances by a PostgreSQL transaction-level advisory lock,
+// the chain head is read again from the table on every write.
+type GormSink struct {
+	db  *gorm.DB
+	key []byte
+}
+
+// NewGormSink returns a new sink backed by the database, migrates the table and checks the last record already in
+// the table is signed with the key. The records are signed with the key, which must not be stored in the database.
+func NewGormSink(db *gorm.DB, key []byte) (*GormSink, error) {
+	if len(key) == 0 {
+		return nil, ErrKeyRequired
+	}
+	if err := db.AutoMigrate(&auditRecord{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate audit records: %w", err)
+	}
+
+	s := &GormSink{db: db, key: key}
+	if _, err := s.head(db); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Write implements the Sink interface.
+func (s *GormSink) Write(ctx context.Context, r Record) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Holds the other writers until the commit, so the record is linked to the last committed one
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditLockID).Error; err != nil {
+			return err
+		}
+
+		c, err := s.head(tx)
+		if err != nil {
+			return err
+		}
+		c.link(&r)
+
+		rec := auditRecord(r)
+
+		return tx.Create(&rec).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write the audit record: %w", err)
+	}
+
+	return nil
+}
+
+// head returns the chain continuing the last record of the table.
+func (s *GormSink) head(tx *gorm.DB) (chain, error) {
+	c := chain{key: s.key}
+
+	var last auditRecord
+	err := tx.Order("seq DESC").Take(&last).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c, nil
+	case err != nil:
+		return c, err
+	}
+
+	if err := c.resume(last.toRecord()); err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+// VerifyDB verifies the chain of the records in the database signed with the key,
+// returns the number of the verified records.
+func VerifyDB(ctx context.Context, db *gorm.DB, key []byte) (int, error) {
+	if len(key) == 0 {
+		return 0, ErrKeyRequired
+	}
+	v := NewVerifier(key)
+
+	var records []auditRecord
+	err := db.WithContext(ctx).Order("seq").FindInBatches(&records, verifyBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, rec := range records {
+			if err := v.Add(rec.toRecord()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}).Error
+
+	return v.Count(), err
+}
+
+func (r auditRecord) toRecord() Record {
+	return Record(r)
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/identity"
+)
+
+// writeTimeout is the timeout of writing a record, which outlives the request context.
+const writeTimeout = 5 * time.Second
+
+// Auditor writes an audit record for every call of the audited methods.
+type Auditor struct {
+	sink    Sink
+	methods map[string]bool
+
+	now          func() time.Time
+	writeTimeout time.Duration
+}
+
+// NewAuditor returns a new auditor writing into the sink for the given full method names.
+func NewAuditor(sink Sink, methods ...string) *Auditor {
+	m := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		m[method] = true
+	}
+
+	return &Auditor{
+		sink:         sink,
+		methods:      m,
+		now:          time.Now,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// UnaryServerInterceptor returns a unary interceptor that audits the calls.
+//
+// NOTE: Should be chained before the authentication, so the denied calls are audited,
+// and before the grpcerr interceptor, so the records have the codes returned to the callers.
+func (a *Auditor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !a.methods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		tracked, caller := identity.Track(ctx)
+		resp, err := handler(tracked, req)
+		a.write(ctx, caller(), info.FullMethod, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that audits the calls.
+func (a *Auditor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !a.methods[info.FullMethod] {
+			return handler(srv, ss)
+		}
+
+		tracked, caller := identity.Track(ss.Context())
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = tracked
+
+		err := handler(srv, wrapped)
+		a.write(ss.Context(), caller(), info.FullMethod, err)
+
+		return err
+	}
+}
+
+func (a *Auditor) write(ctx context.Context, caller, fullMethod string, err error) {
+	code := status.Code(err)
+	reqCtx := coremiddleware.RequestContext(ctx)
+
+	r := Record{
+		Time:       a.now(),
+		Caller:     caller,
+		Method:     fullMethod,
+		Decision:   decisionOf(code),
+		TraceID:    reqCtx.TraceID(),
+		RemoteAddr: reqCtx.RemoteAddr(),
+		Code:       code.String(),
+	}
+
+	// The record is written even if the call is cancelled, but a stalled sink mustn't hang the call
+	writeCtx, cancel := context.WithTimeout(context.Background(), a.writeTimeout)
+	defer cancel()
+
+	if wErr := a.sink.Write(writeCtx, r); wErr != nil {
+		coremiddleware.Logger(ctx).WithError(wErr).WithField("audit_record", r).Error("Failed to write the audit record")
+	}
+}
+
+func decisionOf(code codes.Code) string {
+	if code == codes.PermissionDenied || code == codes.Unauthenticated {
+		return DecisionDenied
+	}
+
+	return DecisionAllowed
+}
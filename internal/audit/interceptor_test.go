@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/identity"
+)
+
+type memorySink struct {
+	m       sync.Mutex
+	chain   chain
+	records []Record
+	err     error
+}
+
+func (s *memorySink) Write(_ context.Context, r Record) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.chain.link(&r)
+	s.records = append(s.records, r)
+
+	return nil
+}
+
+// stalledSink blocks until the context of the write is done.
+type stalledSink struct{}
+
+func (stalledSink) Write(ctx context.Context, _ Record) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("no deadline")
+	}
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func TestAuditorUnaryServerInterceptor(t *testing.T) {
+	const method = "/test.Service/Delete"
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sink := &memorySink{}
+	a := NewAuditor(sink, method)
+	a.now = func() time.Time { return now }
+	interceptor := a.UnaryServerInterceptor()
+
+	ctx := coremiddleware.NewContextWithRequestCtx(context.Background(), coremiddleware.BuildRequestContext(
+		context.Background(), coremiddleware.EntryConfigs{}))
+	ctx = identity.NewContext(ctx, "user-1")
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return "resp", nil }
+	denied := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.PermissionDenied, "denied")
+	}
+
+	resp, err := interceptor(ctx, "req", &grpc.UnaryServerInfo{FullMethod: method}, ok)
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+
+	_, err = interceptor(ctx, "req", &grpc.UnaryServerInfo{FullMethod: method}, denied)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = interceptor(ctx, "req", &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}, ok)
+	require.NoError(t, err)
+
+	require.Len(t, sink.records, 2, "Must audit the configured methods only")
+	reqCtx := coremiddleware.RequestContext(ctx)
+	assert.Equal(t, Record{
+		Seq:        1,
+		Time:       now,
+		Caller:     "user-1",
+		Method:     method,
+		Decision:   DecisionAllowed,
+		TraceID:    reqCtx.TraceID(),
+		RemoteAddr: reqCtx.RemoteAddr(),
+		Code:       "OK",
+		Hash:       sink.records[0].Hash,
+	}, sink.records[0])
+	assert.Equal(t, DecisionDenied, sink.records[1].Decision)
+	assert.Equal(t, "PermissionDenied", sink.records[1].Code)
+
+	t.Run("Records the identity authenticated in the chain", func(t *testing.T) {
+		authenticating := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return ok(identity.NewContext(ctx, "user-2"), req)
+		}
+
+		_, err := interceptor(ctx, "req", &grpc.UnaryServerInfo{FullMethod: method}, authenticating)
+		require.NoError(t, err)
+		assert.Equal(t, "user-2", sink.records[len(sink.records)-1].Caller)
+	})
+
+	t.Run("Sink failure does not fail the call", func(t *testing.T) {
+		sink.err = errors.New("disk full")
+
+		resp, err := interceptor(ctx, "req", &grpc.UnaryServerInfo{FullMethod: method}, ok)
+		require.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+	})
+
+	t.Run("Stalled sink bounded", func(t *testing.T) {
+		a := NewAuditor(stalledSink{}, method)
+		a.writeTimeout = time.Millisecond
+		stalled := a.UnaryServerInterceptor()
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		done := make(chan error)
+		go func() {
+			_, err := stalled(ctx, "req", &grpc.UnaryServerInfo{FullMethod: method}, ok)
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Must bound the write of the record")
+		}
+	})
+}
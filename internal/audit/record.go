@@ -0,0 +1,137 @@
+// Package audit writes a tamper-evident audit trail of the security-relevant calls, separated from the
+// application logs. Every record is chained to the previous one by its HMAC, so a modified, removed or
+// reordered record breaks the chain, which is detected by the Verifier. The HMAC key is kept outside the log,
+// so the chain can't be rewritten with the write access to the log only.
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Decisions of the audited calls.
+const (
+	DecisionAllowed = "allowed"
+	DecisionDenied  = "denied"
+)
+
+var (
+	// ErrChainBroken is returned by the verification when a record is missing or tampered with.
+	ErrChainBroken = errors.New("audit chain broken")
+
+	// ErrKeyRequired is returned by the persistent sinks and the verification without an HMAC key.
+	ErrKeyRequired = errors.New("audit key is required")
+)
+
+// Record is an audit record of a call.
+type Record struct {
+	Seq        uint64    `json:"seq"`
+	Time       time.Time `json:"time"`
+	Caller     string    `json:"caller"`
+	Method     string    `json:"method"`
+	Decision   string    `json:"decision"`
+	TraceID    string    `json:"trace_id"`
+	RemoteAddr string    `json:"remote_addr"`
+	Code       string    `json:"code"`
+
+	// PrevHash is the HMAC of the previous record, empty for the first record.
+	PrevHash string `json:"prev_hash"`
+	// Hash is the HMAC of this record including the PrevHash.
+	Hash string `json:"hash"`
+}
+
+// digest returns the HMAC-SHA256 of the record, the time is hashed with microsecond precision in UTC,
+// so the HMAC is stable after a round trip through the database.
+func (r Record) digest(key []byte) string {
+	b, _ := json.Marshal([]interface{}{
+		r.Seq,
+		r.Time.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		r.Caller,
+		r.Method,
+		r.Decision,
+		r.TraceID,
+		r.RemoteAddr,
+		r.Code,
+		r.PrevHash,
+	})
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(b)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// chain links the records by their HMACs, it's not safe for concurrent use.
+type chain struct {
+	key  []byte
+	seq  uint64
+	hash string
+}
+
+// resume continues the chain after the last written record, which must be signed with the key of the chain.
+func (c *chain) resume(last Record) error {
+	if !hmac.Equal([]byte(last.Hash), []byte(last.digest(c.key))) {
+		return fmt.Errorf("%w: last record %d is tampered with or signed with another key", ErrChainBroken, last.Seq)
+	}
+	c.seq = last.Seq
+	c.hash = last.Hash
+
+	return nil
+}
+
+// link sets the sequence number and the hashes of the record.
+func (c *chain) link(r *Record) {
+	r.Seq = c.seq + 1
+	r.Time = r.Time.UTC().Truncate(time.Microsecond)
+	r.PrevHash = c.hash
+	r.Hash = r.digest(c.key)
+
+	c.seq = r.Seq
+	c.hash = r.Hash
+}
+
+// Verifier verifies the chain of records passed in order.
+type Verifier struct {
+	key   []byte
+	prev  *Record
+	count int
+}
+
+// NewVerifier returns a new verifier of the records signed with the key.
+func NewVerifier(key []byte) *Verifier {
+	return &Verifier{key: key}
+}
+
+// Add verifies the next record of the chain.
+func (v *Verifier) Add(r Record) error {
+	if !hmac.Equal([]byte(r.Hash), []byte(r.digest(v.key))) {
+		return fmt.Errorf("%w: record %d is tampered with", ErrChainBroken, r.Seq)
+	}
+
+	if v.prev == nil {
+		if r.Seq != 1 || r.PrevHash != "" {
+			return fmt.Errorf("%w: chain starts at record %d", ErrChainBroken, r.Seq)
+		}
+	} else {
+		if r.Seq != v.prev.Seq+1 {
+			return fmt.Errorf("%w: gap between records %d and %d", ErrChainBroken, v.prev.Seq, r.Seq)
+		}
+		if r.PrevHash != v.prev.Hash {
+			return fmt.Errorf("%w: record %d is not linked to record %d", ErrChainBroken, r.Seq, v.prev.Seq)
+		}
+	}
+
+	v.prev = &r
+	v.count++
+
+	return nil
+}
+
+// Count returns the number of the verified records.
+func (v *Verifier) Count() int {
+	return v.count
+}
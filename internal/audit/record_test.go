@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = []byte("test-key")
+
+func newChainedRecords(n int) []Record {
+	c := chain{key: testKey}
+	records := make([]Record, 0, n)
+	for i := 0; i < n; i++ {
+		r := Record{
+			Time:     time.Date(2020, 1, 1, 0, 0, i, 123456789, time.UTC),
+			Caller:   "caller",
+			Method:   "/test.Service/Method",
+			Decision: DecisionAllowed,
+			Code:     "OK",
+		}
+		c.link(&r)
+		records = append(records, r)
+	}
+
+	return records
+}
+
+func TestVerifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(records []Record) []Record
+		wantErr string
+	}{
+		{
+			name:   "Valid chain",
+			modify: func(records []Record) []Record { return records },
+		},
+		{
+			name: "Tampered record",
+			modify: func(records []Record) []Record {
+				records[1].Code = "PermissionDenied"
+
+				return records
+			},
+			wantErr: "record 2 is tampered with",
+		},
+		{
+			name: "Removed record",
+			modify: func(records []Record) []Record {
+				return append(records[:1], records[2:]...)
+			},
+			wantErr: "gap between records 1 and 3",
+		},
+		{
+			name: "Removed first record",
+			modify: func(records []Record) []Record {
+				return records[1:]
+			},
+			wantErr: "chain starts at record 2",
+		},
+		{
+			name: "Rewritten with another key",
+			modify: func(records []Record) []Record {
+				c := chain{key: []byte("forged-key")}
+				for i := range records {
+					records[i].Code = "PermissionDenied"
+					c.link(&records[i])
+				}
+
+				return records
+			},
+			wantErr: "record 1 is tampered with",
+		},
+		{
+			name: "Replaced record",
+			modify: func(records []Record) []Record {
+				forged := records[1]
+				forged.PrevHash = "forged"
+				forged.Hash = forged.digest(testKey)
+				records[1] = forged
+
+				return records
+			},
+			wantErr: "record 2 is not linked to record 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(testKey)
+
+			var err error
+			for _, r := range tt.modify(newChainedRecords(3)) {
+				if err = v.Add(r); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, 3, v.Count())
+
+				return
+			}
+			assert.ErrorIs(t, err, ErrChainBroken)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRecordDigestStableTime(t *testing.T) {
+	r := newChainedRecords(1)[0]
+
+	local := r
+	local.Time = r.Time.In(time.FixedZone("test", 3600))
+	assert.Equal(t, r.Hash, local.digest(testKey))
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// maxLineSize is the maximum size of a record line in the audit file.
+const maxLineSize = 1024 * 1024
+
+// Sink writes the audit records, the sinks link the records into a chain before writing them.
+type Sink interface {
+	Write(ctx context.Context, r Record) error
+}
+
+// FileSink writes the records into a file as JSON lines.
+type FileSink struct {
+	m     sync.Mutex
+	f     *os.File
+	chain chain
+}
+
+// NewFileSink opens the audit file for appending, and continues the chain of the records already in the file.
+// The records are signed with the key, which must not be stored along with the file.
+func NewFileSink(path string, key []byte) (*FileSink, error) {
+	if len(key) == 0 {
+		return nil, ErrKeyRequired
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the audit file: %w", err)
+	}
+
+	s := &FileSink{f: f, chain: chain{key: key}}
+	last, ok, err := lastRecord(f)
+	if err == nil && ok {
+		err = s.chain.resume(last)
+	}
+	if err != nil {
+		_ = f.Close()
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Write implements the Sink interface.
+func (s *FileSink) Write(_ context.Context, r Record) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	next := s.chain
+	next.link(&r)
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to write the audit record: %w", err)
+	}
+	s.chain = next
+
+	return nil
+}
+
+// Close closes the audit file.
+func (s *FileSink) Close() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.f.Close()
+}
+
+// MemorySink keeps the records in memory, e.g. in the tests, the records are signed with an empty key.
+type MemorySink struct {
+	m       sync.Mutex
+	chain   chain
+	records []Record
+}
+
+// Write implements the Sink interface.
+func (s *MemorySink) Write(_ context.Context, r Record) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.chain.link(&r)
+	s.records = append(s.records, r)
+
+	return nil
+}
+
+// Records returns a copy of the records written so far.
+func (s *MemorySink) Records() []Record {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return append([]Record(nil), s.records...)
+}
+
+// VerifyFile verifies the chain of the records in the audit file signed with the key,
+// returns the number of the verified records.
+func VerifyFile(path string, key []byte) (int, error) {
+	if len(key) == 0 {
+		return 0, ErrKeyRequired
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open the audit file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	v := NewVerifier(key)
+	err = readRecords(f, v.Add)
+
+	return v.Count(), err
+}
+
+func lastRecord(r io.Reader) (Record, bool, error) {
+	var last Record
+	found := false
+	err := readRecords(r, func(r Record) error {
+		last, found = r, true
+
+		return nil
+	})
+
+	return last, found, err
+}
+
+func readRecords(r io.Reader, fn func(r Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("%w: malformed record at line %d: %v", ErrChainBroken, line, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
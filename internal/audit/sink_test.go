@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	s, err := NewFileSink(path, testKey)
+	require.NoError(t, err)
+	require.NoError(t, s.Write(ctx, Record{Caller: "a", Method: "/test.Service/Method", Code: "OK"}))
+	require.NoError(t, s.Write(ctx, Record{Caller: "b", Method: "/test.Service/Method", Code: "OK"}))
+	require.NoError(t, s.Close())
+
+	// Continues the chain after reopening
+	s, err = NewFileSink(path, testKey)
+	require.NoError(t, err)
+	require.NoError(t, s.Write(ctx, Record{Caller: "c", Method: "/test.Service/Method", Code: "OK"}))
+	require.NoError(t, s.Close())
+
+	n, err := VerifyFile(path, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	t.Run("Requires the key", func(t *testing.T) {
+		_, err := NewFileSink(path, nil)
+		assert.ErrorIs(t, err, ErrKeyRequired)
+
+		_, err = VerifyFile(path, nil)
+		assert.ErrorIs(t, err, ErrKeyRequired)
+	})
+
+	t.Run("Fails with another key", func(t *testing.T) {
+		_, err := NewFileSink(path, []byte("another-key"))
+		assert.ErrorIs(t, err, ErrChainBroken, "Must not continue the chain signed with another key")
+
+		_, err = VerifyFile(path, []byte("another-key"))
+		assert.ErrorIs(t, err, ErrChainBroken)
+	})
+
+	t.Run("Detects tampering", func(t *testing.T) {
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(b), `"caller":"b"`, `"caller":"x"`, 1)), 0o600))
+
+		n, err := VerifyFile(path, testKey)
+		assert.ErrorIs(t, err, ErrChainBroken)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("Detects removed lines", func(t *testing.T) {
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.SplitAfter(string(b), "\n")
+		require.NoError(t, os.WriteFile(path, []byte(lines[0]+lines[2]), 0o600))
+
+		_, err = VerifyFile(path, testKey)
+		assert.ErrorIs(t, err, ErrChainBroken)
+	})
+
+	t.Run("Detects malformed lines", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("{not json\n"), 0o600))
+
+		_, err := VerifyFile(path, testKey)
+		assert.ErrorIs(t, err, ErrChainBroken)
+	})
+}
+
+func TestMemorySink(t *testing.T) {
+	s := &MemorySink{}
+	require.NoError(t, s.Write(context.Background(), Record{Caller: "a", Method: "/test.Service/Method", Code: "OK"}))
+	require.NoError(t, s.Write(context.Background(), Record{Caller: "b", Method: "/test.Service/Method", Code: "OK"}))
+
+	records := s.Records()
+	require.Len(t, records, 2)
+
+	v := NewVerifier(nil)
+	for _, r := range records {
+		require.NoError(t, v.Add(r), "Must link the records into a chain")
+	}
+	assert.Equal(t, 2, v.Count())
+}
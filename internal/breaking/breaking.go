@@ -0,0 +1,216 @@
+// Package breaking detects the wire-incompatible changes between two versions of the proto descriptors, e.g. between
+// the last released descriptor set and the current one, so the consumers are not broken by a release.
+package breaking
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Change represents a wire-incompatible change.
+type Change struct {
+	// Element is the full name of the changed element, e.g. "template.v1.UnaryEchoRequest.message".
+	Element string
+	// Description describes the change, e.g. "field number changed from 1 to 2".
+	Description string
+}
+
+// String returns the change in the `<element>: <description>` format.
+func (c Change) String() string {
+	return c.Element + ": " + c.Description
+}
+
+// Unmarshal parses a serialized FileDescriptorSet, e.g. the output of `protoc --descriptor_set_out`.
+func Unmarshal(b []byte) (*descriptorpb.FileDescriptorSet, error) {
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, set); err != nil {
+		return nil, fmt.Errorf("malformed descriptor set: %w", err)
+	}
+
+	return set, nil
+}
+
+// Check returns the wire-incompatible changes from the previous to the current descriptors, sorted by element:
+//   - removed services
+//   - removed or renamed RPCs, and RPCs whose request or response types or streaming changed
+//   - removed fields whose numbers are not reserved
+//   - fields whose numbers, types or cardinality changed
+func Check(previous, current *descriptorpb.FileDescriptorSet) []Change {
+	prev, cur := index(previous), index(current)
+
+	var changes []Change
+	add := func(element, format string, args ...interface{}) {
+		changes = append(changes, Change{Element: element, Description: fmt.Sprintf(format, args...)})
+	}
+
+	for name, ps := range prev.services {
+		cs, ok := cur.services[name]
+		if !ok {
+			add(name, "service removed")
+
+			continue
+		}
+		checkMethods(name, ps, cs, add)
+	}
+
+	for name, pm := range prev.messages {
+		// The removed messages are reported through the changed RPCs and fields referencing them
+		if cm, ok := cur.messages[name]; ok {
+			checkFields(name, pm, cm, add)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Element != changes[j].Element {
+			return changes[i].Element < changes[j].Element
+		}
+
+		return changes[i].Description < changes[j].Description
+	})
+
+	return changes
+}
+
+func checkMethods(service string, prev, cur *descriptorpb.ServiceDescriptorProto, add func(string, string, ...interface{})) {
+	methods := make(map[string]*descriptorpb.MethodDescriptorProto, len(cur.GetMethod()))
+	for _, m := range cur.GetMethod() {
+		methods[m.GetName()] = m
+	}
+
+	for _, pm := range prev.GetMethod() {
+		element := service + "." + pm.GetName()
+		cm, ok := methods[pm.GetName()]
+		if !ok {
+			add(element, "rpc removed or renamed")
+
+			continue
+		}
+
+		if pm.GetInputType() != cm.GetInputType() {
+			add(element, "request type changed from %s to %s", trimDot(pm.GetInputType()), trimDot(cm.GetInputType()))
+		}
+		if pm.GetOutputType() != cm.GetOutputType() {
+			add(element, "response type changed from %s to %s", trimDot(pm.GetOutputType()), trimDot(cm.GetOutputType()))
+		}
+		if pm.GetClientStreaming() != cm.GetClientStreaming() || pm.GetServerStreaming() != cm.GetServerStreaming() {
+			add(element, "streaming changed from %s to %s", streaming(pm), streaming(cm))
+		}
+	}
+}
+
+func checkFields(message string, prev, cur *descriptorpb.DescriptorProto, add func(string, string, ...interface{})) {
+	byName := make(map[string]*descriptorpb.FieldDescriptorProto, len(cur.GetField()))
+	byNumber := make(map[int32]*descriptorpb.FieldDescriptorProto, len(cur.GetField()))
+	for _, f := range cur.GetField() {
+		byName[f.GetName()] = f
+		byNumber[f.GetNumber()] = f
+	}
+
+	for _, pf := range prev.GetField() {
+		element := message + "." + pf.GetName()
+
+		cf, ok := byName[pf.GetName()]
+		switch {
+		case ok && cf.GetNumber() != pf.GetNumber():
+			add(element, "field number changed from %d to %d", pf.GetNumber(), cf.GetNumber())
+		case !ok && byNumber[pf.GetNumber()] == nil && !reserved(cur, pf.GetNumber()):
+			add(element, "field %d removed without reserving its number", pf.GetNumber())
+		}
+
+		// The wire format depends on the numbers, a field renamed keeping its number and type is compatible
+		if cf, ok := byNumber[pf.GetNumber()]; ok {
+			if fieldType(pf) != fieldType(cf) {
+				add(element, "field %d type changed from %s to %s", pf.GetNumber(), fieldType(pf), fieldType(cf))
+			}
+			if pf.GetLabel() != cf.GetLabel() {
+				add(element, "field %d cardinality changed from %s to %s", pf.GetNumber(), label(pf), label(cf))
+			}
+		}
+	}
+}
+
+// descriptors indexes the services and the messages, including the nested ones, by their full names.
+type descriptors struct {
+	services map[string]*descriptorpb.ServiceDescriptorProto
+	messages map[string]*descriptorpb.DescriptorProto
+}
+
+func index(set *descriptorpb.FileDescriptorSet) descriptors {
+	d := descriptors{
+		services: make(map[string]*descriptorpb.ServiceDescriptorProto),
+		messages: make(map[string]*descriptorpb.DescriptorProto),
+	}
+
+	var addMessages func(prefix string, messages []*descriptorpb.DescriptorProto)
+	addMessages = func(prefix string, messages []*descriptorpb.DescriptorProto) {
+		for _, m := range messages {
+			name := fullName(prefix, m.GetName())
+			d.messages[name] = m
+			addMessages(name, m.GetNestedType())
+		}
+	}
+
+	for _, f := range set.GetFile() {
+		for _, s := range f.GetService() {
+			d.services[fullName(f.GetPackage(), s.GetName())] = s
+		}
+		addMessages(f.GetPackage(), f.GetMessageType())
+	}
+
+	return d
+}
+
+func fullName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+func reserved(m *descriptorpb.DescriptorProto, number int32) bool {
+	for _, r := range m.GetReservedRange() {
+		// The end of the reserved ranges is exclusive
+		if number >= r.GetStart() && number < r.GetEnd() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldType returns the type of the field, with the type name for the messages and the enums,
+// e.g. "string" or "message template.v1.User".
+func fieldType(f *descriptorpb.FieldDescriptorProto) string {
+	t := strings.ToLower(strings.TrimPrefix(f.GetType().String(), "TYPE_"))
+	if f.GetTypeName() != "" {
+		t += " " + trimDot(f.GetTypeName())
+	}
+
+	return t
+}
+
+func label(f *descriptorpb.FieldDescriptorProto) string {
+	return strings.ToLower(strings.TrimPrefix(f.GetLabel().String(), "LABEL_"))
+}
+
+func streaming(m *descriptorpb.MethodDescriptorProto) string {
+	switch {
+	case m.GetClientStreaming() && m.GetServerStreaming():
+		return "bidirectional"
+	case m.GetClientStreaming():
+		return "client"
+	case m.GetServerStreaming():
+		return "server"
+	}
+
+	return "unary"
+}
+
+func trimDot(typeName string) string {
+	return strings.TrimPrefix(typeName, ".")
+}
@@ -0,0 +1,195 @@
+package breaking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   typ.Enum(),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+}
+
+func method(name, input, output string) *descriptorpb.MethodDescriptorProto {
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(input),
+		OutputType: proto.String(output),
+	}
+}
+
+// descriptorSet returns the descriptors of the `example.v1` package used as the baseline of the tests.
+func descriptorSet() *descriptorpb.FileDescriptorSet {
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("example/v1/example.proto"),
+		Package: proto.String("example.v1"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("GetRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+					field("verbose", 2, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+				},
+			},
+			{
+				Name: proto.String("GetResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("item", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name:  proto.String("Item"),
+					Field: []*descriptorpb.FieldDescriptorProto{field("count", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64)},
+				}},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("Items"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					method("Get", ".example.v1.GetRequest", ".example.v1.GetResponse"),
+					method("Watch", ".example.v1.GetRequest", ".example.v1.GetResponse"),
+				},
+			},
+			{Name: proto.String("Admin")},
+		},
+	}}}
+}
+
+func TestCheck(t *testing.T) {
+	messages := func(set *descriptorpb.FileDescriptorSet) []*descriptorpb.DescriptorProto {
+		return set.GetFile()[0].GetMessageType()
+	}
+	methods := func(set *descriptorpb.FileDescriptorSet) []*descriptorpb.MethodDescriptorProto {
+		return set.GetFile()[0].GetService()[0].GetMethod()
+	}
+
+	tests := []struct {
+		name     string
+		change   func(set *descriptorpb.FileDescriptorSet)
+		expected []string
+	}{
+		{
+			name:   "No change",
+			change: func(set *descriptorpb.FileDescriptorSet) {},
+		},
+		{
+			name: "Added elements",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				messages(set)[0].Field = append(messages(set)[0].Field, field("limit", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32))
+				set.GetFile()[0].GetService()[0].Method = append(methods(set), method("List", ".example.v1.GetRequest", ".example.v1.GetResponse"))
+				set.GetFile()[0].Service = append(set.GetFile()[0].Service, &descriptorpb.ServiceDescriptorProto{Name: proto.String("Events")})
+			},
+		},
+		{
+			name: "Renamed field",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				messages(set)[0].GetField()[1].Name = proto.String("detailed")
+			},
+		},
+		{
+			name: "Removed field with reserved number",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				messages(set)[0].Field = messages(set)[0].Field[:1]
+				messages(set)[0].ReservedRange = []*descriptorpb.DescriptorProto_ReservedRange{{Start: proto.Int32(2), End: proto.Int32(3)}}
+			},
+		},
+		{
+			name: "Removed field",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				messages(set)[0].Field = messages(set)[0].Field[:1]
+			},
+			expected: []string{"example.v1.GetRequest.verbose: field 2 removed without reserving its number"},
+		},
+		{
+			name: "Changed field number",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				messages(set)[0].GetField()[1].Number = proto.Int32(3)
+			},
+			expected: []string{"example.v1.GetRequest.verbose: field number changed from 2 to 3"},
+		},
+		{
+			name: "Changed field type",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				messages(set)[0].GetField()[0].Type = descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()
+			},
+			expected: []string{"example.v1.GetRequest.id: field 1 type changed from string to int64"},
+		},
+		{
+			name: "Changed nested field type and cardinality",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				count := messages(set)[1].GetNestedType()[0].GetField()[0]
+				count.Type = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+				count.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+			},
+			expected: []string{
+				"example.v1.GetResponse.Item.count: field 1 cardinality changed from optional to repeated",
+				"example.v1.GetResponse.Item.count: field 1 type changed from int64 to int32",
+			},
+		},
+		{
+			name: "Changed field message type",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				messages(set)[1].GetField()[0].TypeName = proto.String(".example.v1.Item")
+			},
+			expected: []string{"example.v1.GetResponse.item: field 1 type changed from message to message example.v1.Item"},
+		},
+		{
+			name: "Renamed RPC",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				methods(set)[0].Name = proto.String("Fetch")
+			},
+			expected: []string{"example.v1.Items.Get: rpc removed or renamed"},
+		},
+		{
+			name: "Changed RPC types and streaming",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				methods(set)[1].InputType = proto.String(".example.v1.WatchRequest")
+				methods(set)[1].ServerStreaming = proto.Bool(true)
+			},
+			expected: []string{
+				"example.v1.Items.Watch: request type changed from example.v1.GetRequest to example.v1.WatchRequest",
+				"example.v1.Items.Watch: streaming changed from unary to server",
+			},
+		},
+		{
+			name: "Removed service",
+			change: func(set *descriptorpb.FileDescriptorSet) {
+				set.GetFile()[0].Service = set.GetFile()[0].Service[:1]
+			},
+			expected: []string{"example.v1.Admin: service removed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := descriptorSet()
+			tt.change(current)
+
+			var changes []string
+			for _, c := range Check(descriptorSet(), current) {
+				changes = append(changes, c.String())
+			}
+			assert.Equal(t, tt.expected, changes)
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	b, err := proto.Marshal(descriptorSet())
+	require.NoError(t, err)
+
+	set, err := Unmarshal(b)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(descriptorSet(), set))
+
+	_, err = Unmarshal([]byte("not a descriptor set"))
+	assert.Error(t, err)
+}
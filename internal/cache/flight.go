@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errFlightPanicked is returned to the waiting calls when the call panicked.
+var errFlightPanicked = errors.New("the collapsed call panicked")
+
+// flightGroup collapses the concurrent calls with the same key into a single call.
+type flightGroup struct {
+	m     sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	// done is closed once the call returned.
+	done chan struct{}
+	// dups is the number of the collapsed calls waiting for the result.
+	dups int
+
+	resp interface{}
+	err  error
+}
+
+// do calls fn once for the concurrent calls with the same key, and returns its result to all of them,
+// the shared bool reports whether the result was produced by another call.
+// The waiting calls return the error of their context once it's done, without waiting for the result.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (interface{}, error)) (resp interface{}, shared bool, err error) {
+	g.m.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.m.Unlock()
+
+		select {
+		case <-c.done:
+			return c.resp, true, c.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+
+	c := &flightCall{done: make(chan struct{}), err: errFlightPanicked}
+	g.calls[key] = c
+	g.m.Unlock()
+
+	defer func() {
+		g.m.Lock()
+		delete(g.calls, key)
+		g.m.Unlock()
+		close(c.done)
+	}()
+
+	c.resp, c.err = fn()
+
+	return c.resp, false, c.err
+}
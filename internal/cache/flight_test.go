@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitDups waits until the given number of calls joined the in-flight call of the key.
+func waitDups(t *testing.T, g *flightGroup, key string, n int) {
+	assert.Eventually(t, func() bool {
+		g.m.Lock()
+		defer g.m.Unlock()
+
+		c, ok := g.calls[key]
+
+		return ok && c.dups == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestFlightGroup(t *testing.T) {
+	t.Run("Collapses the concurrent calls", func(t *testing.T) {
+		var g flightGroup
+		entered := make(chan struct{})
+		release := make(chan struct{})
+
+		go func() {
+			_, _, _ = g.do(context.Background(), "key", func() (interface{}, error) {
+				close(entered)
+				<-release
+
+				return "resp", nil
+			})
+		}()
+		<-entered
+
+		const n = 5
+		var wg sync.WaitGroup
+		wg.Add(n)
+		for i := 0; i < n; i++ {
+			go func() {
+				defer wg.Done()
+
+				resp, shared, err := g.do(context.Background(), "key", func() (interface{}, error) {
+					return "other", nil
+				})
+				assert.NoError(t, err)
+				assert.True(t, shared)
+				assert.Equal(t, "resp", resp)
+			}()
+		}
+
+		waitDups(t, &g, "key", n)
+		close(release)
+		wg.Wait()
+	})
+
+	t.Run("Waiters get an error on panic", func(t *testing.T) {
+		var g flightGroup
+		entered := make(chan struct{})
+		release := make(chan struct{})
+
+		go func() {
+			defer func() { _ = recover() }()
+			_, _, _ = g.do(context.Background(), "key", func() (interface{}, error) {
+				close(entered)
+				<-release
+				panic("boom")
+			})
+		}()
+		<-entered
+
+		done := make(chan error)
+		go func() {
+			_, _, err := g.do(context.Background(), "key", func() (interface{}, error) { return "other", nil })
+			done <- err
+		}()
+
+		waitDups(t, &g, "key", 1)
+		close(release)
+		assert.ErrorIs(t, <-done, errFlightPanicked)
+	})
+
+	t.Run("Waiters return on cancellation", func(t *testing.T) {
+		var g flightGroup
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+
+		go func() {
+			_, _, _ = g.do(context.Background(), "key", func() (interface{}, error) {
+				close(entered)
+				<-release
+
+				return "resp", nil
+			})
+		}()
+		<-entered
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			_, _, err := g.do(ctx, "key", func() (interface{}, error) { return "other", nil })
+			done <- err
+		}()
+
+		waitDups(t, &g, "key", 1)
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+}
@@ -0,0 +1,281 @@
+// Package cache caches the responses of the idempotent read-only unary methods.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/identity"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+)
+
+const (
+	// MetaKeyCacheControl represents the meta key to get the cache directives from client's request,
+	// the `no-cache` directive bypasses the cached response.
+	MetaKeyCacheControl = "cache-control"
+
+	// DefaultTTL is the default duration to keep the responses.
+	DefaultTTL = time.Minute
+
+	// DefaultTimeout is the default time limit of the collapsed calls of the handler.
+	DefaultTimeout = 5 * time.Second
+
+	// Results of the cache lookups used in the metrics.
+	resultHit    = "hit"
+	resultMiss   = "miss"
+	resultBypass = "bypass"
+
+	// keySeparator separates the method and the hash in the cache keys.
+	keySeparator = "|"
+)
+
+var (
+	cacheRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_cache_requests_total",
+			Help: "Total number of cache lookups by result (hit, miss or bypass).",
+		},
+		[]string{"grpc_service", "grpc_method", "result"},
+	)
+
+	cacheEvictions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_cache_evictions_total",
+			Help: "Total number of cached responses evicted due to the size bounds.",
+		},
+		[]string{"grpc_service", "grpc_method"},
+	)
+)
+
+// Params represents the parameters of the cache interceptor.
+type Params struct {
+	// Backend stores the responses, see NewLRU for the in-memory backend.
+	Backend Backend
+	// TTL is the duration to keep the responses, DefaultTTL is used if zero.
+	TTL time.Duration
+	// Timeout is the time limit of the collapsed calls of the handler, which don't inherit the deadline
+	// of the callers, DefaultTimeout is used if zero.
+	Timeout time.Duration
+	// Methods are the full method names to cache, the methods must be idempotent and read-only.
+	Methods []string
+	// MetadataKeys are the request metadata keys included in the cache keys,
+	// for the responses varying by metadata, e.g. "accept-language".
+	MetadataKeys []string
+	// Shared shares the cached responses across the callers and tenants, only for the responses
+	// not depending on the caller. The responses are cached per caller and tenant by default.
+	Shared bool
+}
+
+// Cache caches the responses of the configured methods.
+type Cache struct {
+	backend      Backend
+	ttl          time.Duration
+	timeout      time.Duration
+	methods      map[string]bool
+	metadataKeys []string
+	shared       bool
+
+	flight flightGroup
+}
+
+// New returns a new cache of the given params.
+func New(p Params) *Cache {
+	ttl := p.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	methods := make(map[string]bool, len(p.Methods))
+	for _, method := range p.Methods {
+		methods[method] = true
+	}
+
+	return &Cache{
+		backend:      p.Backend,
+		ttl:          ttl,
+		timeout:      timeout,
+		methods:      methods,
+		metadataKeys: p.MetadataKeys,
+		shared:       p.Shared,
+	}
+}
+
+// CountEviction counts an evicted key in the metrics, it's the eviction callback of the LRU backend.
+func CountEviction(key string) {
+	fullMethod := key
+	if i := strings.Index(key, keySeparator); i >= 0 {
+		fullMethod = key[:i]
+	}
+
+	service, method := protoutil.SplitMethodName(fullMethod)
+	cacheEvictions.With(prometheus.Labels{
+		"grpc_service": strings.ToLower(service),
+		"grpc_method":  strings.ToLower(method),
+	}).Inc()
+}
+
+// UnaryServerInterceptor returns a unary interceptor that serves the responses from the cache.
+func (c *Cache) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !c.methods[info.FullMethod] || c.backend == nil {
+			return handler(ctx, req)
+		}
+
+		key, err := c.key(ctx, info.FullMethod, req)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		l := coremiddleware.Logger(ctx)
+		if noCache(ctx) {
+			countRequest(info.FullMethod, resultBypass)
+		} else {
+			resp, ok := c.lookup(ctx, l, key)
+			if ok {
+				countRequest(info.FullMethod, resultHit)
+
+				return resp, nil
+			}
+			countRequest(info.FullMethod, resultMiss)
+		}
+
+		// Collapse the concurrent identical misses into a single call of the handler, which runs on a detached
+		// context, so the cancellation of the first caller doesn't fail the others waiting for it,
+		// the timeout bounds the handler which would otherwise never see a deadline
+		resp, shared, err := c.flight.do(ctx, key, func() (interface{}, error) {
+			flightCtx, cancel := context.WithTimeout(detachedContext{ctx}, c.timeout)
+			defer cancel()
+
+			resp, err := handler(flightCtx, req)
+			if err != nil {
+				return resp, err
+			}
+
+			if b, mErr := protoutil.MarshalAny(resp); mErr != nil {
+				l.WithError(mErr).Warn("Failed to marshal the response for the cache")
+			} else if sErr := c.backend.Set(flightCtx, key, b, c.ttl); sErr != nil {
+				l.WithError(sErr).Warn("Failed to store the response in the cache")
+			}
+
+			return resp, nil
+		})
+		if shared && err == nil {
+			// Every waiter gets its own copy, the next interceptors may modify the response
+			resp = protoutil.Clone(resp)
+		}
+
+		return resp, err
+	}
+}
+
+func (c *Cache) lookup(ctx context.Context, l *logrus.Entry, key string) (interface{}, bool) {
+	b, ok, err := c.backend.Get(ctx, key)
+	if err != nil {
+		l.WithError(err).Warn("Failed to get the response from the cache")
+
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	resp, err := protoutil.UnmarshalAny(b)
+	if err != nil {
+		l.WithError(err).Warn("Failed to unmarshal the cached response")
+
+		return nil, false
+	}
+
+	return resp, true
+}
+
+// key returns the cache key built from the method, the serialized request, the selected metadata,
+// and the caller and tenant unless the cache is shared.
+func (c *Cache) key(ctx context.Context, fullMethod string, req interface{}) (string, error) {
+	b, err := protoutil.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	_, _ = h.Write(b)
+
+	if !c.shared {
+		t, _ := tenant.FromContext(ctx)
+		for _, s := range []string{identity.Caller(ctx), t} {
+			_, _ = h.Write([]byte{0})
+			_, _ = h.Write([]byte(s))
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, k := range c.metadataKeys {
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(k))
+		for _, v := range md.Get(k) {
+			_, _ = h.Write([]byte{0})
+			_, _ = h.Write([]byte(v))
+		}
+	}
+
+	return fullMethod + keySeparator + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// detachedContext keeps the values of the parent context without its deadline and cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func noCache(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, v := range md.Get(MetaKeyCacheControl) {
+		for _, directive := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func countRequest(fullMethod, result string) {
+	service, method := protoutil.SplitMethodName(fullMethod)
+	cacheRequests.With(prometheus.Labels{
+		"grpc_service": strings.ToLower(service),
+		"grpc_method":  strings.ToLower(method),
+		"result":       result,
+	}).Inc()
+}
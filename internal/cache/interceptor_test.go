@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	"github.com/sliide/template-grpc-service/internal/identity"
+	"github.com/sliide/template-grpc-service/internal/metrictest"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+)
+
+const testMethod = "/template.v1.Echo/UnaryEcho"
+
+func TestCacheUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+
+		return &templatev1.UnaryEchoResponse{Message: req.(*templatev1.UnaryEchoRequest).GetMessage()}, nil
+	}
+
+	newInterceptor := func(metadataKeys ...string) grpc.UnaryServerInterceptor {
+		calls = 0
+
+		return New(Params{
+			Backend:      NewLRU(10, 0, CountEviction),
+			TTL:          time.Minute,
+			Methods:      []string{testMethod},
+			MetadataKeys: metadataKeys,
+		}).UnaryServerInterceptor()
+	}
+	callerCtx := func(caller, tenantID string) context.Context {
+		return tenant.NewContext(identity.NewContext(context.Background(), caller), tenantID)
+	}
+
+	t.Run("Serves the cached response", func(t *testing.T) {
+		interceptor := newInterceptor()
+		hits := cacheRequests.WithLabelValues("template.v1.echo", "unaryecho", resultHit)
+		before := metrictest.CounterValue(t, hits)
+
+		for i := 0; i < 2; i++ {
+			resp, err := interceptor(context.Background(), &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+			require.NoError(t, err)
+			assert.Equal(t, "hello", resp.(*templatev1.UnaryEchoResponse).GetMessage())
+		}
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, before+1, metrictest.CounterValue(t, hits))
+
+		_, err := interceptor(context.Background(), &templatev1.UnaryEchoRequest{Message: "bye"}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls, "Must call the handler for another request")
+	})
+
+	t.Run("Keyed by the metadata", func(t *testing.T) {
+		interceptor := newInterceptor("accept-language")
+		ctxOf := func(lang string) context.Context {
+			return metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", lang))
+		}
+
+		for _, ctx := range []context.Context{ctxOf("en"), ctxOf("el"), ctxOf("en")} {
+			_, err := interceptor(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Keyed by the caller and tenant", func(t *testing.T) {
+		interceptor := newInterceptor()
+
+		for _, ctx := range []context.Context{callerCtx("a", "t1"), callerCtx("b", "t1"), callerCtx("a", "t2"), callerCtx("a", "t1")} {
+			_, err := interceptor(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Shared across the callers", func(t *testing.T) {
+		calls = 0
+		interceptor := New(Params{
+			Backend: NewLRU(10, 0, CountEviction),
+			Methods: []string{testMethod},
+			Shared:  true,
+		}).UnaryServerInterceptor()
+
+		for _, ctx := range []context.Context{callerCtx("a", "t1"), callerCtx("b", "t2")} {
+			_, err := interceptor(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Collapsed calls", func(t *testing.T) {
+		calls = 0
+		c := New(Params{Backend: NewLRU(10, 0, CountEviction), Methods: []string{testMethod}})
+		interceptor := c.UnaryServerInterceptor()
+		started, release := make(chan struct{}), make(chan struct{})
+		slow := func(ctx context.Context, req interface{}) (interface{}, error) {
+			close(started)
+			<-release
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan interface{})
+		go func() {
+			resp, _ := interceptor(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, info, slow)
+			first <- resp
+		}()
+		<-started
+
+		second := make(chan interface{})
+		go func() {
+			resp, err := interceptor(context.Background(), &templatev1.UnaryEchoRequest{Message: "hello"}, info, slow)
+			assert.NoError(t, err, "Must not fail with the cancellation of the first caller")
+			second <- resp
+		}()
+		assert.Eventually(t, func() bool {
+			c.flight.m.Lock()
+			defer c.flight.m.Unlock()
+
+			for _, call := range c.flight.calls {
+				return call.dups == 1
+			}
+
+			return false
+		}, time.Second, time.Millisecond)
+
+		cancel()
+		close(release)
+
+		resp1, resp2 := <-first, <-second
+		require.NotNil(t, resp2)
+		assert.Equal(t, "hello", resp2.(*templatev1.UnaryEchoResponse).GetMessage())
+		assert.NotSame(t, resp1, resp2, "Must clone the shared response")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Collapsed call times out", func(t *testing.T) {
+		interceptor := New(Params{Backend: NewLRU(10, 0, CountEviction), Methods: []string{testMethod}, Timeout: time.Millisecond}).UnaryServerInterceptor()
+		blocking := func(ctx context.Context, req interface{}) (interface{}, error) {
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		}
+
+		_, err := interceptor(context.Background(), &templatev1.UnaryEchoRequest{Message: "hello"}, info, blocking)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Bypassed by no-cache", func(t *testing.T) {
+		interceptor := newInterceptor()
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetaKeyCacheControl, "no-cache"))
+
+		_, err := interceptor(context.Background(), &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+		_, err = interceptor(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		interceptor := newInterceptor()
+		failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+
+			return nil, errors.New("failed")
+		}
+
+		for i := 0; i < 2; i++ {
+			_, err := interceptor(context.Background(), &templatev1.UnaryEchoRequest{Message: "hello"}, info, failing)
+			assert.Error(t, err)
+		}
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Other methods are not cached", func(t *testing.T) {
+		interceptor := newInterceptor()
+		otherInfo := &grpc.UnaryServerInfo{FullMethod: "/template.v1.Echo/Other"}
+
+		for i := 0; i < 2; i++ {
+			_, err := interceptor(context.Background(), &templatev1.UnaryEchoRequest{Message: "hello"}, otherInfo, handler)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestCountEviction(t *testing.T) {
+	evictions := cacheEvictions.WithLabelValues("template.v1.echo", "unaryecho")
+	before := metrictest.CounterValue(t, evictions)
+
+	c := NewLRU(1, 0, CountEviction)
+	require.NoError(t, c.Set(context.Background(), testMethod+keySeparator+"a", []byte("1"), time.Minute))
+	require.NoError(t, c.Set(context.Background(), testMethod+keySeparator+"b", []byte("1"), time.Minute))
+
+	assert.Equal(t, before+1, metrictest.CounterValue(t, evictions))
+}
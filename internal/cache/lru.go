@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Backend stores the cached responses, it could be in-memory or shared across the instances (e.g. Redis).
+type Backend interface {
+	// Get returns the value of the key, the bool reports whether the key is found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores the value of the key for the ttl duration.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// LRU is an in-memory Backend bounded by the number of entries and the total size of the values,
+// the least recently used entries are evicted when exceeding the bounds.
+type LRU struct {
+	maxEntries int
+	maxBytes   int
+
+	// onEvict is called with the evicted key when an entry is evicted due to the bounds.
+	onEvict func(key string)
+
+	m     sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int
+
+	now func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRU returns a new LRU backend, zero means no limit for the maxEntries or maxBytes.
+func NewLRU(maxEntries, maxBytes int, onEvict func(key string)) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		onEvict:    onEvict,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// Get implements the Backend interface.
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	e := el.Value.(*lruEntry)
+	if !c.now().Before(e.expiresAt) {
+		c.remove(el)
+
+		return nil, false, nil
+	}
+	c.ll.MoveToFront(el)
+
+	return e.value, true, nil
+}
+
+// Set implements the Backend interface.
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.maxBytes > 0 && len(value) > c.maxBytes {
+		// Never fits, do not evict the other entries for it
+		return nil
+	}
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+
+	el := c.ll.PushFront(&lruEntry{
+		key:       key,
+		value:     value,
+		expiresAt: c.now().Add(ttl),
+	})
+	c.items[key] = el
+	c.bytes += len(value)
+
+	for c.exceeded() {
+		oldest := c.ll.Back()
+		c.remove(oldest)
+		if c.onEvict != nil {
+			c.onEvict(oldest.Value.(*lruEntry).key)
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of entries in the cache.
+func (c *LRU) Len() int {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *LRU) exceeded() bool {
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		return true
+	}
+
+	return c.maxBytes > 0 && c.bytes > c.maxBytes
+}
+
+// remove deletes the element, must be called with the lock held.
+func (c *LRU) remove(el *list.Element) {
+	e := el.Value.(*lruEntry)
+	c.ll.Remove(el)
+	delete(c.items, e.key)
+	c.bytes -= len(e.value)
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Expires the entries", func(t *testing.T) {
+		now := time.Now()
+		c := NewLRU(0, 0, nil)
+		c.now = func() time.Time { return now }
+
+		require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+
+		v, ok, err := c.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []byte("value"), v)
+
+		now = now.Add(time.Minute)
+		_, ok, err = c.Get(ctx, "key")
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("Evicts the least recently used entries", func(t *testing.T) {
+		var evicted []string
+		c := NewLRU(2, 0, func(key string) { evicted = append(evicted, key) })
+
+		require.NoError(t, c.Set(ctx, "a", []byte("1"), time.Minute))
+		require.NoError(t, c.Set(ctx, "b", []byte("2"), time.Minute))
+		_, _, _ = c.Get(ctx, "a")
+		require.NoError(t, c.Set(ctx, "c", []byte("3"), time.Minute))
+
+		assert.Equal(t, []string{"b"}, evicted)
+		assert.Equal(t, 2, c.Len())
+
+		_, ok, _ := c.Get(ctx, "a")
+		assert.True(t, ok)
+	})
+
+	t.Run("Bounded by bytes", func(t *testing.T) {
+		var evicted []string
+		c := NewLRU(0, 10, func(key string) { evicted = append(evicted, key) })
+
+		require.NoError(t, c.Set(ctx, "a", []byte("12345"), time.Minute))
+		require.NoError(t, c.Set(ctx, "b", []byte("12345"), time.Minute))
+		require.NoError(t, c.Set(ctx, "c", []byte("1"), time.Minute))
+		assert.Equal(t, []string{"a"}, evicted)
+
+		require.NoError(t, c.Set(ctx, "d", []byte("12345678901"), time.Minute))
+		_, ok, _ := c.Get(ctx, "d")
+		assert.False(t, ok, "Must not store a value larger than the bound")
+		assert.Equal(t, 2, c.Len())
+	})
+
+	t.Run("Replaces the value", func(t *testing.T) {
+		c := NewLRU(0, 10, nil)
+
+		require.NoError(t, c.Set(ctx, "a", []byte("12345"), time.Minute))
+		require.NoError(t, c.Set(ctx, "a", []byte("1234567890"), time.Minute))
+
+		v, ok, _ := c.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, []byte("1234567890"), v)
+		assert.Equal(t, 1, c.Len())
+	})
+}
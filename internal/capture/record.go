@@ -0,0 +1,109 @@
+// Package capture records a sample of the unary calls with their responses into a capture file, and replays the
+// captured calls to another instance reporting the differences, e.g. as regression tests before releasing.
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// maxLineSize is the maximum size of a record line in the capture files.
+const maxLineSize = 4 * 1024 * 1024
+
+// Record represents a captured call, written as a JSON line in the capture files.
+// The payloads are in the proto text format, so the records could be written by hand as golden test fixtures.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Method is the full method name of the call.
+	Method string `json:"method"`
+	// Metadata is the request metadata of the allowed keys.
+	Metadata map[string][]string `json:"metadata,omitempty"`
+	// RequestType is the full name of the request message, e.g. "template.v2.UnaryEchoRequest".
+	RequestType string `json:"request_type"`
+	// Request is the request in the proto text format.
+	Request string `json:"request"`
+	// Code is the status code of the call, either the number or the name, e.g. "NOT_FOUND".
+	Code codes.Code `json:"code"`
+	// Message is the status message of the failed calls.
+	Message string `json:"message,omitempty"`
+	// ResponseType is the full name of the response message, empty if the call failed.
+	ResponseType string `json:"response_type,omitempty"`
+	// Response is the response in the proto text format.
+	Response string `json:"response,omitempty"`
+}
+
+// Decode reads the records of the JSON lines, the empty lines are skipped.
+func Decode(r io.Reader) ([]Record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var records []Record
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("malformed record at line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+
+	return records, scanner.Err()
+}
+
+// ReadFile reads the records of a capture file.
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the capture file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	records, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the capture file %s: %w", path, err)
+	}
+
+	return records, nil
+}
+
+// encodeText serializes the message in the proto text format.
+func encodeText(m protoreflect.Message) (string, error) {
+	b, err := prototext.Marshal(m.Interface())
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// decodeText parses the message of the registered type from the proto text format.
+func decodeText(typeName, text string) (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(typeName))
+	if err != nil {
+		return nil, fmt.Errorf("unknown message type %q: %w", typeName, err)
+	}
+
+	m := mt.New().Interface()
+	if err := prototext.Unmarshal([]byte(text), m); err != nil {
+		return nil, fmt.Errorf("malformed %s: %w", typeName, err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,149 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+// Params represents the parameters of the recorder.
+type Params struct {
+	// Output receives the records as JSON lines, see rotate.Open for a rotating file.
+	Output io.Writer
+	// Rate is the sampling rate of the captured calls, between 0 (none) and 1 (all).
+	Rate float64
+	// Methods are the full method names captured, all unary methods if empty.
+	Methods []string
+	// MetadataKeys are the request metadata keys captured, the other keys (e.g. "authorization") are dropped.
+	MetadataKeys []string
+}
+
+// Recorder writes a sample of the calls into the output.
+type Recorder struct {
+	p       Params
+	methods map[string]bool
+
+	m      sync.Mutex
+	random func() float64
+	now    func() time.Time
+}
+
+// NewRecorder returns a new recorder of the given params.
+func NewRecorder(p Params) *Recorder {
+	methods := make(map[string]bool, len(p.Methods))
+	for _, m := range p.Methods {
+		methods[m] = true
+	}
+
+	return &Recorder{
+		p:       p,
+		methods: methods,
+		random:  rand.New(rand.NewSource(rand.Int63())).Float64, // nolint: gosec
+		now:     time.Now,
+	}
+}
+
+// UnaryServerInterceptor returns a unary interceptor that captures the sampled calls.
+//
+// NOTE: Must be chained before the grpcerr interceptor, so the records have the statuses returned to the callers,
+// and not the internal messages of the unknown errors.
+func (r *Recorder) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if r == nil || !r.sampled(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		m, ok := protoutil.Reflect(req)
+		if !ok {
+			return handler(ctx, req)
+		}
+		// Serialize the request before the handler could modify it
+		text, err := encodeText(m)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		rec := Record{
+			Time:        r.now(),
+			Method:      info.FullMethod,
+			Metadata:    r.metadata(ctx),
+			RequestType: string(m.Descriptor().FullName()),
+			Request:     text,
+		}
+
+		resp, err := handler(ctx, req)
+
+		st := status.Convert(err)
+		rec.Code, rec.Message = st.Code(), st.Message()
+		if rm, ok := protoutil.Reflect(resp); ok && err == nil {
+			rec.ResponseType = string(rm.Descriptor().FullName())
+			rec.Response, _ = encodeText(rm)
+		}
+
+		if wErr := r.write(rec); wErr != nil {
+			coremiddleware.Logger(ctx).WithError(wErr).Warn("Failed to write the captured call")
+		}
+
+		return resp, err
+	}
+}
+
+func (r *Recorder) sampled(fullMethod string) bool {
+	if len(r.methods) > 0 && !r.methods[fullMethod] {
+		return false
+	}
+
+	switch {
+	case r.p.Rate <= 0:
+		return false
+	case r.p.Rate >= 1:
+		return true
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.random() < r.p.Rate
+}
+
+func (r *Recorder) metadata(ctx context.Context) map[string][]string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var out map[string][]string
+	for _, k := range r.p.MetadataKeys {
+		values := md.Get(k)
+		if len(values) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string][]string)
+		}
+		out[k] = values
+	}
+
+	return out
+}
+
+func (r *Recorder) write(rec Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	_, err = r.p.Output.Write(append(b, '\n'))
+
+	return err
+}
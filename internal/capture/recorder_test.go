@@ -0,0 +1,177 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+)
+
+const testMethod = "/template.v1.Echo/UnaryEcho"
+
+func TestRecorderUnaryServerInterceptor(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"accept-language", "en",
+		"authorization", "Bearer secret",
+	))
+
+	testCases := []struct {
+		name    string
+		params  Params
+		method  string
+		handler grpc.UnaryHandler
+		want    []Record
+	}{
+		{
+			name:   "Response",
+			params: Params{Rate: 1, MetadataKeys: []string{"accept-language", "x-missing"}},
+			method: testMethod,
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &templatev1.UnaryEchoResponse{Message: "hello"}, nil
+			},
+			want: []Record{{
+				Time:         now,
+				Method:       testMethod,
+				Metadata:     map[string][]string{"accept-language": {"en"}},
+				RequestType:  "template.v1.UnaryEchoRequest",
+				Request:      `message:"hello"`,
+				Code:         codes.OK,
+				ResponseType: "template.v1.UnaryEchoResponse",
+				Response:     `message:"hello"`,
+			}},
+		},
+		{
+			name:   "Error",
+			params: Params{Rate: 1},
+			method: testMethod,
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, status.Error(codes.NotFound, "not found")
+			},
+			want: []Record{{
+				Time:        now,
+				Method:      testMethod,
+				RequestType: "template.v1.UnaryEchoRequest",
+				Request:     `message:"hello"`,
+				Code:        codes.NotFound,
+				Message:     "not found",
+			}},
+		},
+		{
+			name:   "Domain error",
+			params: Params{Rate: 1},
+			method: testMethod,
+			handler: mapped(func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, fmt.Errorf("device: %w", grpcerr.ErrNotFound)
+			}),
+			want: []Record{{
+				Time:        now,
+				Method:      testMethod,
+				RequestType: "template.v1.UnaryEchoRequest",
+				Request:     `message:"hello"`,
+				Code:        codes.NotFound,
+				Message:     "Not found",
+			}},
+		},
+		{
+			name:   "Plain error",
+			params: Params{Rate: 1},
+			method: testMethod,
+			handler: mapped(func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, errors.New("pq: password authentication failed")
+			}),
+			want: []Record{{
+				Time:        now,
+				Method:      testMethod,
+				RequestType: "template.v1.UnaryEchoRequest",
+				Request:     `message:"hello"`,
+				Code:        codes.Internal,
+				Message:     "InternalServerError",
+			}},
+		},
+		{
+			name:   "Not sampled",
+			params: Params{Rate: 0},
+			method: testMethod,
+		},
+		{
+			name:   "Other method",
+			params: Params{Rate: 1, Methods: []string{"/template.v1.Echo/Other"}},
+			method: testMethod,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := bytes.NewBuffer(nil)
+			tc.params.Output = b
+			r := NewRecorder(tc.params)
+			r.now = func() time.Time { return now }
+
+			handler := tc.handler
+			if handler == nil {
+				handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+					return &templatev1.UnaryEchoResponse{}, nil
+				}
+			}
+			_, _ = r.UnaryServerInterceptor()(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+
+			records, err := Decode(b)
+			require.NoError(t, err)
+			require.Len(t, records, len(tc.want))
+			for i := range records {
+				// The spacing of the text format isn't stable
+				records[i].Request = compact(records[i].Request)
+				records[i].Response = compact(records[i].Response)
+			}
+			assert.Equal(t, tc.want, records)
+		})
+	}
+
+	t.Run("Nil recorder", func(t *testing.T) {
+		var r *Recorder
+		resp, err := r.UnaryServerInterceptor()(ctx, &templatev1.UnaryEchoRequest{}, &grpc.UnaryServerInfo{FullMethod: testMethod},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return req, nil
+			})
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+	})
+}
+
+func TestDecode(t *testing.T) {
+	records, err := Decode(bytes.NewBufferString(`{"method":"/a.B/C","code":"NOT_FOUND"}
+
+{"method":"/a.B/D","code":0}
+`))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, codes.NotFound, records[0].Code)
+	assert.Equal(t, codes.OK, records[1].Code)
+
+	_, err = Decode(bytes.NewBufferString("{\n"))
+	assert.EqualError(t, err, "malformed record at line 1: unexpected end of JSON input")
+}
+
+// mapped returns the handler chained after the grpcerr interceptor, like in the server.
+func mapped(handler grpc.UnaryHandler) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return grpcerr.UnaryServerInterceptor("test-service")(ctx, req, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+	}
+}
+
+func compact(s string) string {
+	return string(bytes.Join(bytes.Fields([]byte(s)), nil))
+}
@@ -0,0 +1,101 @@
+package capture
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+// Result represents the outcome of a replayed record.
+type Result struct {
+	Record Record
+	// Code and Message are the status of the replayed call.
+	Code    codes.Code
+	Message string
+	// Diff are the names of the top-level response fields differing from the captured response.
+	Diff []string
+	// Err is the error preventing the replay, e.g. an unknown message type.
+	Err error
+}
+
+// Matched returns true if the replayed call returned the captured status code and response.
+func (r Result) Matched() bool {
+	return r.Err == nil && r.Code == r.Record.Code && len(r.Diff) == 0
+}
+
+// String returns a one-line summary of the result.
+func (r Result) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: error: %v", r.Record.Method, r.Err)
+	case r.Code != r.Record.Code:
+		return fmt.Sprintf("%s: status %s, captured %s (%s)", r.Record.Method, r.Code, r.Record.Code, r.Message)
+	case len(r.Diff) > 0:
+		return fmt.Sprintf("%s: response fields %v differ", r.Record.Method, r.Diff)
+	}
+
+	return fmt.Sprintf("%s: match", r.Record.Method)
+}
+
+// Replay sends the captured call with its metadata to the connection, and compares the status code and
+// the response with the captured ones. The message types must be registered, i.e. their packages imported.
+func Replay(ctx context.Context, conn grpc.ClientConnInterface, rec Record) Result {
+	res := Result{Record: rec}
+
+	req, err := decodeText(rec.RequestType, rec.Request)
+	if err != nil {
+		res.Err = err
+
+		return res
+	}
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		res.Err = err
+
+		return res
+	}
+
+	if len(rec.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.MD(rec.Metadata).Copy())
+	}
+	resp := &protoutil.Frame{}
+	err = conn.Invoke(ctx, rec.Method, &protoutil.Frame{Bytes: b}, resp, grpc.ForceCodec(protoutil.RawCodec{}))
+	st := status.Convert(err)
+	res.Code, res.Message = st.Code(), st.Message()
+	if err != nil || rec.ResponseType == "" {
+		return res
+	}
+
+	want, err := decodeText(rec.ResponseType, rec.Response)
+	if err != nil {
+		res.Err = err
+
+		return res
+	}
+	got := want.ProtoReflect().New().Interface()
+	if err := proto.Unmarshal(resp.Bytes, got); err != nil {
+		res.Err = fmt.Errorf("malformed response: %w", err)
+
+		return res
+	}
+	res.Diff = protoutil.DiffFields(want, got)
+
+	return res
+}
+
+// ReplayAll replays the records in order, the results are in the order of the records.
+func ReplayAll(ctx context.Context, conn grpc.ClientConnInterface, records []Record) []Result {
+	results := make([]Result, 0, len(records))
+	for _, rec := range records {
+		results = append(results, Replay(ctx, conn, rec))
+	}
+
+	return results
+}
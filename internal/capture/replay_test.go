@@ -0,0 +1,141 @@
+package capture
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+)
+
+// echoServer echoes the messages, it upper cases the messages starting with "diff", fails the messages starting
+// with "fail", and echoes the "accept-language" metadata for the "language" message.
+type echoServer struct {
+	templatev1.UnimplementedEchoServer
+}
+
+func (*echoServer) UnaryEcho(ctx context.Context, req *templatev1.UnaryEchoRequest) (*templatev1.UnaryEchoResponse, error) {
+	msg := req.GetMessage()
+	switch {
+	case strings.HasPrefix(msg, "diff"):
+		msg = strings.ToUpper(msg)
+	case strings.HasPrefix(msg, "fail"):
+		return nil, status.Error(codes.Internal, "failed")
+	case msg == "language":
+		md, _ := metadata.FromIncomingContext(ctx)
+		msg = strings.Join(md.Get("accept-language"), ",")
+	}
+
+	return &templatev1.UnaryEchoResponse{Message: msg}, nil
+}
+
+func dialEcho(t *testing.T) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	templatev1.RegisterEchoServer(s, &echoServer{})
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func TestReplay(t *testing.T) {
+	conn := dialEcho(t)
+
+	record := func(req, resp string, code codes.Code) Record {
+		rec := Record{
+			Method:      testMethod,
+			RequestType: "template.v1.UnaryEchoRequest",
+			Request:     req,
+			Code:        code,
+		}
+		if code == codes.OK {
+			rec.ResponseType = "template.v1.UnaryEchoResponse"
+			rec.Response = resp
+		}
+
+		return rec
+	}
+
+	withMetadata := record(`message: "language"`, `message: "fr"`, codes.OK)
+	withMetadata.Metadata = map[string][]string{"accept-language": {"fr"}}
+	unknownType := record(`message: "hello"`, "", codes.OK)
+	unknownType.RequestType = "template.v1.Unknown"
+
+	testCases := []struct {
+		name    string
+		record  Record
+		matched bool
+		code    codes.Code
+		diff    []string
+		err     string
+	}{
+		{name: "Match", record: record(`message: "hello"`, `message: "hello"`, codes.OK), matched: true},
+		{name: "Metadata", record: withMetadata, matched: true},
+		{name: "Error match", record: record(`message: "fail"`, "", codes.Internal), matched: true, code: codes.Internal},
+		{name: "Response mismatch", record: record(`message: "diff"`, `message: "diff"`, codes.OK), diff: []string{"message"}},
+		{name: "Code mismatch", record: record(`message: "fail"`, `message: "fail"`, codes.OK), code: codes.Internal},
+		{name: "Unknown type", record: unknownType, err: `unknown message type "template.v1.Unknown"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := Replay(context.Background(), conn, tc.record)
+
+			assert.Equal(t, tc.matched, res.Matched(), res.String())
+			if tc.err != "" {
+				require.Error(t, res.Err)
+				assert.Contains(t, res.Err.Error(), tc.err)
+
+				return
+			}
+			assert.NoError(t, res.Err)
+			assert.Equal(t, tc.code, res.Code)
+			assert.Equal(t, tc.diff, res.Diff)
+		})
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	conn := dialEcho(t)
+	r := NewRecorder(Params{Output: &strings.Builder{}, Rate: 1})
+
+	// Capture the calls of the echo server, then replay them to the same server
+	var records []Record
+	for _, msg := range []string{"hello", "fail"} {
+		b := &strings.Builder{}
+		r.p.Output = b
+		_, _ = r.UnaryServerInterceptor()(context.Background(), &templatev1.UnaryEchoRequest{Message: msg}, &grpc.UnaryServerInfo{FullMethod: testMethod},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return (&echoServer{}).UnaryEcho(ctx, req.(*templatev1.UnaryEchoRequest))
+			})
+
+		recs, err := Decode(strings.NewReader(b.String()))
+		require.NoError(t, err)
+		records = append(records, recs...)
+	}
+	require.Len(t, records, 2)
+
+	for _, res := range ReplayAll(context.Background(), conn, records) {
+		assert.True(t, res.Matched(), res.String())
+	}
+}
@@ -0,0 +1,244 @@
+// Package chaos injects faults into the calls matching the configured rules, to exercise the resilience
+// of the service and its clients, e.g. the timeouts, retries and the Recovery interceptor.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"gopkg.in/yaml.v3"
+)
+
+// prodEnv is the ENV value of the production environments, where the faults are refused unless overridden.
+const prodEnv = "prod"
+
+var (
+	// ErrProdNotAllowed is returned when enabling the fault injection in production without the override.
+	ErrProdNotAllowed = errors.New("fault injection is not allowed in production")
+
+	// ErrRuleNotFound is returned when toggling an unknown rule.
+	ErrRuleNotFound = errors.New("chaos rule not found")
+)
+
+// Rule represents the faults injected into a percentage of the matching calls,
+// the latency is injected first, then the panic, the status code or the stream drop.
+type Rule struct {
+	// Name identifies the rule, it's used to toggle the rule at runtime.
+	Name string `yaml:"name" json:"name"`
+	// Enabled reports whether the faults are injected, the rules are disabled unless set.
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Methods are the full method names, or the service prefixes ending with a slash, e.g. "/template.v2.Echo/",
+	// all methods match if empty.
+	Methods []string `yaml:"methods" json:"methods"`
+	// Tenants are the tenants of the calls, all calls match if empty.
+	Tenants []string `yaml:"tenants" json:"tenants"`
+	// Percentage is the percentage of the matching calls getting the faults, all of them if zero.
+	Percentage float64 `yaml:"percentage" json:"percentage"`
+
+	// Latency delays the calls.
+	Latency time.Duration `yaml:"latency" json:"latency"`
+	// Code is the gRPC code returned without calling the handler, e.g. "UNAVAILABLE".
+	Code string `yaml:"code" json:"code"`
+	// DropStreamAfter fails the streams with the UNAVAILABLE code after sending or receiving that many messages.
+	DropStreamAfter int `yaml:"drop_stream_after" json:"drop_stream_after"`
+	// Panic panics in the unary calls only, a panic in a stream would crash the service as the stream
+	// interceptors have no Recovery.
+	Panic bool `yaml:"panic" json:"panic"`
+
+	code codes.Code
+}
+
+// validate checks the rule and parses its code.
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if r.Percentage < 0 || r.Percentage > 100 {
+		return fmt.Errorf("%s: percentage must be between 0 and 100", r.Name)
+	}
+	if r.Percentage == 0 {
+		r.Percentage = 100
+	}
+	if r.Latency < 0 || r.DropStreamAfter < 0 {
+		return fmt.Errorf("%s: latency and drop_stream_after must not be negative", r.Name)
+	}
+	if r.Code != "" {
+		code, ok := parseCode(r.Code)
+		if !ok {
+			return fmt.Errorf("%s: invalid code %q", r.Name, r.Code)
+		}
+		r.code = code
+	}
+	if r.Latency == 0 && r.Code == "" && r.DropStreamAfter == 0 && !r.Panic {
+		return fmt.Errorf("%s: no fault", r.Name)
+	}
+
+	return nil
+}
+
+// parseCode parses the gRPC code names, e.g. "DEADLINE_EXCEEDED" or "DeadlineExceeded".
+func parseCode(name string) (codes.Code, bool) {
+	name = strings.ReplaceAll(name, "_", "")
+	for c := codes.Canceled; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(c.String(), name) {
+			return c, true
+		}
+	}
+
+	return codes.OK, false
+}
+
+// matches returns true if the rule matches the method and the tenant.
+func (r *Rule) matches(fullMethod, tenant string) bool {
+	if len(r.Methods) > 0 {
+		found := false
+		for _, m := range r.Methods {
+			if m == fullMethod || (strings.HasSuffix(m, "/") && strings.HasPrefix(fullMethod, m)) {
+				found = true
+
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.Tenants) > 0 {
+		for _, t := range r.Tenants {
+			if t == tenant {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// Config represents the fault injection rules, in the following YAML (or JSON) format.
+//
+//	rules:
+//	  - name: slow-echo
+//	    enabled: true
+//	    methods: [/template.v2.Echo/UnaryEcho]
+//	    percentage: 10
+//	    latency: 2s
+//	  - name: unavailable-team-a
+//	    tenants: [team-a]
+//	    code: UNAVAILABLE
+type Config struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// LoadFile loads the rules from a YAML (or JSON) file.
+func LoadFile(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read chaos rules: %w", err)
+	}
+
+	cfg := Config{}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse chaos rules: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Params represents the parameters of the Injector.
+type Params struct {
+	// Env is the environment of the service, the ENV env variable.
+	Env string
+	// AllowProd allows the fault injection in production.
+	AllowProd bool
+	Config    Config
+}
+
+// Injector injects the faults of its rules, the rules can be toggled at runtime.
+type Injector struct {
+	m     sync.RWMutex
+	rules []Rule
+
+	random func() float64
+}
+
+// New returns a new injector of the rules, or ErrProdNotAllowed in production without the override.
+func New(p Params) (*Injector, error) {
+	if strings.EqualFold(p.Env, prodEnv) && !p.AllowProd {
+		return nil, ErrProdNotAllowed
+	}
+
+	rules := make([]Rule, len(p.Config.Rules))
+	names := make(map[string]bool, len(rules))
+	for i, r := range p.Config.Rules {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("invalid chaos rule: %w", err)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("invalid chaos rule: duplicate name %s", r.Name)
+		}
+		names[r.Name] = true
+		rules[i] = r
+	}
+
+	return &Injector{
+		rules:  rules,
+		random: rand.Float64,
+	}, nil
+}
+
+// Rules returns the rules and their current state.
+func (inj *Injector) Rules() []Rule {
+	inj.m.RLock()
+	defer inj.m.RUnlock()
+
+	return append([]Rule(nil), inj.rules...)
+}
+
+// SetEnabled enables or disables a rule, returns the updated rule, or ErrRuleNotFound.
+func (inj *Injector) SetEnabled(name string, enabled bool) (Rule, error) {
+	inj.m.Lock()
+	defer inj.m.Unlock()
+
+	for i := range inj.rules {
+		if inj.rules[i].Name == name {
+			inj.rules[i].Enabled = enabled
+
+			return inj.rules[i], nil
+		}
+	}
+
+	return Rule{}, ErrRuleNotFound
+}
+
+// match returns the first enabled rule matching the call and selected by its percentage.
+func (inj *Injector) match(fullMethod, tenant string, stream bool) (Rule, bool) {
+	inj.m.RLock()
+	defer inj.m.RUnlock()
+
+	for _, r := range inj.rules {
+		if !r.Enabled || !r.matches(fullMethod, tenant) {
+			continue
+		}
+		if stream && r.Latency == 0 && r.Code == "" && r.DropStreamAfter == 0 {
+			// Only the panic which is not supported by the streams
+			continue
+		}
+		if r.Percentage < 100 && inj.random()*100 >= r.Percentage {
+			continue
+		}
+
+		return r, true
+	}
+
+	return Rule{}, false
+}
@@ -0,0 +1,128 @@
+package chaos
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+const testConfig = `
+rules:
+  - name: slow-echo
+    enabled: true
+    methods: [/template.v1.Echo/UnaryEcho]
+    percentage: 10
+    latency: 2s
+  - name: unavailable-team-a
+    methods: [/template.v1.Echo/]
+    tenants: [team-a]
+    code: UNAVAILABLE
+`
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chaos.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testConfig), 0o600))
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Rules, 2)
+	assert.Equal(t, 2*time.Second, cfg.Rules[0].Latency)
+	assert.True(t, cfg.Rules[0].Enabled)
+	assert.False(t, cfg.Rules[1].Enabled, "Must be disabled by default")
+
+	inj, err := New(Params{Env: "dev", Config: cfg})
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), inj.Rules()[1].Percentage, "Must default to all calls")
+	assert.Equal(t, codes.Unavailable, inj.Rules()[1].code)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("rules: invalid"), 0o600))
+	_, err = LoadFile(path)
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   Params
+		expected error
+		hasError bool
+	}{
+		{name: "dev", params: Params{Env: "dev"}},
+		{name: "prod", params: Params{Env: "prod"}, expected: ErrProdNotAllowed, hasError: true},
+		{name: "prod override", params: Params{Env: "PROD", AllowProd: true}},
+		{name: "missing name", params: Params{Config: Config{Rules: []Rule{{Panic: true}}}}, hasError: true},
+		{name: "no fault", params: Params{Config: Config{Rules: []Rule{{Name: "a"}}}}, hasError: true},
+		{name: "invalid code", params: Params{Config: Config{Rules: []Rule{{Name: "a", Code: "BROKEN"}}}}, hasError: true},
+		{name: "invalid percentage", params: Params{Config: Config{Rules: []Rule{{Name: "a", Panic: true, Percentage: 101}}}}, hasError: true},
+		{name: "duplicate name", params: Params{Config: Config{Rules: []Rule{{Name: "a", Panic: true}, {Name: "a", Panic: true}}}}, hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.params)
+			if !tt.hasError {
+				assert.NoError(t, err)
+
+				return
+			}
+			assert.Error(t, err)
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	for _, name := range []string{"DEADLINE_EXCEEDED", "DeadlineExceeded", "deadline_exceeded"} {
+		code, ok := parseCode(name)
+		assert.True(t, ok)
+		assert.Equal(t, codes.DeadlineExceeded, code)
+	}
+
+	_, ok := parseCode("OK")
+	assert.False(t, ok, "Must not inject OK")
+}
+
+func TestInjectorMatch(t *testing.T) {
+	inj, err := New(Params{Config: Config{Rules: []Rule{
+		{Name: "half", Enabled: true, Methods: []string{"/test.Service/Get"}, Percentage: 50, Code: "UNAVAILABLE"},
+		{Name: "team-a", Methods: []string{"/test.Service/"}, Tenants: []string{"team-a"}, Code: "INTERNAL"},
+		{Name: "panic", Enabled: true, Methods: []string{"/test.Service/Watch"}, Panic: true},
+	}}})
+	require.NoError(t, err)
+
+	inj.random = func() float64 { return 0.6 }
+	_, ok := inj.match("/test.Service/Get", "", false)
+	assert.False(t, ok, "Must not select the call over the percentage")
+
+	inj.random = func() float64 { return 0.4 }
+	r, ok := inj.match("/test.Service/Get", "", false)
+	assert.True(t, ok)
+	assert.Equal(t, "half", r.Name)
+
+	_, ok = inj.match("/test.Service/List", "team-a", false)
+	assert.False(t, ok, "Must not match the disabled rules")
+
+	r, err = inj.SetEnabled("team-a", true)
+	require.NoError(t, err)
+	assert.True(t, r.Enabled)
+	r, ok = inj.match("/test.Service/List", "team-a", false)
+	assert.True(t, ok)
+	assert.Equal(t, "team-a", r.Name)
+	_, ok = inj.match("/test.Service/List", "team-b", false)
+	assert.False(t, ok)
+	_, ok = inj.match("/other.Service/List", "team-a", false)
+	assert.False(t, ok)
+
+	_, ok = inj.match("/test.Service/Watch", "", true)
+	assert.False(t, ok, "Must not panic in the streams")
+
+	_, err = inj.SetEnabled("unknown", true)
+	assert.ErrorIs(t, err, ErrRuleNotFound)
+}
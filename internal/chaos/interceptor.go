@@ -0,0 +1,171 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+)
+
+// Faults used in the logs and the metrics.
+const (
+	faultLatency = "latency"
+	faultPanic   = "panic"
+	faultCode    = "code"
+	faultDrop    = "drop_stream"
+)
+
+var injectedFaults = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "grpc_chaos_faults_total",
+		Help: "Total number of faults injected by the chaos rules.",
+	},
+	[]string{"rule", "fault"},
+)
+
+// UnaryServerInterceptor returns a unary interceptor that injects the faults of the matching rules.
+//
+// The injected latency stops once the context of the call is done. The Timeout interceptor doesn't pass its deadline
+// to the handlers, so after a timeout the latency keeps its goroutine until the call completes and its context is
+// cancelled, or until the deadline of the caller.
+//
+// NOTE: Must be chained before a Recovery interceptor to recover the injected panics.
+func (inj *Injector) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if inj == nil {
+			return handler(ctx, req)
+		}
+
+		rule, ok := inj.matchCall(ctx, info.FullMethod, false)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		if err := inject(ctx, rule); err != nil {
+			return nil, err
+		}
+		if rule.Panic {
+			logFault(ctx, rule, faultPanic)
+			panic(fmt.Sprintf("chaos: panic injected by the rule %s", rule.Name))
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that injects the faults of the matching rules,
+// except the panics as the stream interceptors have no Recovery.
+func (inj *Injector) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if inj == nil {
+			return handler(srv, ss)
+		}
+
+		ctx := ss.Context()
+		rule, ok := inj.matchCall(ctx, info.FullMethod, true)
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		if err := inject(ctx, rule); err != nil {
+			return err
+		}
+		if rule.DropStreamAfter > 0 {
+			ss = &droppingStream{
+				WrappedServerStream: grpcmiddleware.WrapServerStream(ss),
+				rule:                rule,
+			}
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// matchCall returns the rule injecting faults into the call if any.
+func (inj *Injector) matchCall(ctx context.Context, fullMethod string, stream bool) (Rule, bool) {
+	t, _ := tenant.FromContext(ctx)
+
+	return inj.match(fullMethod, t, stream)
+}
+
+// inject injects the latency and the code of the rule, returns the error returned to the caller if any.
+// The latency is interrupted when the context is done, so it never outlives the call.
+func inject(ctx context.Context, rule Rule) error {
+	if rule.Latency > 0 {
+		logFault(ctx, rule, faultLatency)
+
+		timer := time.NewTimer(rule.Latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+
+	if rule.Code != "" {
+		logFault(ctx, rule, faultCode)
+
+		return status.Errorf(rule.code, "chaos: %s injected by the rule %s", rule.code, rule.Name)
+	}
+
+	return nil
+}
+
+// droppingStream fails the stream after sending or receiving the number of messages of the rule.
+type droppingStream struct {
+	*grpcmiddleware.WrappedServerStream
+
+	rule     Rule
+	messages int32
+}
+
+func (s *droppingStream) SendMsg(m interface{}) error {
+	if err := s.drop(); err != nil {
+		return err
+	}
+
+	return s.WrappedServerStream.SendMsg(m)
+}
+
+func (s *droppingStream) RecvMsg(m interface{}) error {
+	if err := s.drop(); err != nil {
+		return err
+	}
+
+	return s.WrappedServerStream.RecvMsg(m)
+}
+
+// drop returns an Unavailable error once the stream has reached its number of messages.
+func (s *droppingStream) drop() error {
+	n := atomic.AddInt32(&s.messages, 1)
+	if int(n) <= s.rule.DropStreamAfter {
+		return nil
+	}
+	if int(n) == s.rule.DropStreamAfter+1 {
+		logFault(s.Context(), s.rule, faultDrop)
+	}
+
+	return status.Errorf(codes.Unavailable, "chaos: stream dropped by the rule %s", s.rule.Name)
+}
+
+// logFault logs and counts a fault injected into the call.
+func logFault(ctx context.Context, rule Rule, fault string) {
+	injectedFaults.WithLabelValues(rule.Name, fault).Inc()
+	coremiddleware.Logger(ctx).WithFields(logrus.Fields{
+		"chaos_rule":  rule.Name,
+		"chaos_fault": fault,
+	}).Warn("Chaos fault injected")
+}
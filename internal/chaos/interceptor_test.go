@@ -0,0 +1,126 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sliide/template-grpc-service/internal/tenant"
+)
+
+func newTestInjector(t *testing.T, rules ...Rule) *Injector {
+	for i := range rules {
+		rules[i].Enabled = true
+	}
+	inj, err := New(Params{Config: Config{Rules: rules}})
+	require.NoError(t, err)
+
+	return inj
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+
+	t.Run("Latency", func(t *testing.T) {
+		inj := newTestInjector(t, Rule{Name: "slow", Latency: 20 * time.Millisecond})
+
+		start := time.Now()
+		_, err := inj.UnaryServerInterceptor()(context.Background(), struct{}{}, info, handler)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		inj = newTestInjector(t, Rule{Name: "slower", Latency: time.Minute})
+		_, err = inj.UnaryServerInterceptor()(ctx, struct{}{}, info, handler)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		ctx, cancel = context.WithCancel(context.Background())
+		time.AfterFunc(time.Millisecond, cancel)
+		start = time.Now()
+		_, err = inj.UnaryServerInterceptor()(ctx, struct{}{}, info, handler)
+		assert.Equal(t, codes.Canceled, status.Code(err))
+		assert.Less(t, int64(time.Since(start)), int64(time.Second), "Must stop the latency once the call is cancelled")
+	})
+
+	t.Run("Code", func(t *testing.T) {
+		inj := newTestInjector(t, Rule{Name: "unavailable", Code: "UNAVAILABLE", Tenants: []string{"team-a"}})
+
+		_, err := inj.UnaryServerInterceptor()(tenant.NewContext(context.Background(), "team-a"), struct{}{}, info, handler)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+
+		_, err = inj.UnaryServerInterceptor()(context.Background(), struct{}{}, info, handler)
+		assert.NoError(t, err, "Must not match the calls of other tenants")
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		inj := newTestInjector(t, Rule{Name: "panic", Panic: true})
+
+		assert.Panics(t, func() {
+			_, _ = inj.UnaryServerInterceptor()(context.Background(), struct{}{}, info, handler)
+		})
+	})
+
+	t.Run("Nil injector", func(t *testing.T) {
+		_, err := (*Injector)(nil).UnaryServerInterceptor()(context.Background(), struct{}{}, info, handler)
+		assert.NoError(t, err)
+	})
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	sent int
+}
+
+func (s *testServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *testServerStream) SendMsg(interface{}) error {
+	s.sent++
+
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}
+
+	t.Run("Drop", func(t *testing.T) {
+		inj := newTestInjector(t, Rule{Name: "drop", DropStreamAfter: 2})
+		ss := &testServerStream{}
+
+		err := inj.StreamServerInterceptor()(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+			for i := 0; i < 5; i++ {
+				if err := stream.SendMsg(i); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, 2, ss.sent)
+	})
+
+	t.Run("Code", func(t *testing.T) {
+		inj := newTestInjector(t, Rule{Name: "aborted", Code: "ABORTED"})
+
+		called := false
+		err := inj.StreamServerInterceptor()(nil, &testServerStream{}, info, func(interface{}, grpc.ServerStream) error {
+			called = true
+
+			return nil
+		})
+		assert.Equal(t, codes.Aborted, status.Code(err))
+		assert.False(t, called)
+	})
+}
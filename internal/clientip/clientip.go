@@ -0,0 +1,182 @@
+// Package clientip resolves the client IP of the calls behind the trusted proxies.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// MetaKeyForwardedFor represents the meta key of the client IP and the proxies forwarding the request,
+	// in the `<client>, <proxy1>, <proxy2>` format.
+	MetaKeyForwardedFor = "x-forwarded-for"
+
+	// MetaKeyRealIP represents the meta key of the client IP set by some proxies.
+	MetaKeyRealIP = "x-real-ip"
+)
+
+// ParseCIDRs parses the CIDRs, e.g. "10.0.0.0/8", the IPs without a prefix length match a single address.
+func ParseCIDRs(values []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP: %s", v)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+
+	return nets, nil
+}
+
+// Resolver resolves the client IP from the forwarded-for metadata set by the trusted proxies.
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// NewResolver returns a resolver trusting the proxies in the given networks, the forwarded-for metadata
+// is stripped and the client IP is the peer IP if no network is given.
+func NewResolver(trusted []*net.IPNet) *Resolver {
+	return &Resolver{
+		trusted: trusted,
+	}
+}
+
+// Trusted returns true if the IP is in the networks of the trusted proxies.
+func (r *Resolver) Trusted(ip net.IP) bool {
+	for _, n := range r.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolve returns the client IP of a connection from the peer IP and the forwarded-for values, the values are only
+// used if the peer is a trusted proxy, and are walked from the nearest proxy until an untrusted IP is found.
+func (r *Resolver) Resolve(peerIP net.IP, forwardedFor []string, realIP string) net.IP {
+	if peerIP == nil || !r.Trusted(peerIP) {
+		return peerIP
+	}
+
+	var hops []string
+	for _, v := range forwardedFor {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	if len(hops) == 0 && realIP != "" {
+		hops = []string{realIP}
+	}
+
+	client := peerIP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// The values before a malformed one cannot be trusted
+			break
+		}
+
+		client = ip
+		if !r.Trusted(ip) {
+			break
+		}
+	}
+
+	return client
+}
+
+// newContext returns a new context whose forwarded-for metadata only contains the resolved client IP,
+// so the remote address of the request context is the client IP.
+func (r *Resolver) newContext(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+
+	var realIP string
+	if v := md.Get(MetaKeyRealIP); len(v) > 0 {
+		realIP = v[0]
+	}
+
+	client := r.Resolve(peerIP(p.Addr), md.Get(MetaKeyForwardedFor), realIP)
+	delete(md, MetaKeyRealIP)
+	if client == nil {
+		delete(md, MetaKeyForwardedFor)
+	} else {
+		md.Set(MetaKeyForwardedFor, client.String())
+	}
+
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// UnaryServerInterceptor returns a unary interceptor that resolves the client IP,
+// the interceptor does nothing if the resolver is nil.
+//
+// NOTE: Must be chained before the Entry interceptor, which builds the remote address of the request context.
+func (r *Resolver) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if r == nil {
+			return handler(ctx, req)
+		}
+
+		return handler(r.newContext(ctx), req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that resolves the client IP,
+// the interceptor does nothing if the resolver is nil.
+func (r *Resolver) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if r == nil {
+			return handler(srv, ss)
+		}
+
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = r.newContext(ss.Context())
+
+		return handler(srv, wrapped)
+	}
+}
+
+// peerIP returns the IP of the peer address, nil if the address has no IP.
+func peerIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+
+	return net.ParseIP(host)
+}
@@ -0,0 +1,83 @@
+package clientip
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+)
+
+func TestParseCIDRs(t *testing.T) {
+	nets, err := ParseCIDRs([]string{"10.0.0.0/8", " 192.0.2.1 ", "2001:db8::/32"})
+	require.NoError(t, err)
+	require.Len(t, nets, 3)
+	assert.Equal(t, "10.0.0.0/8", nets[0].String())
+	assert.Equal(t, "192.0.2.1/32", nets[1].String())
+	assert.Equal(t, "2001:db8::/32", nets[2].String())
+
+	_, err = ParseCIDRs([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseCIDRs([]string{"invalid"})
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	trusted, err := ParseCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	r := NewResolver(trusted)
+
+	tests := []struct {
+		name         string
+		peer         string
+		forwardedFor []string
+		realIP       string
+		expected     string
+	}{
+		{name: "untrusted peer", peer: "192.0.2.1", forwardedFor: []string{"198.51.100.1"}, expected: "192.0.2.1"},
+		{name: "trusted peer", peer: "10.0.0.1", forwardedFor: []string{"198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "spoofed hops", peer: "10.0.0.1", forwardedFor: []string{"203.0.113.1, 198.51.100.1, 10.0.0.2"}, expected: "198.51.100.1"},
+		{name: "several values", peer: "10.0.0.1", forwardedFor: []string{"203.0.113.1", "198.51.100.1"}, expected: "198.51.100.1"},
+		{name: "all trusted", peer: "10.0.0.1", forwardedFor: []string{"10.0.0.3, 10.0.0.2"}, expected: "10.0.0.3"},
+		{name: "malformed hop", peer: "10.0.0.1", forwardedFor: []string{"198.51.100.1, unknown, 10.0.0.2"}, expected: "10.0.0.2"},
+		{name: "real IP", peer: "10.0.0.1", realIP: "198.51.100.1", expected: "198.51.100.1"},
+		{name: "no forwarded-for", peer: "10.0.0.1", expected: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, r.Resolve(net.ParseIP(tt.peer), tt.forwardedFor, tt.realIP).String())
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	trusted, err := ParseCIDRs([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	call := func(r *Resolver, peerAddr string, md metadata.MD) string {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(peerAddr), Port: 1234}})
+		ctx = metadata.NewIncomingContext(ctx, md)
+
+		var remoteAddr string
+		_, _ = r.UnaryServerInterceptor()(ctx, "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			remoteAddr = coremiddleware.BuildRequestContext(ctx, coremiddleware.EntryConfigs{}).RemoteAddr()
+
+			return nil, nil
+		})
+
+		return remoteAddr
+	}
+
+	md := metadata.Pairs(MetaKeyForwardedFor, "198.51.100.1", MetaKeyRealIP, "203.0.113.1")
+	assert.Equal(t, "198.51.100.1", call(NewResolver(trusted), "10.0.0.1", md))
+	assert.Equal(t, "192.0.2.1", call(NewResolver(trusted), "192.0.2.1", md), "Must ignore the metadata of untrusted peers")
+	assert.Equal(t, "192.0.2.1", call(NewResolver(nil), "192.0.2.1", md), "Must not trust the metadata without trusted proxies")
+	assert.Equal(t, []string{"198.51.100.1"}, md.Get(MetaKeyForwardedFor), "Must not modify the original metadata")
+}
@@ -2,10 +2,16 @@ package configs
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
 
+// redactedValue replaces the values of the secret fields, tagged with `secret:"true"`.
+const redactedValue = "[REDACTED]"
+
 type Config struct {
 	// `HOSTNAME` is provided by OS and should always be non-empty
 	Hostname     string `env:"HOSTNAME"`
@@ -14,7 +20,133 @@ type Config struct {
 	LogLevel     string `env:"LOG_LEVEL" envDefault:"INFO"`
 	ListenAddr   string `env:"SERVER_LISTEN_ADDR" envDefault:"0.0.0.0:8080"`
 	PprofEnabled bool   `env:"PPROF_ENABLED" envDefault:"true"`
-	RdsURL       string `env:"RDS_URL,required"`
+	RdsURL       string `env:"RDS_URL,required" secret:"true"`
+
+	// InternalListenAddr enables a trusted internal listener besides the public listener on the ListenAddr,
+	// the public listener doesn't trust the trace IDs from the requests then. PublicServices and InternalServices
+	// are the full names of the services registered on the listeners, all services if empty.
+	InternalListenAddr string   `env:"INTERNAL_LISTEN_ADDR"`
+	PublicServices     []string `env:"PUBLIC_SERVICES"`
+	InternalServices   []string `env:"INTERNAL_SERVICES"`
+
+	// DeprecationSunsets are the sunset dates of the deprecated methods or services in the
+	// `<full method or service prefix>=<YYYY-MM-DD>` format, signalled to the callers in the response headers.
+	// The calls are counted by the DeprecationUserAgents products, the other products are counted as "other".
+	DeprecationSunsets    []string `env:"DEPRECATION_SUNSETS"`
+	DeprecationUserAgents []string `env:"DEPRECATION_USER_AGENTS"`
+
+	// TrustedProxyCIDRs are the networks of the proxies whose forwarded-for metadata is trusted to resolve the client
+	// IP, the forwarded-for metadata is ignored if empty. ProxyProtocol requires the PROXY protocol header on the
+	// connections of the public listener, which is only accepted from the trusted proxies, required then.
+	TrustedProxyCIDRs []string `env:"TRUSTED_PROXY_CIDRS"`
+	ProxyProtocol     bool     `env:"PROXY_PROTOCOL" envDefault:"false"`
+
+	// IPFilterFile is an optional YAML/JSON file of the IP allow and deny lists of the listeners and their methods,
+	// reloaded every IPFilterReloadInterval when modified.
+	IPFilterFile           string        `env:"IP_FILTER_FILE"`
+	IPFilterReloadInterval time.Duration `env:"IP_FILTER_RELOAD_INTERVAL" envDefault:"30s"`
+
+	// TenantEnabled identifies the tenant of the calls from the TenantClaim of the authenticated callers, or the
+	// TenantHeader metadata, and enforces their daily and monthly quotas set in the optional TenantQuotasFile.
+	TenantEnabled    bool   `env:"TENANT_ENABLED" envDefault:"false"`
+	TenantHeader     string `env:"TENANT_HEADER" envDefault:"x-tenant-id"`
+	TenantClaim      string `env:"TENANT_CLAIM" envDefault:"tenant"`
+	TenantRequired   bool   `env:"TENANT_REQUIRED" envDefault:"false"`
+	TenantQuotasFile string `env:"TENANT_QUOTAS_FILE"`
+
+	// ChaosEnabled injects the faults of the rules in the optional ChaosRulesFile, the rules can be toggled with the
+	// admin service. It's refused when ENV is prod unless ChaosAllowProd is set.
+	ChaosEnabled   bool   `env:"CHAOS_ENABLED" envDefault:"false"`
+	ChaosRulesFile string `env:"CHAOS_RULES_FILE"`
+	ChaosAllowProd bool   `env:"CHAOS_ALLOW_PROD" envDefault:"false"`
+
+	// ShadowTarget is the address of the shadow target receiving a copy of ShadowRate of the unary calls
+	// of the ShadowMethods (required), with their ShadowMetadataKeys metadata, disabled if empty. The shadow calls
+	// are limited by ShadowTimeout and ShadowMaxConcurrency, and ShadowLogRate of the differences with the primary
+	// calls are logged.
+	ShadowTarget         string        `env:"SHADOW_TARGET"`
+	ShadowRate           float64       `env:"SHADOW_RATE" envDefault:"0.01"`
+	ShadowMethods        []string      `env:"SHADOW_METHODS"`
+	ShadowMetadataKeys   []string      `env:"SHADOW_METADATA_KEYS"`
+	ShadowTimeout        time.Duration `env:"SHADOW_TIMEOUT" envDefault:"1s"`
+	ShadowMaxConcurrency int           `env:"SHADOW_MAX_CONCURRENCY" envDefault:"10"`
+	ShadowLogRate        float64       `env:"SHADOW_LOG_RATE" envDefault:"0.01"`
+
+	// CaptureFile is the file receiving CaptureRate of the unary calls of the CaptureMethods (all if empty), with
+	// their CaptureMetadataKeys metadata, rotated when exceeding the CaptureMaxSize. Disabled if empty, the captured
+	// calls can be replayed with the `replay` command.
+	CaptureFile         string   `env:"CAPTURE_FILE"`
+	CaptureRate         float64  `env:"CAPTURE_RATE" envDefault:"0.01"`
+	CaptureMethods      []string `env:"CAPTURE_METHODS"`
+	CaptureMetadataKeys []string `env:"CAPTURE_METADATA_KEYS"`
+	CaptureMaxSize      int64    `env:"CAPTURE_MAX_SIZE" envDefault:"1073741824"`
+
+	// GeoIPDBFile is an optional MaxMind GeoIP2/GeoLite2 City or Country database file to look up the client IPs,
+	// reloaded every GeoIPReloadInterval when modified.
+	GeoIPDBFile         string        `env:"GEOIP_DB_FILE"`
+	GeoIPReloadInterval time.Duration `env:"GEOIP_RELOAD_INTERVAL" envDefault:"1m"`
+	// GeoIPCountryRulesFile is an optional YAML/JSON file of the allowed and denied countries of the methods.
+	GeoIPCountryRulesFile string `env:"GEOIP_COUNTRY_RULES_FILE"`
+
+	// TLSCertFile and TLSKeyFile enable TLS on the public listener.
+	TLSCertFile string `env:"SERVER_TLS_CERT_FILE"`
+	TLSKeyFile  string `env:"SERVER_TLS_KEY_FILE"`
+
+	// LogSamplingFirst messages with the same level and message are kept in every LogSamplingInterval, and then
+	// one in LogSamplingThereafter, a summary of the suppressed messages is logged. Disabled if it's zero, the default.
+	LogSamplingFirst      int           `env:"LOG_SAMPLING_FIRST" envDefault:"0"`
+	LogSamplingThereafter int           `env:"LOG_SAMPLING_THEREAFTER" envDefault:"100"`
+	LogSamplingInterval   time.Duration `env:"LOG_SAMPLING_INTERVAL" envDefault:"1m"`
+
+	// AccessLogFile is the file of the access log, one line per RPC and per monitoring HTTP request,
+	// rotated when exceeding the AccessLogMaxSize. The access log is disabled if it's empty.
+	AccessLogFile       string  `env:"ACCESS_LOG_FILE"`
+	AccessLogFormat     string  `env:"ACCESS_LOG_FORMAT" envDefault:"json"`
+	AccessLogSampleRate float64 `env:"ACCESS_LOG_SAMPLE_RATE" envDefault:"1"`
+	AccessLogMaxSize    int64   `env:"ACCESS_LOG_MAX_SIZE" envDefault:"2147483648"`
+
+	// AdminToken protects the admin endpoints and the Admin gRPC service, which are disabled if it's empty.
+	// The Admin gRPC service is served on the AdminListenAddr, which must be internal-only.
+	AdminToken      string `env:"ADMIN_TOKEN" secret:"true"`
+	AdminListenAddr string `env:"ADMIN_LISTEN_ADDR" envDefault:"127.0.0.1:8081"`
+
+	// RPCZSize is the number of the recent requests and errors per method shown in the `/debug/rpcz` page,
+	// the page is disabled if it's zero, the default, as it shows the error messages and the trace IDs of the calls.
+	RPCZSize int `env:"RPCZ_SIZE" envDefault:"0"`
+
+	// PayloadLogRate is the sampling rate (0 to 1) of the calls logging their request and response payloads,
+	// PayloadLogMethodRates overrides it per method in the `<full method>=<rate>` format.
+	PayloadLogRate         float64  `env:"PAYLOAD_LOG_RATE" envDefault:"0"`
+	PayloadLogMethodRates  []string `env:"PAYLOAD_LOG_METHOD_RATES"`
+	PayloadLogRedactFields []string `env:"PAYLOAD_LOG_REDACT_FIELDS"`
+	PayloadLogMaxBytes     int      `env:"PAYLOAD_LOG_MAX_BYTES" envDefault:"4096"`
+
+	// AuditMethods are the full method names whose calls are written into the audit trail,
+	// into the AuditFile if set, or into the DB otherwise. The records are signed with the AuditKey,
+	// which is required with the AuditMethods and must not be stored along with the trail.
+	AuditMethods []string `env:"AUDIT_METHODS"`
+	AuditFile    string   `env:"AUDIT_FILE"`
+	AuditKey     string   `env:"AUDIT_KEY" secret:"true"`
+
+	// ValidationRulesFile is an optional YAML/JSON file of request validation rules,
+	// which are added to the rules defined in the code.
+	ValidationRulesFile string `env:"VALIDATION_RULES_FILE"`
+
+	// IdempotentMethods are the full method names which support the `idempotency-key` metadata.
+	// The keys of the anonymous callers are scoped by their IP, so require authentication for these methods.
+	// IdempotencyLease is how long the key of a request in progress is kept, must be longer than the RPC timeout.
+	IdempotentMethods []string      `env:"IDEMPOTENT_METHODS"`
+	IdempotencyTTL    time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+	IdempotencyLease  time.Duration `env:"IDEMPOTENCY_LEASE" envDefault:"30s"`
+
+	// CacheMethods are the full method names of the idempotent read-only methods whose responses are cached.
+	CacheMethods      []string      `env:"CACHE_METHODS"`
+	CacheTTL          time.Duration `env:"CACHE_TTL" envDefault:"1m"`
+	CacheMaxEntries   int           `env:"CACHE_MAX_ENTRIES" envDefault:"10000"`
+	CacheMaxBytes     int           `env:"CACHE_MAX_BYTES" envDefault:"67108864"`
+	CacheMetadataKeys []string      `env:"CACHE_METADATA_KEYS"`
+	// CacheShared shares the cached responses across the callers and tenants, they're cached per caller by default.
+	CacheShared bool `env:"CACHE_SHARED"`
 }
 
 func Load() (Config, error) {
@@ -25,3 +157,40 @@ func Load() (Config, error) {
 
 	return cfg, nil
 }
+
+// Redacted returns the config values by their env variable names, with the non-empty secrets redacted.
+func (c Config) Redacted() map[string]string {
+	v := reflect.ValueOf(c)
+	t := v.Type()
+
+	values := make(map[string]string, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("env"), ",")[0]
+		if name == "" {
+			continue
+		}
+
+		value := fieldString(v.Field(i))
+		if f.Tag.Get("secret") == "true" && value != "" {
+			value = redactedValue
+		}
+		values[name] = value
+	}
+
+	return values
+}
+
+// fieldString formats the value of a field, the slices are formatted like their env variables.
+func fieldString(v reflect.Value) string {
+	if v.Kind() != reflect.Slice {
+		return fmt.Sprint(v.Interface())
+	}
+
+	items := make([]string, v.Len())
+	for i := range items {
+		items[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,163 @@
+// Package deprecation signals the calls of the deprecated methods and API versions to the callers with response
+// headers, and counts them by the known user agents to find the callers to migrate before the sunset.
+package deprecation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+const (
+	// MetaKeyDeprecation represents the response meta key set to "true" on the calls of the deprecated methods.
+	MetaKeyDeprecation = "deprecation"
+	// MetaKeySunset represents the response meta key of the sunset date of the deprecated methods, in the HTTP date
+	// format like the Sunset HTTP header (RFC 8594), e.g. "Wed, 30 Jun 2027 00:00:00 GMT".
+	MetaKeySunset = "sunset"
+
+	// dateLayout is the layout of the sunset dates in the config.
+	dateLayout = "2006-01-02"
+
+	// unknownUserAgent is the user agent label of the calls without a valid user agent.
+	unknownUserAgent = "unknown"
+	// otherUserAgent is the user agent label of the calls whose product is not listed.
+	otherUserAgent = "other"
+)
+
+// validUserAgent matches the product tokens of the user agents, e.g. "my-app/1.2.3".
+var validUserAgent = regexp.MustCompile(`^[A-Za-z0-9._+/-]+$`)
+
+var deprecatedCalls = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "grpc_deprecated_calls_total",
+		Help: "Total number of calls of the deprecated methods by product of the user agent of the callers.",
+	},
+	[]string{"grpc_service", "grpc_method", "user_agent"},
+)
+
+// Sunsets maps the full method names, or the service prefixes ending with a slash (e.g. "/template.v1.Echo/"),
+// to the sunset dates of the deprecated methods. The exact method names have precedence over the services.
+type Sunsets map[string]time.Time
+
+// ParseSunsets parses the sunset dates in the `<full method or service prefix>=<YYYY-MM-DD>` format,
+// e.g. "/template.v1.Echo/=2027-06-30".
+func ParseSunsets(values []string) (Sunsets, error) {
+	sunsets := make(Sunsets, len(values))
+	for _, v := range values {
+		i := strings.LastIndex(v, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid sunset %q, expected <full method>=<YYYY-MM-DD>", v)
+		}
+
+		date, err := time.Parse(dateLayout, v[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid date of sunset %q, expected <full method>=<YYYY-MM-DD>", v)
+		}
+		sunsets[v[:i]] = date
+	}
+
+	return sunsets, nil
+}
+
+// Sunset returns the sunset date of the method, false if it's not deprecated.
+func (s Sunsets) Sunset(fullMethod string) (time.Time, bool) {
+	if date, ok := s[fullMethod]; ok {
+		return date, true
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		date, ok := s[fullMethod[:i+1]]
+
+		return date, ok
+	}
+
+	return time.Time{}, false
+}
+
+// UnaryServerInterceptor returns a unary interceptor that sets the deprecation headers of the deprecated methods,
+// and counts their calls by the products of the user agents, e.g. "my-app", the other products are counted as "other".
+func (s Sunsets) UnaryServerInterceptor(products ...string) grpc.UnaryServerInterceptor {
+	known := toSet(products)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if date, ok := s.Sunset(info.FullMethod); ok {
+			// The headers are sent with the response, the error is only returned if they were already sent
+			_ = grpc.SetHeader(ctx, header(date))
+			countCall(ctx, info.FullMethod, known)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that sets the deprecation headers of the deprecated methods,
+// and counts their calls by the products of the user agents like the UnaryServerInterceptor.
+func (s Sunsets) StreamServerInterceptor(products ...string) grpc.StreamServerInterceptor {
+	known := toSet(products)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if date, ok := s.Sunset(info.FullMethod); ok {
+			_ = ss.SetHeader(header(date))
+			countCall(ss.Context(), info.FullMethod, known)
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func header(sunset time.Time) metadata.MD {
+	return metadata.Pairs(
+		MetaKeyDeprecation, "true",
+		MetaKeySunset, sunset.UTC().Format(http.TimeFormat),
+	)
+}
+
+func countCall(ctx context.Context, fullMethod string, products map[string]bool) {
+	service, method := protoutil.SplitMethodName(fullMethod)
+	deprecatedCalls.With(prometheus.Labels{
+		"grpc_service": strings.ToLower(service),
+		"grpc_method":  strings.ToLower(method),
+		"user_agent":   userAgent(ctx, products),
+	}).Inc()
+}
+
+// userAgent returns the product of the first token of the user agent if it's listed, e.g. "my-app" of
+// "my-app/1.2.3 grpc-go/1.35.0", "other" if it's not listed, so the cardinality of the metric is bounded.
+// The versions are dropped, they're chosen by the callers.
+func userAgent(ctx context.Context, products map[string]bool) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("user-agent")
+	if len(values) == 0 {
+		return unknownUserAgent
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) == 0 || !validUserAgent.MatchString(fields[0]) {
+		return unknownUserAgent
+	}
+
+	product := strings.SplitN(fields[0], "/", 2)[0]
+	if !products[product] {
+		return otherUserAgent
+	}
+
+	return product
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+
+	return set
+}
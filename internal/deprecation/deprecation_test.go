@@ -0,0 +1,106 @@
+package deprecation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/sliide/template-grpc-service/internal/metrictest"
+)
+
+func TestParseSunsets(t *testing.T) {
+	sunsets, err := ParseSunsets([]string{"/template.v1.Echo/=2027-06-30", "/template.v2.Echo/UnaryEcho=2028-01-01"})
+	require.NoError(t, err)
+	assert.Equal(t, Sunsets{
+		"/template.v1.Echo/":          time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC),
+		"/template.v2.Echo/UnaryEcho": time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, sunsets)
+
+	for _, v := range []string{"/template.v1.Echo/", "=2027-06-30", "/template.v1.Echo/=30/06/2027"} {
+		_, err := ParseSunsets([]string{v})
+		assert.Error(t, err, v)
+	}
+}
+
+func TestSunsetsSunset(t *testing.T) {
+	service := time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+	method := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunsets := Sunsets{
+		"/template.v1.Echo/":          service,
+		"/template.v1.Echo/UnaryEcho": method,
+	}
+
+	tests := []struct {
+		name       string
+		fullMethod string
+		expected   time.Time
+		ok         bool
+	}{
+		{name: "Method", fullMethod: "/template.v1.Echo/UnaryEcho", expected: method, ok: true},
+		{name: "Service", fullMethod: "/template.v1.Echo/ServerStreamingEcho", expected: service, ok: true},
+		{name: "Not deprecated", fullMethod: "/template.v2.Echo/UnaryEcho"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, ok := sunsets.Sunset(tt.fullMethod)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, date)
+		})
+	}
+
+	var empty Sunsets
+	_, ok := empty.Sunset("/template.v1.Echo/UnaryEcho")
+	assert.False(t, ok)
+}
+
+func TestUserAgent(t *testing.T) {
+	products := toSet([]string{"my-app", "grpc-go"})
+
+	tests := []struct {
+		name      string
+		userAgent []string
+		expected  string
+	}{
+		{name: "Product", userAgent: []string{"my-app/1.2.3 grpc-go/1.35.0"}, expected: "my-app"},
+		{name: "gRPC only", userAgent: []string{"grpc-go/1.35.0"}, expected: "grpc-go"},
+		{name: "Not listed", userAgent: []string{"other-app/1.0 grpc-go/1.35.0"}, expected: otherUserAgent},
+		{name: "Random", userAgent: []string{"a1b2c3d4/9.9"}, expected: otherUserAgent},
+		{name: "Missing", expected: unknownUserAgent},
+		{name: "Invalid", userAgent: []string{"my\"app"}, expected: unknownUserAgent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := metadata.MD{}
+			if tt.userAgent != nil {
+				md.Set("user-agent", tt.userAgent...)
+			}
+			assert.Equal(t, tt.expected, userAgent(metadata.NewIncomingContext(context.Background(), md), products))
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	sunsets := Sunsets{"/test.v1.Service/": time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-agent", "test-app/1.0 grpc-go/1.35.0"))
+	calls := deprecatedCalls.WithLabelValues("test.v1.service", "get", "test-app")
+	before := metrictest.CounterValue(t, calls)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+	resp, err := sunsets.UnaryServerInterceptor("test-app")(ctx, "req", &grpc.UnaryServerInfo{FullMethod: "/test.v1.Service/Get"}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "req", resp)
+	assert.Equal(t, before+1, metrictest.CounterValue(t, calls), "Must count the deprecated calls by user agent")
+
+	_, err = sunsets.UnaryServerInterceptor("test-app")(ctx, "req", &grpc.UnaryServerInfo{FullMethod: "/test.v2.Service/Get"}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, before+1, metrictest.CounterValue(t, calls), "Must not count the other methods")
+}
@@ -0,0 +1,49 @@
+// Package filewatch polls the files for modifications, e.g. to reload a config without a restart.
+package filewatch
+
+import (
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Poll calls the reload function when the file is modified, checking it every interval until the stop channel
+// is closed. The reload errors are logged, the caller should keep the previous version of the file then.
+func Poll(path string, interval time.Duration, stop <-chan struct{}, reload func() error) {
+	var modTime time.Time
+	var size int64
+	if fi, err := os.Stat(path); err == nil {
+		modTime, size = fi.ModTime(), fi.Size()
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	l := logrus.WithField("path", path)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		}
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			l.WithError(err).Warn("Failed to check the file")
+
+			continue
+		}
+		if fi.ModTime().Equal(modTime) && fi.Size() == size {
+			continue
+		}
+		modTime, size = fi.ModTime(), fi.Size()
+
+		if err := reload(); err != nil {
+			l.WithError(err).Error("Failed to reload the file, keeping the previous version")
+
+			continue
+		}
+		l.Info("File reloaded")
+	}
+}
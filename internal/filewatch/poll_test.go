@@ -0,0 +1,46 @@
+package filewatch
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("v1"), 0o600))
+
+	var reloads int32
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Poll(path, time.Millisecond, stop, func() error {
+			atomic.AddInt32(&reloads, 1)
+
+			return errors.New("keep the previous version")
+		})
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&reloads), "Must not reload the unmodified file")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("v2 longer"), 0o600))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&reloads) == 1
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, os.Remove(path))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reloads), "Must not reload the removed file")
+
+	close(stop)
+	<-done
+}
@@ -0,0 +1,147 @@
+// Package geo looks up the geo locations of the client IPs in a local MaxMind database,
+// and enforces the country rules of the methods.
+package geo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/sliide/shared-go-libs/geoip"
+	"github.com/sliide/template-grpc-service/internal/filewatch"
+)
+
+// englishName is the language of the names of the locations.
+const englishName = "en"
+
+var (
+	// ErrNotFound is returned when the IP is not in the database.
+	ErrNotFound = errors.New("IP not found in the geoip database")
+
+	// errNoDatabase is returned when the database is not loaded.
+	errNoDatabase = errors.New("geoip database not loaded")
+)
+
+// DB looks up the locations in a GeoIP2 or GeoLite2 City (or Country) database file, it implements geoip.DB.
+// The file can be replaced while serving, see Watch.
+type DB struct {
+	reader atomic.Value // *maxminddb.Reader
+}
+
+var _ geoip.DB = (*DB)(nil)
+
+// Open returns a new DB of the database file.
+func Open(path string) (*DB, error) {
+	db := &DB{}
+	if err := db.Load(path); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Load replaces the database by the file, the previous one is kept if the file cannot be read.
+// The database is read in memory, so the file can be replaced while the previous one is in use.
+func (db *DB) Load(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load the geoip database: %w", err)
+	}
+	r, err := maxminddb.FromBytes(b)
+	if err != nil {
+		return fmt.Errorf("failed to load the geoip database: %w", err)
+	}
+	db.reader.Store(r)
+
+	return nil
+}
+
+// Watch reloads the database file when modified, checking it every interval until the stop channel is closed.
+func (db *DB) Watch(path string, interval time.Duration, stop <-chan struct{}) {
+	filewatch.Poll(path, interval, stop, func() error {
+		return db.Load(path)
+	})
+}
+
+// IPLookup implements geoip.DB, it returns ErrNotFound if the IP is not in the database.
+func (db *DB) IPLookup(_ context.Context, ip string) (*geoip.Location, error) {
+	if db == nil {
+		return nil, errNoDatabase
+	}
+	r, ok := db.reader.Load().(*maxminddb.Reader)
+	if !ok {
+		return nil, errNoDatabase
+	}
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil, fmt.Errorf("invalid IP: %q", ip)
+	}
+
+	offset, err := r.LookupOffset(addr)
+	if err != nil {
+		return nil, err
+	}
+	if offset == maxminddb.NotFound {
+		return nil, ErrNotFound
+	}
+	var rec record
+	if err := r.Decode(offset, &rec); err != nil {
+		return nil, err
+	}
+
+	return rec.location(), nil
+}
+
+// place is a location of a GeoIP2 record.
+type place struct {
+	IsoCode           string            `maxminddb:"iso_code"`
+	IsInEuropeanUnion bool              `maxminddb:"is_in_european_union"`
+	Names             map[string]string `maxminddb:"names"`
+}
+
+// record is the part of a GeoIP2 City or Country record of the locations, e.g.
+//
+//	{"city": {"names": {"en": "London"}},
+//	 "country": {"iso_code": "GB", "names": {"en": "United Kingdom"}},
+//	 "subdivisions": [{"iso_code": "ENG", "names": {"en": "England"}}]}
+type record struct {
+	City              place   `maxminddb:"city"`
+	Country           place   `maxminddb:"country"`
+	RegisteredCountry place   `maxminddb:"registered_country"`
+	Subdivisions      []place `maxminddb:"subdivisions"`
+}
+
+// location returns the location of the record.
+func (r *record) location() *geoip.Location {
+	country := r.Country
+	if country.IsoCode == "" {
+		// The anonymous proxies and satellite providers only have a registered country
+		country = r.RegisteredCountry
+	}
+
+	l := &geoip.Location{
+		City: geoip.City{
+			Name: r.City.Names[englishName],
+		},
+		Country: geoip.Country{
+			IsoCode:           country.IsoCode,
+			Name:              country.Names[englishName],
+			IsInEuropeanUnion: country.IsInEuropeanUnion,
+		},
+	}
+	for _, s := range r.Subdivisions {
+		l.Subdivisions = append(l.Subdivisions, geoip.Subdivision{
+			IsoCode: s.IsoCode,
+			Name:    s.Names[englishName],
+		})
+	}
+
+	return l
+}
@@ -0,0 +1,86 @@
+package geo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sliide/shared-go-libs/geoip"
+)
+
+// The test databases are City databases of the networks and their countries:
+// testdata/city.mmdb has 192.0.2.0/24 in GB and 2001:db8::/32 in DE, testdata/city-us.mmdb has 192.0.2.0/24 in US.
+
+// copyTestDB copies the test database into the file.
+func copyTestDB(t *testing.T, name, path string) {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, b, 0o600))
+}
+
+func TestDBIPLookup(t *testing.T) {
+	db, err := Open(filepath.Join("testdata", "city.mmdb"))
+	require.NoError(t, err)
+
+	l, err := db.IPLookup(context.Background(), "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, &geoip.Location{
+		City: geoip.City{Name: "City GB"},
+		Country: geoip.Country{
+			IsoCode: "GB",
+			Name:    "Country GB",
+		},
+		Subdivisions: []geoip.Subdivision{{IsoCode: "S1", Name: "Subdivision GB"}},
+	}, l)
+
+	l, err = db.IPLookup(context.Background(), "2001:db8::1")
+	require.NoError(t, err)
+	assert.Equal(t, "DE", l.Country.IsoCode)
+	assert.True(t, l.Country.IsInEuropeanUnion)
+
+	_, err = db.IPLookup(context.Background(), "198.51.100.1")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = db.IPLookup(context.Background(), "invalid")
+	assert.Error(t, err)
+
+	_, err = (*DB)(nil).IPLookup(context.Background(), "192.0.2.1")
+	assert.Error(t, err, "Must be nil safe")
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.mmdb"))
+	assert.Error(t, err)
+}
+
+func TestDBWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	copyTestDB(t, "city.mmdb", path)
+
+	db, err := Open(path)
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go db.Watch(path, time.Millisecond, stop)
+
+	// Keep the previous database if invalid
+	require.NoError(t, ioutil.WriteFile(path, []byte("invalid"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	time.Sleep(20 * time.Millisecond)
+	l, err := db.IPLookup(context.Background(), "192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "GB", l.Country.IsoCode)
+
+	copyTestDB(t, "city-us.mmdb", path)
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+	assert.Eventually(t, func() bool {
+		l, err := db.IPLookup(context.Background(), "192.0.2.1")
+
+		return err == nil && l.Country.IsoCode == "US"
+	}, time.Second, time.Millisecond)
+}
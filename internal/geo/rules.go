@@ -0,0 +1,223 @@
+package geo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+
+	"github.com/sliide/shared-go-libs/geoip"
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+// unknownCountry is the country label of the calls whose country is not found.
+const unknownCountry = "unknown"
+
+var (
+	requestsByCountry = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_server_handled_by_country_total",
+			Help: "Total number of RPCs completed on the server by country of the client IP.",
+		},
+		[]string{"grpc_service", "grpc_method", "grpc_code", "country"},
+	)
+
+	blockedCalls = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_country_blocked_total",
+			Help: "Total number of calls blocked by the country rules.",
+		},
+		[]string{"grpc_service", "grpc_method", "country"},
+	)
+)
+
+// Rules represents the allowed and denied countries of a method by their ISO 3166-1 codes, e.g. "GB".
+type Rules struct {
+	// Allow only allows the countries in the list if not empty, the unknown countries are blocked then.
+	Allow []string `yaml:"allow"`
+	// Deny blocks the countries in the list.
+	Deny []string `yaml:"deny"`
+}
+
+// allowed returns true if the country is not denied, and allowed if there is an allow list.
+func (r Rules) allowed(country string) bool {
+	for _, c := range r.Deny {
+		if strings.EqualFold(c, country) {
+			return false
+		}
+	}
+	if len(r.Allow) == 0 {
+		return true
+	}
+	for _, c := range r.Allow {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CountryRules maps the full method names to their country rules, the calls of the other methods are allowed.
+type CountryRules map[string]Rules
+
+// LoadRulesFile loads the country rules from a YAML (or JSON) file in the following format.
+//
+//	/template.v2.Echo/UnaryEcho:
+//	  allow: [GB, US]
+//	  deny: [KP]
+func LoadRulesFile(path string) (CountryRules, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read country rules: %w", err)
+	}
+
+	rules := CountryRules{}
+
+	// The unknown keys are rejected, a misspelled allow list would silently allow every country
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rules); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse country rules: %w", err)
+	}
+
+	return rules, rules.Check()
+}
+
+// Check returns an error if a method isn't registered, or a country isn't an ISO 3166-1 alpha-2 code,
+// so a typo in the rules fails instead of disabling them silently.
+func (r CountryRules) Check() error {
+	methods := make([]string, 0, len(r))
+	for method := range r {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		if _, err := protoutil.MethodDescriptor(method); err != nil {
+			return fmt.Errorf("invalid country rules of %s: %w", method, err)
+		}
+		for _, c := range append(r[method].Allow, r[method].Deny...) {
+			if !isISOCountry(c) {
+				return fmt.Errorf("invalid country rules of %s: %q is not an ISO 3166-1 alpha-2 code", method, c)
+			}
+		}
+	}
+
+	return nil
+}
+
+// check returns a PermissionDenied error if the country of the client IP is not allowed to call the method.
+func (r CountryRules) check(ctx context.Context, fullMethod string) error {
+	rules, ok := r[fullMethod]
+	if !ok {
+		return nil
+	}
+
+	country := country(ctx)
+	if rules.allowed(country) {
+		return nil
+	}
+
+	service, method := protoutil.SplitMethodName(fullMethod)
+	blockedCalls.With(prometheus.Labels{
+		"grpc_service": strings.ToLower(service),
+		"grpc_method":  strings.ToLower(method),
+		"country":      country,
+	}).Inc()
+
+	return grpcerr.New(grpcerr.ErrPermissionDenied, "COUNTRY_BLOCKED", "Country is not allowed")
+}
+
+// UnaryServerInterceptor returns a unary interceptor that blocks the calls from the countries which are not allowed.
+//
+// NOTE: Must be chained after the GeoIPLookup interceptor.
+func (r CountryRules) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := r.check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that blocks the streams from the countries which are not allowed.
+//
+// NOTE: Must be chained after the LookupStreamServerInterceptor.
+func (r CountryRules) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := r.check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// LookupStreamServerInterceptor returns a stream interceptor that looks up the location of the client IP,
+// and sets it into the stream context like the GeoIPLookup unary interceptor.
+//
+// NOTE: Must be chained after the stream Entry interceptor, which resolves the client IP.
+func LookupStreamServerInterceptor(db geoip.DB) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		ip := coremiddleware.RequestContext(ctx).RemoteAddr()
+
+		result := coremiddleware.GeoIPResult{RemoteAddr: ip}
+		if db == nil {
+			result.Error = errNoDatabase
+		} else if v, err := db.IPLookup(ctx, ip); err != nil {
+			result.Error = err
+		} else {
+			result.Location = *v
+		}
+
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = coremiddleware.NewContextWithGeoIP(ctx, result)
+
+		return handler(srv, wrapped)
+	}
+}
+
+// MetricsUnaryServerInterceptor returns a unary interceptor that counts the completed calls by country.
+//
+// NOTE: Must be chained after the GeoIPLookup interceptor.
+func MetricsUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+
+		service, method := protoutil.SplitMethodName(info.FullMethod)
+		requestsByCountry.With(prometheus.Labels{
+			"grpc_service": strings.ToLower(service),
+			"grpc_method":  strings.ToLower(method),
+			"grpc_code":    strings.ToLower(status.Code(err).String()),
+			"country":      country(ctx),
+		}).Inc()
+
+		return resp, err
+	}
+}
+
+// country returns the ISO code of the country of the client IP, unknownCountry if not found.
+func country(ctx context.Context) string {
+	v := coremiddleware.GeoIP(ctx)
+	if v.Error != nil || v.Country.IsoCode == "" {
+		return unknownCountry
+	}
+
+	return strings.ToUpper(v.Country.IsoCode)
+}
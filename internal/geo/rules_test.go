@@ -0,0 +1,237 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sliide/shared-go-libs/geoip"
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	_ "github.com/sliide/template-grpc-service/api/template/v1"
+	_ "github.com/sliide/template-grpc-service/api/template/v2"
+	"github.com/sliide/template-grpc-service/internal/metrictest"
+)
+
+func TestLoadRulesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "countries.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+/template.v2.Echo/UnaryEcho:
+  allow: [GB, us]
+/template.v1.Echo/UnaryEcho:
+  deny: [KP]
+`), 0o600))
+
+	rules, err := LoadRulesFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, CountryRules{
+		"/template.v2.Echo/UnaryEcho": {Allow: []string{"GB", "us"}},
+		"/template.v1.Echo/UnaryEcho": {Deny: []string{"KP"}},
+	}, rules)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "Invalid", content: "- invalid"},
+		{name: "Unknown key", content: "/template.v2.Echo/UnaryEcho:\n  alow: [GB]"},
+		{name: "Unknown method", content: "/template.v2.Echo/UnaryEko:\n  allow: [GB]"},
+		{name: "Unknown country", content: "/template.v2.Echo/UnaryEcho:\n  deny: [UK]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, ioutil.WriteFile(path, []byte(tt.content), 0o600))
+			_, err := LoadRulesFile(path)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err = LoadRulesFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestCountryRulesUnaryServerInterceptor(t *testing.T) {
+	rules := CountryRules{
+		"/test.Service/Get":    {Allow: []string{"GB", "us"}},
+		"/test.Service/Delete": {Deny: []string{"KP"}},
+	}
+
+	tests := []struct {
+		name       string
+		fullMethod string
+		geoIP      *coremiddleware.GeoIPResult
+		expected   codes.Code
+	}{
+		{
+			name:       "allowed country",
+			fullMethod: "/test.Service/Get",
+			geoIP:      &coremiddleware.GeoIPResult{Location: geoip.Location{Country: geoip.Country{IsoCode: "GB"}}},
+			expected:   codes.OK,
+		},
+		{
+			name:       "allowed country case insensitive",
+			fullMethod: "/test.Service/Get",
+			geoIP:      &coremiddleware.GeoIPResult{Location: geoip.Location{Country: geoip.Country{IsoCode: "US"}}},
+			expected:   codes.OK,
+		},
+		{
+			name:       "not allowed country",
+			fullMethod: "/test.Service/Get",
+			geoIP:      &coremiddleware.GeoIPResult{Location: geoip.Location{Country: geoip.Country{IsoCode: "DE"}}},
+			expected:   codes.PermissionDenied,
+		},
+		{
+			name:       "unknown country with allow list",
+			fullMethod: "/test.Service/Get",
+			geoIP:      &coremiddleware.GeoIPResult{Error: errors.New("not found")},
+			expected:   codes.PermissionDenied,
+		},
+		{
+			name:       "denied country",
+			fullMethod: "/test.Service/Delete",
+			geoIP:      &coremiddleware.GeoIPResult{Location: geoip.Location{Country: geoip.Country{IsoCode: "KP"}}},
+			expected:   codes.PermissionDenied,
+		},
+		{
+			name:       "unknown country with deny list",
+			fullMethod: "/test.Service/Delete",
+			expected:   codes.OK,
+		},
+		{
+			name:       "method without rules",
+			fullMethod: "/test.Service/List",
+			geoIP:      &coremiddleware.GeoIPResult{Location: geoip.Location{Country: geoip.Country{IsoCode: "KP"}}},
+			expected:   codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.geoIP != nil {
+				ctx = coremiddleware.NewContextWithGeoIP(ctx, *tt.geoIP)
+			}
+
+			called := false
+			_, err := rules.UnaryServerInterceptor()(ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: tt.fullMethod},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					called = true
+
+					return req, nil
+				})
+			assert.Equal(t, tt.expected, status.Code(err))
+			assert.Equal(t, tt.expected == codes.OK, called)
+		})
+	}
+
+	t.Run("nil rules", func(t *testing.T) {
+		_, err := CountryRules(nil).UnaryServerInterceptor()(context.Background(), struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return req, nil
+			})
+		assert.NoError(t, err)
+	})
+}
+
+func TestCountry(t *testing.T) {
+	assert.Equal(t, unknownCountry, country(context.Background()))
+	assert.Equal(t, "GB", country(coremiddleware.NewContextWithGeoIP(context.Background(), coremiddleware.GeoIPResult{
+		Location: geoip.Location{Country: geoip.Country{IsoCode: "gb"}},
+	})))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestMetricsUnaryServerInterceptor(t *testing.T) {
+	ctx := coremiddleware.NewContextWithGeoIP(context.Background(), coremiddleware.GeoIPResult{
+		Location: geoip.Location{Country: geoip.Country{IsoCode: "gb"}},
+	})
+	requests := requestsByCountry.WithLabelValues("test.service", "get", "notfound", "GB")
+	before := metrictest.CounterValue(t, requests)
+
+	_, err := MetricsUnaryServerInterceptor()(ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "not found")
+		})
+	require.Error(t, err)
+
+	assert.Equal(t, before+1, metrictest.CounterValue(t, requests), "Must count with the lower-cased code")
+}
+
+func TestCountryRulesStreamServerInterceptor(t *testing.T) {
+	rules := CountryRules{"/test.Service/Watch": {Deny: []string{"KP"}}}
+
+	tests := []struct {
+		name       string
+		fullMethod string
+		country    string
+		expected   codes.Code
+	}{
+		{name: "allowed country", fullMethod: "/test.Service/Watch", country: "GB", expected: codes.OK},
+		{name: "denied country", fullMethod: "/test.Service/Watch", country: "KP", expected: codes.PermissionDenied},
+		{name: "method without rules", fullMethod: "/test.Service/List", country: "KP", expected: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := &testServerStream{ctx: coremiddleware.NewContextWithGeoIP(context.Background(), coremiddleware.GeoIPResult{
+				Location: geoip.Location{Country: geoip.Country{IsoCode: tt.country}},
+			})}
+
+			called := false
+			err := rules.StreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: tt.fullMethod},
+				func(srv interface{}, stream grpc.ServerStream) error {
+					called = true
+
+					return nil
+				})
+			assert.Equal(t, tt.expected, status.Code(err))
+			assert.Equal(t, tt.expected == codes.OK, called)
+		})
+	}
+}
+
+func TestLookupStreamServerInterceptor(t *testing.T) {
+	db, err := Open(filepath.Join("testdata", "city.mmdb"))
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-real-ip", "192.0.2.1"))
+	ctx = coremiddleware.NewContextWithRequestCtx(ctx, coremiddleware.BuildRequestContext(ctx, coremiddleware.EntryConfigs{}))
+
+	lookup := func(db geoip.DB) coremiddleware.GeoIPResult {
+		var actual coremiddleware.GeoIPResult
+		err := LookupStreamServerInterceptor(db)(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"},
+			func(srv interface{}, stream grpc.ServerStream) error {
+				actual = coremiddleware.GeoIP(stream.Context())
+
+				return nil
+			})
+		require.NoError(t, err)
+
+		return actual
+	}
+
+	actual := lookup(db)
+	assert.NoError(t, actual.Error)
+	assert.Equal(t, "192.0.2.1", actual.RemoteAddr)
+	assert.Equal(t, "GB", actual.Country.IsoCode)
+
+	actual = lookup(nil)
+	assert.Error(t, actual.Error)
+	assert.Equal(t, "192.0.2.1", actual.RemoteAddr)
+}
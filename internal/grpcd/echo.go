@@ -2,20 +2,42 @@ package grpcd
 
 import (
 	"context"
+	"strings"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/examples/features/proto/echo"
-	"google.golang.org/grpc/status"
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+	"github.com/sliide/template-grpc-service/internal/validation"
 )
 
-func (s templateService) UnaryEcho(_ context.Context, r *echo.EchoRequest) (*echo.EchoResponse, error) {
-	const MaxMessageLength = 500
+// maxMessageLength is the length limit (exclusive) in bytes of the echo messages.
+const maxMessageLength = 500
 
-	if len(r.GetMessage()) >= MaxMessageLength {
-		return nil, status.Error(codes.InvalidArgument, "Message is too long")
+// echoValidationRules returns the validation rules of the Echo service.
+func echoValidationRules() validation.MethodRules {
+	messageRules := []validation.FieldRules{
+		validation.Field("message", validation.Length{Max: maxMessageLength - 1}, validation.UTF8{}),
 	}
 
-	return &echo.EchoResponse{
-		Message: r.GetMessage(),
+	rules := validation.MethodRules{}
+	for _, service := range []string{echoV1ServiceName, echoServiceName} {
+		for _, method := range []string{"UnaryEcho", "ServerStreamingEcho", "ClientStreamingEcho", "BidirectionalStreamingEcho"} {
+			rules["/"+service+"/"+method] = messageRules
+		}
+	}
+
+	return rules
+}
+
+// echo returns the message to echo back.
+func echo(message string, upperCase bool) string {
+	if upperCase {
+		return strings.ToUpper(message)
+	}
+
+	return message
+}
+
+func (s templateService) UnaryEcho(_ context.Context, r *templatev2.UnaryEchoRequest) (*templatev2.UnaryEchoResponse, error) {
+	return &templatev2.UnaryEchoResponse{
+		Message: echo(r.GetMessage(), r.GetUpperCase()),
 	}, nil
 }
@@ -1,21 +1,32 @@
-package grpcd
+package grpcd_test
 
 import (
-	"bytes"
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/examples/features/proto/echo"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+	"github.com/sliide/template-grpc-service/internal/capture"
+	"github.com/sliide/template-grpc-service/internal/deprecation"
+	"github.com/sliide/template-grpc-service/internal/grpcd"
+	"github.com/sliide/template-grpc-service/internal/grpcdtest"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
 )
 
 func TestUnaryEcho(t *testing.T) {
+	client := templatev2.NewEchoClient(grpcdtest.Start(t).Conn)
+
 	t.Run("OK", func(t *testing.T) {
-		s := templateService{}
-		resp, err := s.UnaryEcho(context.Background(), &echo.EchoRequest{
+		resp, err := client.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{
 			Message: "this-is-test-message",
 		})
 
@@ -23,18 +34,82 @@ func TestUnaryEcho(t *testing.T) {
 		assert.Equal(t, "this-is-test-message", resp.GetMessage())
 	})
 
+	t.Run("Upper case", func(t *testing.T) {
+		resp, err := client.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{
+			Message:   "this-is-test-message",
+			UpperCase: true,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "THIS-IS-TEST-MESSAGE", resp.GetMessage())
+	})
+
 	t.Run("Too long", func(t *testing.T) {
-		b := bytes.NewBufferString("")
-		for i := 0; i < 500; i++ {
-			b.WriteString("a")
-		}
-
-		s := templateService{}
-		_, err := s.UnaryEcho(context.Background(), &echo.EchoRequest{
-			Message: b.String(),
+		// The limit is enforced by the validation interceptor of the server
+		_, err := client.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{
+			Message: strings.Repeat("a", 500),
 		})
 
-		require.Error(t, err)
-		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		grpcdtest.RequireErrorInfo(t, err, codes.InvalidArgument, "INVALID_ARGUMENT")
+		grpcdtest.AssertDetail(t, err, grpcerr.BadRequest{
+			FieldViolations: []grpcerr.FieldViolation{
+				{Field: "message", Description: "must be at most 499 bytes long"},
+			},
+		})
+	})
+}
+
+func TestUnaryEchoGolden(t *testing.T) {
+	records, err := capture.ReadFile("testdata/echo_capture.jsonl")
+	require.NoError(t, err)
+
+	conn := grpcdtest.Start(t).Conn
+	for _, res := range capture.ReplayAll(context.Background(), conn, records) {
+		assert.True(t, res.Matched(), res.String())
+	}
+}
+
+func TestStreamingEchoUnimplemented(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn := grpcdtest.Start(t).Conn
+
+	t.Run("v2", func(t *testing.T) {
+		stream, err := templatev2.NewEchoClient(conn).ServerStreamingEcho(ctx, &templatev2.ServerStreamingEchoRequest{Message: "hello"})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		stream, err := templatev1.NewEchoClient(conn).BidirectionalStreamingEcho(ctx)
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
+
+func TestEchoV1(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sunset := time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+	client := templatev1.NewEchoClient(grpcdtest.Start(t, grpcd.SetSunsets(deprecation.Sunsets{"/template.v1.Echo/": sunset})).Conn)
+
+	t.Run("Unary", func(t *testing.T) {
+		var header metadata.MD
+		resp, err := client.UnaryEcho(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", resp.GetMessage())
+		assert.Equal(t, []string{"true"}, header.Get(deprecation.MetaKeyDeprecation))
+		assert.Equal(t, []string{"Wed, 30 Jun 2027 00:00:00 GMT"}, header.Get(deprecation.MetaKeySunset))
+	})
+
+	t.Run("Not deprecated", func(t *testing.T) {
+		var header metadata.MD
+		_, err := templatev2.NewEchoClient(grpcdtest.Start(t).Conn).UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: "hello"}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Empty(t, header.Get(deprecation.MetaKeySunset))
 	})
 }
@@ -0,0 +1,24 @@
+package grpcd
+
+import (
+	"context"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+)
+
+// echoV1 adapts the deprecated v1 Echo service to the v2 implementation, converting the messages between versions.
+type echoV1 struct {
+	templatev1.UnimplementedEchoServer
+
+	service templatev2.EchoServer
+}
+
+func (s echoV1) UnaryEcho(ctx context.Context, r *templatev1.UnaryEchoRequest) (*templatev1.UnaryEchoResponse, error) {
+	resp, err := s.service.UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: r.GetMessage()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &templatev1.UnaryEchoResponse{Message: resp.GetMessage()}, nil
+}
@@ -5,9 +5,10 @@
 package grpcd
 
 import (
-	"google.golang.org/grpc/examples/features/proto/echo"
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
 )
 
+// templateService implements the latest version of the services, the previous versions are adapted to it.
 type templateService struct {
-	echo.UnimplementedEchoServer
+	templatev2.UnimplementedEchoServer
 }
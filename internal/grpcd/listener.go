@@ -0,0 +1,229 @@
+package grpcd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/identity"
+)
+
+const (
+	// defaultListenerName is the name of the listener used when no listener is added.
+	defaultListenerName = "default"
+
+	// Full names of the services which can be registered on the listeners.
+	echoServiceName       = "template.v2.Echo"
+	echoV1ServiceName     = "template.v1.Echo"
+	reflectionServiceName = "grpc.reflection.v1alpha.ServerReflection"
+)
+
+var (
+	// errAuthenticatorRequired is returned when a listener requires auth without an authenticator.
+	errAuthenticatorRequired = errors.New("authenticator is required for the listeners requiring auth")
+
+	// errDuplicateListener is returned when several listeners have the same name.
+	errDuplicateListener = errors.New("duplicate listener name")
+
+	// errTrustedProxiesRequired is returned when a listener requires the PROXY protocol without trusted proxies.
+	errTrustedProxiesRequired = errors.New("trusted proxies are required for the PROXY protocol")
+
+	// errUnknownService is returned when a listener registers a service not served by the Server.
+	errUnknownService = errors.New("unknown service")
+)
+
+// Listener represents a named listener of the Server, with its own address, TLS, entry configs,
+// auth requirement and registered services, e.g. a hardened public port and a trusted internal port.
+type Listener struct {
+	// Name identifies the listener in the logs, e.g. "public" or "internal".
+	Name string
+	Addr string
+
+	// TLS is the TLS config of the listener, the listener is plaintext if nil.
+	TLS *tls.Config
+
+	// Entry configures the request context of the calls,
+	// AllowTraceIDFromRequest must be false for the public listeners.
+	Entry coremiddleware.EntryConfigs
+
+	// TrustedProxies are the networks of the proxies whose forwarded-for metadata is trusted to resolve the client IP,
+	// see clientip.Resolver. The forwarded-for metadata is ignored if empty.
+	TrustedProxies []*net.IPNet
+
+	// ProxyProtocol requires the PROXY protocol header on the connections, e.g. behind an AWS NLB,
+	// which is only accepted from the TrustedProxies, required then.
+	ProxyProtocol bool
+
+	// RequireAuth rejects the calls which are not authenticated by the authenticator of the Server.
+	RequireAuth bool
+
+	// Services are the full names of the services registered on the listener, e.g. "template.v2.Echo",
+	// all services are registered if empty.
+	Services []string
+}
+
+// Authenticator authenticates the caller of a call, returns the identity of the caller and its claims if any,
+// or an error if the credentials are missing or invalid.
+type Authenticator func(ctx context.Context) (string, identity.Claims, error)
+
+// DefaultListener returns the listener used when no listener is added,
+// which trusts the trace IDs from the requests and registers all services.
+func DefaultListener(listenAddr string) Listener {
+	return Listener{
+		Name: defaultListenerName,
+		Addr: listenAddr,
+		Entry: coremiddleware.EntryConfigs{
+			AllowTraceIDFromRequest: true,
+			ReturnRequestIDInHeader: false,
+		},
+	}
+}
+
+// serviceRegistrars returns the functions registering the services of the Server by their full names.
+func serviceRegistrars(service *templateService) map[string]func(*grpc.Server) {
+	return map[string]func(*grpc.Server){
+		echoServiceName: func(s *grpc.Server) {
+			templatev2.RegisterEchoServer(s, service)
+		},
+		echoV1ServiceName: func(s *grpc.Server) {
+			templatev1.RegisterEchoServer(s, echoV1{service: service})
+		},
+		// The reflection service lists the services registered on the same listener only
+		reflectionServiceName: func(s *grpc.Server) {
+			reflection.Register(s)
+		},
+	}
+}
+
+// validateListeners returns an error if the listeners cannot be served with the configs.
+func validateListeners(cfg ServerConfigs, registrars map[string]func(*grpc.Server)) error {
+	names := make(map[string]bool, len(cfg.listeners))
+	for _, l := range cfg.listeners {
+		if names[l.Name] {
+			return fmt.Errorf("%w: %s", errDuplicateListener, l.Name)
+		}
+		names[l.Name] = true
+
+		if l.RequireAuth && cfg.authenticator == nil {
+			return fmt.Errorf("%w: %s", errAuthenticatorRequired, l.Name)
+		}
+
+		if l.ProxyProtocol && len(l.TrustedProxies) == 0 {
+			return fmt.Errorf("%w: %s", errTrustedProxiesRequired, l.Name)
+		}
+
+		for _, name := range l.Services {
+			if _, ok := registrars[name]; !ok {
+				return fmt.Errorf("%w: %s on the %s listener", errUnknownService, name, l.Name)
+			}
+		}
+	}
+
+	listeners := make([]string, 0, len(cfg.listeners))
+	for _, l := range cfg.listeners {
+		listeners = append(listeners, l.Name)
+	}
+
+	return cfg.ipFilter.Check(listeners...)
+}
+
+// registerServices registers the services of the listener on the gRPC server.
+func registerServices(s *grpc.Server, l Listener, registrars map[string]func(*grpc.Server)) {
+	if len(l.Services) == 0 {
+		for _, register := range registrars {
+			register(s)
+		}
+
+		return
+	}
+
+	for _, name := range l.Services {
+		registrars[name](s)
+	}
+}
+
+// authUnaryInterceptor returns a unary interceptor that sets the identity of the authenticated callers,
+// the calls are not authenticated if the listener doesn't require auth.
+func authUnaryInterceptor(authenticator Authenticator, required bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !required {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, authenticator)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor returns a stream interceptor that sets the identity of the authenticated callers,
+// the streams are not authenticated if the listener doesn't require auth.
+func authStreamInterceptor(authenticator Authenticator, required bool) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !required {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authenticate(ss.Context(), authenticator)
+		if err != nil {
+			return err
+		}
+
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
+}
+
+// authenticate returns a new context which sets the identity of the caller,
+// or an Unauthenticated error if the caller is not authenticated.
+func authenticate(ctx context.Context, authenticator Authenticator) (context.Context, error) {
+	id, claims, err := authenticator(ctx)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+
+		coremiddleware.Logger(ctx).WithError(err).Info("Failed to authenticate the caller")
+
+		return nil, grpcerr.New(grpcerr.ErrUnauthenticated, "UNAUTHENTICATED", "Authentication required")
+	}
+	if id == "" {
+		return nil, grpcerr.New(grpcerr.ErrUnauthenticated, "UNAUTHENTICATED", "Authentication required")
+	}
+
+	if claims != nil {
+		ctx = identity.NewContextWithClaims(ctx, claims)
+	}
+
+	return identity.NewContext(ctx, id), nil
+}
+
+// streamEntryInterceptor returns a stream interceptor which setups the request context once per stream,
+// like the Entry interceptor of the unary calls.
+func streamEntryInterceptor(c coremiddleware.EntryConfigs) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		ctx = coremiddleware.NewContextWithRequestCtx(ctx, coremiddleware.BuildRequestContext(ctx, c))
+
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
+}
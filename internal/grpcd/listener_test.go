@@ -0,0 +1,91 @@
+package grpcd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/sliide/template-grpc-service/internal/identity"
+	"github.com/sliide/template-grpc-service/internal/ipfilter"
+)
+
+func TestNewServerInvalidListeners(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners []Listener
+		expected  error
+	}{
+		{
+			name:      "duplicate names",
+			listeners: []Listener{{Name: "public"}, {Name: "public"}},
+			expected:  errDuplicateListener,
+		},
+		{
+			name:      "auth without authenticator",
+			listeners: []Listener{{Name: "internal", RequireAuth: true}},
+			expected:  errAuthenticatorRequired,
+		},
+		{
+			name:      "PROXY protocol without trusted proxies",
+			listeners: []Listener{{Name: "public", ProxyProtocol: true}},
+			expected:  errTrustedProxiesRequired,
+		},
+		{
+			name:      "unknown service",
+			listeners: []Listener{{Name: "public", Services: []string{"test.Unknown"}}},
+			expected:  errUnknownService,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := make([]ServerConfigsOpts, 0, len(tt.listeners))
+			for _, l := range tt.listeners {
+				opts = append(opts, AddListener(l))
+			}
+
+			_, err := NewServer(NewServerConfigs(ServerConfigParams{DB: new(gorm.DB)}, opts...))
+			assert.True(t, errors.Is(err, tt.expected), "unexpected error: %v", err)
+		})
+	}
+
+	t.Run("IP filter of unknown listener", func(t *testing.T) {
+		filter, err := ipfilter.NewFilter(ipfilter.Config{"publc": {Rules: ipfilter.Rules{Allow: []string{"10.0.0.0/8"}}}})
+		require.NoError(t, err)
+
+		_, err = NewServer(NewServerConfigs(ServerConfigParams{DB: new(gorm.DB)}, AddListener(Listener{Name: "public"}), SetIPFilter(filter)))
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Run("Authenticated", func(t *testing.T) {
+		ctx, err := authenticate(context.Background(), func(context.Context) (string, identity.Claims, error) {
+			return "service-a", identity.Claims{"tenant": "team-a"}, nil
+		})
+		require.NoError(t, err)
+
+		id, ok := identity.FromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "service-a", id)
+		assert.Equal(t, identity.Claims{"tenant": "team-a"}, identity.ClaimsFromContext(ctx))
+	})
+
+	t.Run("Status error kept", func(t *testing.T) {
+		_, err := authenticate(context.Background(), func(context.Context) (string, identity.Claims, error) {
+			return "", nil, status.Error(codes.PermissionDenied, "revoked")
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Empty identity", func(t *testing.T) {
+		_, err := authenticate(context.Background(), func(context.Context) (string, identity.Claims, error) { return "", nil, nil })
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
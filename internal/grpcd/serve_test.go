@@ -0,0 +1,82 @@
+package grpcd_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+	"github.com/sliide/template-grpc-service/internal/grpcd"
+	"github.com/sliide/template-grpc-service/internal/grpcdtest"
+	"github.com/sliide/template-grpc-service/internal/identity"
+)
+
+func TestServerListeners(t *testing.T) {
+	authenticator := func(ctx context.Context) (string, identity.Claims, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if v := md.Get("x-service"); len(v) > 0 {
+			return v[0], nil, nil
+		}
+
+		return "", nil, errors.New("no service")
+	}
+
+	s := grpcdtest.Start(t,
+		grpcd.AddListener(grpcd.Listener{
+			Name:     "public",
+			Services: []string{"template.v2.Echo"},
+		}),
+		grpcd.AddListener(grpcd.Listener{
+			Name:        "internal",
+			Entry:       coremiddleware.EntryConfigs{AllowTraceIDFromRequest: true},
+			RequireAuth: true,
+		}),
+		grpcd.SetAuthenticator(authenticator),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	t.Run("Public listener", func(t *testing.T) {
+		conn := s.Dial(t, "public")
+
+		resp, err := templatev2.NewEchoClient(conn).UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: "hello"})
+		require.NoError(t, err, "Must not require auth")
+		assert.Equal(t, "hello", resp.GetMessage())
+
+		stream, err := grpc_reflection_v1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unimplemented, status.Code(err), "Must not register the reflection service")
+	})
+
+	t.Run("Internal listener", func(t *testing.T) {
+		s.Logs.Reset()
+		client := templatev2.NewEchoClient(s.Dial(t, "internal"))
+
+		_, err := client.UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: "hello"})
+		grpcdtest.RequireErrorInfo(t, err, codes.Unauthenticated, "UNAUTHENTICATED")
+		logs := s.Logs.Find("Request completed with error")
+		require.Len(t, logs, 1)
+		assert.Equal(t, "internal", logs[0]["listener"])
+
+		authCtx := metadata.AppendToOutgoingContext(ctx, "x-service", "service-a")
+		resp, err := client.UnaryEcho(authCtx, &templatev2.UnaryEchoRequest{Message: "hello"})
+		require.NoError(t, err)
+		assert.Equal(t, "hello", resp.GetMessage())
+
+		stream, err := client.ServerStreamingEcho(ctx, &templatev2.ServerStreamingEchoRequest{Message: "hello"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), "Must authenticate the streams")
+	})
+}
@@ -1,88 +1,270 @@
 package grpcd
 
 import (
+	"errors"
+	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/examples/features/proto/echo"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // Decompresses the requests compressed by the clients
 	"google.golang.org/grpc/keepalive"
-	"google.golang.org/grpc/reflection"
+	"gorm.io/gorm"
 
 	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/audit"
+	"github.com/sliide/template-grpc-service/internal/cache"
+	"github.com/sliide/template-grpc-service/internal/clientip"
+	"github.com/sliide/template-grpc-service/internal/geo"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/idempotency"
+	"github.com/sliide/template-grpc-service/internal/payloadlog"
+	"github.com/sliide/template-grpc-service/internal/proxyproto"
+	"github.com/sliide/template-grpc-service/internal/validation"
+)
+
+// KeepaliveMinTime is the minimum time between the keepalive pings of the clients, the connections pinging more often
+// are closed.
+const KeepaliveMinTime = 20 * time.Second
+
+var (
+	// errAuditSinkRequired is returned when the audited methods are configured without a sink or a DB.
+	errAuditSinkRequired = errors.New("audit sink or DB is required for the audited methods")
+
+	// errListenersMismatch is returned when the number of net listeners doesn't match the configured listeners.
+	errListenersMismatch = errors.New("net listeners mismatch the configured listeners")
 )
 
 // NewServer returns a new template-grpc server.
 func NewServer(cfg ServerConfigs) (*Server, error) {
-	opts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(newUnaryInterceptor(cfg.logger)),
-		grpc.KeepaliveParams(
-			keepalive.ServerParameters{
-				MaxConnectionAge:      cfg.maxConnectionAge,
-				MaxConnectionAgeGrace: cfg.maxConnectionAgeGrace,
-			},
-		),
-	}
-
-	server := grpc.NewServer(opts...)
-	service := &templateService{}
-
-	echo.RegisterEchoServer(server, service)
-	reflection.Register(server)
-
-	return &Server{
-		s:   server,
-		cfg: cfg,
-	}, nil
+	if err := cfg.validationRules.Check(); err != nil {
+		return nil, err
+	}
+	if len(cfg.idempotentMethods) > 0 && cfg.idempotencyStore == nil {
+		store, err := newIdempotencyStore(cfg.db)
+		if err != nil {
+			return nil, err
+		}
+		cfg.idempotencyStore = store
+	}
+	if len(cfg.auditMethods) > 0 && cfg.auditSink == nil {
+		if cfg.db == nil {
+			return nil, errAuditSinkRequired
+		}
+
+		sink, err := audit.NewGormSink(cfg.db, cfg.auditKey)
+		if err != nil {
+			return nil, err
+		}
+		cfg.auditSink = sink
+	}
+	if len(cfg.cacheMethods) > 0 && cfg.cacheBackend == nil {
+		cfg.cacheBackend = cache.NewLRU(defaultCacheMaxEntries, 0, cache.CountEviction)
+	}
+
+	if len(cfg.listeners) == 0 {
+		cfg.listeners = []Listener{DefaultListener(cfg.listenAddr)}
+	}
+
+	registrars := serviceRegistrars(&templateService{})
+	if err := validateListeners(cfg, registrars); err != nil {
+		return nil, err
+	}
+
+	servers := make([]*listenerServer, 0, len(cfg.listeners))
+	for _, l := range cfg.listeners {
+		opts := []grpc.ServerOption{
+			grpc.UnaryInterceptor(newUnaryInterceptor(cfg, l)),
+			grpc.StreamInterceptor(newStreamInterceptor(cfg, l)),
+			grpc.KeepaliveParams(
+				keepalive.ServerParameters{
+					MaxConnectionAge:      cfg.maxConnectionAge,
+					MaxConnectionAgeGrace: cfg.maxConnectionAgeGrace,
+				},
+			),
+			// Allows the keepalive pings of the clients every 20s, see client.DefaultKeepalive
+			grpc.KeepaliveEnforcementPolicy(
+				keepalive.EnforcementPolicy{
+					MinTime:             KeepaliveMinTime,
+					PermitWithoutStream: true,
+				},
+			),
+		}
+		if l.TLS != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(l.TLS)))
+		}
+
+		server := grpc.NewServer(opts...)
+		registerServices(server, l, registrars)
+
+		servers = append(servers, &listenerServer{
+			l: l,
+			s: server,
+		})
+	}
+
+	srv := &Server{
+		servers:     servers,
+		cfg:         cfg,
+		ready:       make(chan struct{}),
+		stopSweeper: func() {},
+	}
+	if sweeper, ok := cfg.idempotencyStore.(idempotency.Sweeper); ok && len(cfg.idempotentMethods) > 0 {
+		srv.stopSweeper = idempotency.StartSweeper(sweeper, idempotency.DefaultSweepInterval)
+	}
+
+	return srv, nil
 }
 
-// newUnaryInterceptor returns a interceptor for the Server.
-func newUnaryInterceptor(l *logrus.Entry) grpc.UnaryServerInterceptor {
+// newUnaryInterceptor returns a interceptor for the listener of the Server.
+func newUnaryInterceptor(cfg ServerConfigs, l Listener) grpc.UnaryServerInterceptor {
+	logger := cfg.logger
+	if logger != nil {
+		logger = logger.WithField("listener", l.Name)
+	}
+
 	return grpcmiddleware.ChainUnaryServer(
 		coremiddleware.Recovery(),
-		coremiddleware.Logging(l),
-		coremiddleware.Entry(coremiddleware.EntryConfigs{
-			AllowTraceIDFromRequest: true,
-			ReturnRequestIDInHeader: false,
-		}),
+		coremiddleware.Logging(logger),
+		clientip.NewResolver(l.TrustedProxies).UnaryServerInterceptor(),
+		coremiddleware.Entry(l.Entry),
+		// The calls are logged before being filtered, so the blocked and the denied calls have their lines too.
+		cfg.accessLog.UnaryServerInterceptor(),
+		cfg.rpcz.UnaryServerInterceptor(),
+		// The blocked addresses are rejected before any other work, e.g. the authentication or the payload logs.
+		cfg.ipFilter.UnaryServerInterceptor(l.Name),
+		coremiddleware.GeoIPLookup(cfg.geoIPDB),
 		coremiddleware.GeoIPLogging(),
 		coremiddleware.EntryLogs(),
 		coremiddleware.Prometheus(),
+		geo.MetricsUnaryServerInterceptor(),
+		cfg.sunsets.UnaryServerInterceptor(cfg.userAgents...),
+		// The auditor is chained before the authentication, so the denied calls reach the audit trail.
+		audit.NewAuditor(cfg.auditSink, cfg.auditMethods...).UnaryServerInterceptor(),
+		authUnaryInterceptor(cfg.authenticator, l.RequireAuth),
+		cfg.inflight.UnaryServerInterceptor(),
+		payloadlog.New(cfg.payloadLog).UnaryServerInterceptor(),
+		cfg.capture.UnaryServerInterceptor(),
+		grpcerr.UnaryServerInterceptor(cfg.name),
+		cfg.countries.UnaryServerInterceptor(),
+		cfg.shadow.UnaryServerInterceptor(),
+		validation.NewValidator(cfg.validationRules).UnaryServerInterceptor(),
+
+		// The quotas are only consumed by the calls passing the filters and the validation,
+		// and the tenant must be set before the cache which keys the responses by tenant.
+		cfg.tenancy.UnaryServerInterceptor(),
+		cache.New(cache.Params{
+			Backend:      cfg.cacheBackend,
+			TTL:          cfg.cacheTTL,
+			Timeout:      defaultTimeoutRPC,
+			Methods:      cfg.cacheMethods,
+			MetadataKeys: cfg.cacheMetadataKeys,
+			Shared:       cfg.cacheShared,
+		}).UnaryServerInterceptor(),
 		coremiddleware.Timeout(defaultTimeoutRPC),
 
+		// The idempotency keys must be handled after the Timeout interceptor,
+		// because the handler keeps running in the background after a timeout.
+		idempotency.NewInterceptor(cfg.idempotencyStore, cfg.idempotencyLease, cfg.idempotencyTTL, cfg.idempotentMethods...).UnaryServerInterceptor(),
+
 		// The reason we put another Recovery here is to get a correct stack trace when caught a panic,
 		// because the Timeout interceptor handles requests in different coroutines.
 		coremiddleware.Recovery(),
+
+		// The faults are injected last, so the injected panics are recovered like the ones of the handlers.
+		// The injected latency isn't limited by the Timeout, it stops once the call completes.
+		cfg.chaos.UnaryServerInterceptor(),
 	)
 }
 
-// Server describes the template-grpc service server.
+// newIdempotencyStore returns a store backed by the DB, or an in-memory store if no DB given.
+func newIdempotencyStore(db *gorm.DB) (idempotency.Store, error) {
+	if db == nil {
+		return idempotency.NewMemoryStore(), nil
+	}
+
+	return idempotency.NewGormStore(db)
+}
+
+// newStreamInterceptor returns a stream interceptor for the listener of the Server.
+func newStreamInterceptor(cfg ServerConfigs, l Listener) grpc.StreamServerInterceptor {
+	return grpcmiddleware.ChainStreamServer(
+		clientip.NewResolver(l.TrustedProxies).StreamServerInterceptor(),
+		streamEntryInterceptor(l.Entry),
+		cfg.accessLog.StreamServerInterceptor(),
+		cfg.rpcz.StreamServerInterceptor(),
+		cfg.ipFilter.StreamServerInterceptor(l.Name),
+		geo.LookupStreamServerInterceptor(cfg.geoIPDB),
+		cfg.sunsets.StreamServerInterceptor(cfg.userAgents...),
+		audit.NewAuditor(cfg.auditSink, cfg.auditMethods...).StreamServerInterceptor(),
+		authStreamInterceptor(cfg.authenticator, l.RequireAuth),
+		cfg.inflight.StreamServerInterceptor(),
+		cfg.countries.StreamServerInterceptor(),
+		validation.NewValidator(cfg.validationRules).StreamServerInterceptor(),
+		cfg.tenancy.StreamServerInterceptor(),
+		cfg.chaos.StreamServerInterceptor(),
+	)
+}
+
+// Server describes the template-grpc service server, serving several named listeners.
 type Server struct {
-	s   *grpc.Server
-	cfg ServerConfigs
+	servers []*listenerServer
+	cfg     ServerConfigs
 
 	m       sync.Mutex
 	serving int32
+
+	// ready is closed once all listeners are accepting connections.
+	ready chan struct{}
+	// accepting is the number of listeners not accepting connections yet.
+	accepting int32
+
+	// stopSweeper stops deleting the expired idempotency records.
+	stopSweeper func()
+}
+
+// listenerServer is the gRPC server of a listener.
+type listenerServer struct {
+	l Listener
+	s *grpc.Server
 }
 
-// ListenAndServe starts the server and listens to the tcp port defined in configuration.
+// ListenAndServe starts the server and listens to the tcp ports of the listeners defined in configuration.
 func (s *Server) ListenAndServe() error {
-	addr := s.cfg.listenAddr
-	lis, err := net.Listen("tcp", addr)
-	if err != nil {
-		return err
-	}
+	listeners := make([]net.Listener, 0, len(s.servers))
 	defer func() {
-		_ = lis.Close()
+		for _, lis := range listeners {
+			_ = lis.Close()
+		}
 	}()
 
-	return s.serve(lis)
+	for _, srv := range s.servers {
+		lis, err := net.Listen("tcp", srv.l.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on the %s listener: %w", srv.l.Name, err)
+		}
+		if srv.l.ProxyProtocol {
+			lis = proxyproto.NewListener(lis, proxyproto.Params{
+				Trusted: srv.l.TrustedProxies,
+			})
+		}
+		listeners = append(listeners, lis)
+	}
+
+	return s.Serve(listeners...)
 }
 
-func (s *Server) serve(lis net.Listener) error {
+// Serve serves the listeners in the order of the configuration, until all of them are stopped.
+// All listeners are stopped if any of them fails. The listeners could be in-memory, e.g. bufconn in the tests.
+func (s *Server) Serve(listeners ...net.Listener) error {
+	if len(listeners) != len(s.servers) {
+		return errListenersMismatch
+	}
+
 	s.m.Lock()
 	defer s.m.Unlock()
 
@@ -91,14 +273,79 @@ func (s *Server) serve(lis net.Listener) error {
 		atomic.StoreInt32(&s.serving, 0)
 	}()
 
-	return s.s.Serve(lis)
+	atomic.StoreInt32(&s.accepting, int32(len(listeners)))
+	errs := make(chan error, len(listeners))
+	for i, lis := range listeners {
+		lis = &readyListener{Listener: lis, accept: s.accept}
+		go func(srv *listenerServer, lis net.Listener) {
+			if err := srv.s.Serve(lis); err != nil {
+				errs <- fmt.Errorf("failed to serve the %s listener: %w", srv.l.Name, err)
+
+				return
+			}
+			errs <- nil
+		}(s.servers[i], lis)
+	}
+
+	var firstErr error
+	for range listeners {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+			for _, srv := range s.servers {
+				srv.s.Stop()
+			}
+		}
+	}
+
+	return firstErr
 }
 
 func (s *Server) Serving() bool {
 	return atomic.LoadInt32(&s.serving) == 1
 }
 
-// GracefulStop gracefully stops the running server.
+// Ready returns a channel closed once all listeners are accepting connections, it's never closed if serving fails
+// before. A server could be stopped safely once ready.
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Listeners returns the listeners of the server, in the order of the configuration.
+func (s *Server) Listeners() []Listener {
+	listeners := make([]Listener, 0, len(s.servers))
+	for _, srv := range s.servers {
+		listeners = append(listeners, srv.l)
+	}
+
+	return listeners
+}
+
+// accept is called once per listener when it starts accepting connections.
+func (s *Server) accept() {
+	if atomic.AddInt32(&s.accepting, -1) == 0 {
+		close(s.ready)
+	}
+}
+
+// readyListener reports its first Accept call, i.e. when the gRPC server is serving the listener.
+type readyListener struct {
+	net.Listener
+
+	once   sync.Once
+	accept func()
+}
+
+// Accept implements the net.Listener interface.
+func (l *readyListener) Accept() (net.Conn, error) {
+	l.once.Do(l.accept)
+
+	return l.Listener.Accept()
+}
+
+// GracefulStop gracefully stops the running listeners.
 func (s *Server) GracefulStop() {
-	s.s.GracefulStop()
+	for _, srv := range s.servers {
+		srv.s.GracefulStop()
+	}
+	s.stopSweeper()
 }
@@ -5,6 +5,23 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+
+	"github.com/sliide/shared-go-libs/geoip"
+	"github.com/sliide/template-grpc-service/internal/accesslog"
+	"github.com/sliide/template-grpc-service/internal/audit"
+	"github.com/sliide/template-grpc-service/internal/cache"
+	"github.com/sliide/template-grpc-service/internal/capture"
+	"github.com/sliide/template-grpc-service/internal/chaos"
+	"github.com/sliide/template-grpc-service/internal/deprecation"
+	"github.com/sliide/template-grpc-service/internal/geo"
+	"github.com/sliide/template-grpc-service/internal/idempotency"
+	"github.com/sliide/template-grpc-service/internal/inflight"
+	"github.com/sliide/template-grpc-service/internal/ipfilter"
+	"github.com/sliide/template-grpc-service/internal/payloadlog"
+	"github.com/sliide/template-grpc-service/internal/rpcz"
+	"github.com/sliide/template-grpc-service/internal/shadow"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+	"github.com/sliide/template-grpc-service/internal/validation"
 )
 
 const (
@@ -14,6 +31,8 @@ const (
 	defaultMaxConnectionAge = time.Second * 60
 	// defaultMaxConnectionAgeGrace allows pending RPCs to complete before forcibly closing connections.
 	defaultMaxConnectionAgeGrace = time.Second * 10
+	// defaultCacheMaxEntries is the maximum number of responses kept by the default in-memory cache.
+	defaultCacheMaxEntries = 10000
 )
 
 // ServerConfigs defines the initial configs for the content Server.
@@ -21,11 +40,46 @@ type ServerConfigs struct {
 	name       string
 	listenAddr string
 
-	logger *logrus.Entry
+	// listeners are the named listeners of the server, a default listener on listenAddr is used if empty.
+	listeners     []Listener
+	authenticator Authenticator
+	tenancy       *tenant.Tenancy
+
+	logger     *logrus.Entry
+	ipFilter   *ipfilter.Filter
+	geoIPDB    geoip.DB
+	countries  geo.CountryRules
+	accessLog  *accesslog.Logger
+	rpcz       *rpcz.Store
+	inflight   *inflight.Registry
+	chaos      *chaos.Injector
+	shadow     *shadow.Shadow
+	capture    *capture.Recorder
+	sunsets    deprecation.Sunsets
+	userAgents []string
 
 	maxConnectionAge      time.Duration
 	maxConnectionAgeGrace time.Duration
 
+	payloadLog payloadlog.Params
+
+	auditMethods []string
+	auditSink    audit.Sink
+	auditKey     []byte
+
+	validationRules validation.MethodRules
+
+	idempotentMethods []string
+	idempotencyTTL    time.Duration
+	idempotencyLease  time.Duration
+	idempotencyStore  idempotency.Store
+
+	cacheMethods      []string
+	cacheTTL          time.Duration
+	cacheMetadataKeys []string
+	cacheShared       bool
+	cacheBackend      cache.Backend
+
 	db *gorm.DB
 }
 
@@ -46,6 +100,107 @@ func SetLogger(logger *logrus.Entry) ServerConfigsOpts {
 	}
 }
 
+// AddListener adds a named listener to the server, the listenAddr of the ServerConfigParams is ignored
+// once any listener is added.
+func AddListener(l Listener) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.listeners = append(cfg.listeners, l)
+	}
+}
+
+// SetAuthenticator sets the authenticator of the calls of the listeners requiring auth.
+func SetAuthenticator(authenticator Authenticator) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.authenticator = authenticator
+	}
+}
+
+// SetTenancy sets the tenant identification and quotas of the calls, the calls have no tenant by default.
+func SetTenancy(tenancy *tenant.Tenancy) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.tenancy = tenancy
+	}
+}
+
+// SetIPFilter sets the IP allow and deny lists of the listeners, all client IPs are allowed by default.
+func SetIPFilter(filter *ipfilter.Filter) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.ipFilter = filter
+	}
+}
+
+// SetGeoIPDB sets the database to look up the geo locations of the client IPs, the locations are unknown by default.
+func SetGeoIPDB(db geoip.DB) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.geoIPDB = db
+	}
+}
+
+// SetCountryRules sets the allowed and denied countries of the methods, all countries are allowed by default.
+func SetCountryRules(rules geo.CountryRules) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.countries = rules
+	}
+}
+
+// SetSunsets sets the sunset dates of the deprecated methods and services, signalled to the callers in the response
+// headers. No methods are deprecated by default.
+func SetSunsets(sunsets deprecation.Sunsets) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.sunsets = sunsets
+	}
+}
+
+// SetDeprecationUserAgents sets the products of the user agents counted in the calls of the deprecated methods,
+// e.g. "my-app", the other products are counted as "other".
+func SetDeprecationUserAgents(products ...string) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.userAgents = products
+	}
+}
+
+// SetAccessLogger sets the accessLog attribute of a ServerConfigs, the calls are not access logged by default.
+func SetAccessLogger(logger *accesslog.Logger) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.accessLog = logger
+	}
+}
+
+// SetRPCZ sets the store of the recent calls shown in the rpcz debug pages, the calls are not kept by default.
+func SetRPCZ(store *rpcz.Store) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.rpcz = store
+	}
+}
+
+// SetInFlightRegistry sets the registry of the calls currently running, the calls are not registered by default.
+func SetInFlightRegistry(registry *inflight.Registry) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.inflight = registry
+	}
+}
+
+// SetChaos sets the injector of the faults into the calls, no faults are injected by default.
+func SetChaos(injector *chaos.Injector) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.chaos = injector
+	}
+}
+
+// SetShadow sets the mirroring of the unary calls to a shadow target, the calls are not mirrored by default.
+func SetShadow(s *shadow.Shadow) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.shadow = s
+	}
+}
+
+// SetCapture sets the recorder capturing a sample of the unary calls, the calls are not captured by default.
+func SetCapture(r *capture.Recorder) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.capture = r
+	}
+}
+
 // SetMaxConnectionAge sets the maxConnectionAge attribute of a ServerConfigs.
 func SetMaxConnectionAge(value time.Duration) ServerConfigsOpts {
 	return func(cfg *ServerConfigs) {
@@ -60,6 +215,112 @@ func SetMaxConnectionAgeGrace(value time.Duration) ServerConfigsOpts {
 	}
 }
 
+// SetPayloadLogging sets the payloadLog attribute of a ServerConfigs,
+// the request and response payloads are not logged by default.
+func SetPayloadLogging(params payloadlog.Params) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.payloadLog = params
+	}
+}
+
+// SetAuditMethods sets the full method names whose calls are written into the audit trail.
+func SetAuditMethods(methods ...string) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.auditMethods = methods
+	}
+}
+
+// SetAuditKey sets the HMAC key signing the audit records written into the DB.
+func SetAuditKey(key []byte) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.auditKey = key
+	}
+}
+
+// SetAuditSink sets the auditSink attribute of a ServerConfigs,
+// a sink backed by the DB is used by default.
+func SetAuditSink(sink audit.Sink) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.auditSink = sink
+	}
+}
+
+// AddValidationRules adds the request validation rules to the validationRules attribute of a ServerConfigs.
+func AddValidationRules(rules validation.MethodRules) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.validationRules = cfg.validationRules.Merge(rules)
+	}
+}
+
+// SetIdempotentMethods sets the full method names which support the idempotency keys.
+func SetIdempotentMethods(methods ...string) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.idempotentMethods = methods
+	}
+}
+
+// SetIdempotencyTTL sets the idempotencyTTL attribute of a ServerConfigs.
+func SetIdempotencyTTL(value time.Duration) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.idempotencyTTL = value
+	}
+}
+
+// SetIdempotencyLease sets the idempotencyLease attribute of a ServerConfigs,
+// must be longer than the timeout of the idempotent methods.
+func SetIdempotencyLease(value time.Duration) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.idempotencyLease = value
+	}
+}
+
+// SetIdempotencyStore sets the idempotencyStore attribute of a ServerConfigs,
+// a store backed by the DB (or in-memory if no DB given) is used by default.
+// The expired records of the stores implementing idempotency.Sweeper are deleted periodically while serving.
+func SetIdempotencyStore(store idempotency.Store) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.idempotencyStore = store
+	}
+}
+
+// SetCacheMethods sets the full method names whose responses are cached,
+// the methods must be idempotent and read-only.
+func SetCacheMethods(methods ...string) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.cacheMethods = methods
+	}
+}
+
+// SetCacheTTL sets the cacheTTL attribute of a ServerConfigs.
+func SetCacheTTL(value time.Duration) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.cacheTTL = value
+	}
+}
+
+// SetCacheMetadataKeys sets the request metadata keys which the cached responses vary by.
+func SetCacheMetadataKeys(keys ...string) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.cacheMetadataKeys = keys
+	}
+}
+
+// SetCacheShared shares the cached responses across the callers and tenants,
+// only for the methods whose responses don't depend on the caller.
+func SetCacheShared(value bool) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.cacheShared = value
+	}
+}
+
+// SetCacheBackend sets the cacheBackend attribute of a ServerConfigs,
+// an in-memory LRU backend is used by default.
+func SetCacheBackend(backend cache.Backend) ServerConfigsOpts {
+	return func(cfg *ServerConfigs) {
+		cfg.cacheBackend = backend
+	}
+}
+
 // NewServerConfigs returns a new ServerConfigs object initialized with ServerConfigParams, and the default
 // values for other attributes.
 // Clients can also provide optional parameters to override one or more default values.
@@ -71,6 +332,10 @@ func NewServerConfigs(params ServerConfigParams, opts ...ServerConfigsOpts) Serv
 		logger:                logrus.NewEntry(logrus.StandardLogger()),
 		maxConnectionAge:      defaultMaxConnectionAge,
 		maxConnectionAgeGrace: defaultMaxConnectionAgeGrace,
+		validationRules:       echoValidationRules(),
+		idempotencyTTL:        idempotency.DefaultTTL,
+		idempotencyLease:      idempotency.DefaultLease,
+		cacheTTL:              cache.DefaultTTL,
 	}
 
 	for _, o := range opts {
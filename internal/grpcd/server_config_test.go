@@ -7,6 +7,22 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
+
+	"github.com/sliide/template-grpc-service/internal/accesslog"
+	"github.com/sliide/template-grpc-service/internal/audit"
+	"github.com/sliide/template-grpc-service/internal/cache"
+	"github.com/sliide/template-grpc-service/internal/capture"
+	"github.com/sliide/template-grpc-service/internal/chaos"
+	"github.com/sliide/template-grpc-service/internal/deprecation"
+	"github.com/sliide/template-grpc-service/internal/geo"
+	"github.com/sliide/template-grpc-service/internal/idempotency"
+	"github.com/sliide/template-grpc-service/internal/inflight"
+	"github.com/sliide/template-grpc-service/internal/ipfilter"
+	"github.com/sliide/template-grpc-service/internal/payloadlog"
+	"github.com/sliide/template-grpc-service/internal/rpcz"
+	"github.com/sliide/template-grpc-service/internal/shadow"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+	"github.com/sliide/template-grpc-service/internal/validation"
 )
 
 func TestNewServerConfigs(t *testing.T) {
@@ -16,6 +32,20 @@ func TestNewServerConfigs(t *testing.T) {
 	}
 
 	db := new(gorm.DB)
+	store := idempotency.NewMemoryStore()
+	backend := cache.NewLRU(10, 0, nil)
+	auditSink := &audit.FileSink{}
+	accessLog := &accesslog.Logger{}
+	rpczStore := rpcz.NewStore(1)
+	registry := inflight.NewRegistry()
+	injector, _ := chaos.New(chaos.Params{})
+	shadowing, _ := shadow.New(nil, shadow.Params{Rate: 1, Methods: []string{"/template.v1.Echo/UnaryEcho"}})
+	recorder := capture.NewRecorder(capture.Params{Rate: 1})
+	ipFilter, _ := ipfilter.NewFilter(ipfilter.Config{})
+	geoIPDB := &geo.DB{}
+	tenancy := tenant.New(tenant.Params{Required: true})
+	countries := geo.CountryRules{"/test.Service/Get": {Deny: []string{"KP"}}}
+	sunsets := deprecation.Sunsets{"/test.v1.Service/": time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)}
 	tests := []struct {
 		name     string
 		args     args
@@ -36,6 +66,10 @@ func TestNewServerConfigs(t *testing.T) {
 				logger:                logrus.NewEntry(logrus.StandardLogger()),
 				maxConnectionAge:      time.Second * 60,
 				maxConnectionAgeGrace: time.Second * 10,
+				validationRules:       echoValidationRules(),
+				idempotencyTTL:        time.Hour * 24,
+				idempotencyLease:      time.Second * 30,
+				cacheTTL:              time.Minute,
 				db:                    db,
 			},
 		},
@@ -49,17 +83,79 @@ func TestNewServerConfigs(t *testing.T) {
 				},
 				opts: []ServerConfigsOpts{
 					SetLogger(logrus.NewEntry(logrus.StandardLogger())),
+					AddListener(Listener{Name: "public", Addr: "localhost:8080"}),
+					AddListener(Listener{Name: "internal", Addr: "localhost:8081", RequireAuth: true}),
+					SetTenancy(tenancy),
+					SetIPFilter(ipFilter),
+					SetGeoIPDB(geoIPDB),
+					SetCountryRules(countries),
+					SetSunsets(sunsets),
+					SetDeprecationUserAgents("my-app"),
+					SetAccessLogger(accessLog),
+					SetRPCZ(rpczStore),
+					SetInFlightRegistry(registry),
+					SetChaos(injector),
+					SetShadow(shadowing),
+					SetCapture(recorder),
 					SetMaxConnectionAge(time.Second * 2),
 					SetMaxConnectionAgeGrace(time.Hour * 10),
+					SetPayloadLogging(payloadlog.Params{Rate: 0.1, RedactFields: []string{"password"}}),
+					SetAuditMethods("/test.Service/Delete"),
+					SetAuditSink(auditSink),
+					SetAuditKey([]byte("key")),
+					AddValidationRules(validation.MethodRules{
+						"/test.Service/Method": {validation.Field("name", validation.Required{})},
+					}),
+					SetIdempotentMethods("/test.Service/Create"),
+					SetIdempotencyTTL(time.Hour),
+					SetIdempotencyLease(time.Minute),
+					SetIdempotencyStore(store),
+					SetCacheMethods("/test.Service/Get"),
+					SetCacheTTL(time.Second),
+					SetCacheMetadataKeys("accept-language"),
+					SetCacheShared(true),
+					SetCacheBackend(backend),
 				},
 			},
 			expected: ServerConfigs{
-				name:                  "some-service-Name",
-				listenAddr:            "localhost:8080",
+				name:       "some-service-Name",
+				listenAddr: "localhost:8080",
+				listeners: []Listener{
+					{Name: "public", Addr: "localhost:8080"},
+					{Name: "internal", Addr: "localhost:8081", RequireAuth: true},
+				},
+				tenancy:               tenancy,
 				logger:                logrus.NewEntry(logrus.StandardLogger()),
+				ipFilter:              ipFilter,
+				geoIPDB:               geoIPDB,
+				countries:             countries,
+				sunsets:               sunsets,
+				userAgents:            []string{"my-app"},
+				accessLog:             accessLog,
+				rpcz:                  rpczStore,
+				inflight:              registry,
+				chaos:                 injector,
+				shadow:                shadowing,
+				capture:               recorder,
 				maxConnectionAge:      time.Second * 2,
 				maxConnectionAgeGrace: time.Hour * 10,
-				db:                    db,
+				payloadLog:            payloadlog.Params{Rate: 0.1, RedactFields: []string{"password"}},
+				auditMethods:          []string{"/test.Service/Delete"},
+				auditSink:             auditSink,
+				auditKey:              []byte("key"),
+				validationRules: echoValidationRules().Merge(validation.MethodRules{
+					"/test.Service/Method": {validation.Field("name", validation.Required{})},
+				}),
+				idempotentMethods: []string{"/test.Service/Create"},
+				idempotencyTTL:    time.Hour,
+				idempotencyLease:  time.Minute,
+				idempotencyStore:  store,
+				cacheMethods:      []string{"/test.Service/Get"},
+				cacheTTL:          time.Second,
+				cacheMetadataKeys: []string{"accept-language"},
+				cacheShared:       true,
+				cacheBackend:      backend,
+				db:                db,
 			},
 		},
 	}
@@ -12,8 +12,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 	"gorm.io/gorm"
+
+	"github.com/sliide/template-grpc-service/internal/accesslog"
+	"github.com/sliide/template-grpc-service/internal/chaos"
+	"github.com/sliide/template-grpc-service/internal/ipfilter"
 )
 
 func TestServerListenAndServe(t *testing.T) {
@@ -31,7 +37,7 @@ func TestServerListenAndServe(t *testing.T) {
 		ch <- true
 	}()
 
-	time.Sleep(time.Millisecond * 100)
+	waitReady(t, s)
 	assertions.True(s.Serving())
 	s.GracefulStop()
 
@@ -54,11 +60,11 @@ func TestServerServe(t *testing.T) {
 	defer listener.Close()
 
 	go func() {
-		assertions.NoError(s.serve(listener))
+		assertions.NoError(s.Serve(listener))
 		ch <- true
 	}()
 
-	time.Sleep(time.Millisecond * 100)
+	waitReady(t, s)
 	assertions.True(s.Serving())
 	s.GracefulStop()
 
@@ -81,7 +87,7 @@ func TestUnaryInterceptor(t *testing.T) {
 		l.SetOutput(b)
 
 		assertions.NotPanics(func() {
-			_, _ = newUnaryInterceptor(l.WithField("env", "test"))(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, _ = newUnaryInterceptor(ServerConfigs{logger: l.WithField("env", "test")}, DefaultListener(""))(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
 				panic("cause panic")
 			})
 		}, "Must have a recovery interceptor")
@@ -89,6 +95,53 @@ func TestUnaryInterceptor(t *testing.T) {
 		assertions.Contains(b.String(), `"msg":"Caught panic in request"`)
 	})
 
+	t.Run("Chaos panic recovered", func(t *testing.T) {
+		injector, err := chaos.New(chaos.Params{Config: chaos.Config{Rules: []chaos.Rule{
+			{Name: "panic", Enabled: true, Panic: true},
+		}}})
+		assertions.NoError(err)
+
+		b := bytes.NewBuffer(nil)
+		l := logrus.New()
+		l.SetFormatter(&logrus.JSONFormatter{})
+		l.SetOutput(b)
+
+		cfg := ServerConfigs{logger: l.WithField("env", "test"), chaos: injector}
+		assertions.NotPanics(func() {
+			_, err = newUnaryInterceptor(cfg, DefaultListener(""))(context.Background(), struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return req, nil
+				})
+		}, "Must recover the injected panics")
+		assertions.Error(err)
+
+		assertions.Contains(b.String(), `"msg":"Chaos fault injected"`)
+		assertions.Contains(b.String(), `"msg":"Caught panic in request"`)
+	})
+
+	t.Run("Blocked call access logged", func(t *testing.T) {
+		filter, err := ipfilter.NewFilter(ipfilter.Config{
+			defaultListenerName: {Rules: ipfilter.Rules{Allow: []string{"10.0.0.0/8"}}},
+		})
+		assertions.NoError(err)
+
+		b := bytes.NewBuffer(nil)
+		accessLogger, err := accesslog.NewLogger(accesslog.Params{Output: b})
+		assertions.NoError(err)
+
+		cfg := ServerConfigs{ipFilter: filter, accessLog: accessLogger}
+		_, err = newUnaryInterceptor(cfg, DefaultListener(""))(context.Background(), struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return req, nil
+			})
+		assertions.Equal(codes.PermissionDenied, status.Code(err))
+
+		var entry accesslog.Entry
+		assertions.NoError(json.Unmarshal(b.Bytes(), &entry))
+		assertions.Equal("/test.Service/Get", entry.Path)
+		assertions.Equal(codes.PermissionDenied.String(), entry.Status)
+	})
+
 	t.Run("Ensure contains EntryLogs()", func(t *testing.T) {
 		ctx := context.Background()
 		req := struct{}{}
@@ -99,7 +152,7 @@ func TestUnaryInterceptor(t *testing.T) {
 		l.SetFormatter(&logrus.JSONFormatter{})
 		l.SetOutput(b)
 
-		_, _ = newUnaryInterceptor(l.WithField("env", "test"))(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, _ = newUnaryInterceptor(ServerConfigs{logger: l.WithField("env", "test")}, DefaultListener(""))(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
 			return nil, nil
 		})
 
@@ -120,7 +173,7 @@ func TestStackMessageAfterPanic(t *testing.T) {
 	req := struct{}{}
 	info := &grpc.UnaryServerInfo{}
 
-	_, _ = newUnaryInterceptor(l.WithField("service", "test"))(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+	_, _ = newUnaryInterceptor(ServerConfigs{logger: l.WithField("service", "test")}, DefaultListener(""))(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
 		causePanicFunc("panic")
 
 		return req, nil
@@ -148,6 +201,15 @@ func TestStackMessageAfterPanic(t *testing.T) {
 	assertions.True(anyCaught, "No panic caught logs")
 }
 
+// waitReady waits for the server to serve its listeners.
+func waitReady(t *testing.T, s *Server) {
+	select {
+	case <-s.Ready():
+	case <-time.After(5 * time.Second):
+		t.Fatal("The server isn't ready")
+	}
+}
+
 func causePanicFunc(message string) {
 	panic(message)
 }
@@ -0,0 +1,123 @@
+// Package grpcdtest starts the servers built from the template in the tests, on in-memory listeners and with
+// in-memory stores, so the tests call the services through the whole interceptor chain without a network or a DB.
+package grpcdtest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/sliide/template-grpc-service/internal/audit"
+	"github.com/sliide/template-grpc-service/internal/grpcd"
+	"github.com/sliide/template-grpc-service/internal/idempotency"
+)
+
+const (
+	// bufferSize is the buffer size of the in-memory listeners.
+	bufferSize = 1024 * 1024
+
+	// readyTimeout is the time limit of the server to start serving.
+	readyTimeout = 5 * time.Second
+)
+
+// Server is a grpcd.Server serving in-memory listeners, stopped at the end of the test.
+type Server struct {
+	*grpcd.Server
+
+	// Conn is a client connection to the first listener.
+	Conn *grpc.ClientConn
+	// Logs are the logs of the server.
+	Logs *Logs
+	// Audit receives the audit records, unless another sink is set in the options.
+	Audit *audit.MemorySink
+	// Idempotency keeps the responses of the idempotent methods, unless another store is set in the options.
+	Idempotency *idempotency.MemoryStore
+
+	listeners map[string]*bufconn.Listener
+}
+
+// Start starts a server of the options, and returns it once all listeners are serving. The logs and the stores
+// are in-memory by default, the options override them. The test fails if the server doesn't start.
+func Start(t testing.TB, opts ...grpcd.ServerConfigsOpts) *Server {
+	t.Helper()
+
+	s := &Server{
+		Logs:        NewLogs(),
+		Audit:       &audit.MemorySink{},
+		Idempotency: idempotency.NewMemoryStore(),
+		listeners:   make(map[string]*bufconn.Listener),
+	}
+
+	opts = append([]grpcd.ServerConfigsOpts{
+		grpcd.SetLogger(s.Logs.Entry()),
+		grpcd.SetAuditSink(s.Audit),
+		grpcd.SetIdempotencyStore(s.Idempotency),
+	}, opts...)
+
+	var err error
+	s.Server, err = grpcd.NewServer(grpcd.NewServerConfigs(grpcd.ServerConfigParams{Name: "test"}, opts...))
+	if err != nil {
+		t.Fatalf("Failed to create the server: %v", err)
+	}
+
+	netListeners := make([]net.Listener, 0, len(s.Listeners()))
+	for _, l := range s.Listeners() {
+		lis := bufconn.Listen(bufferSize)
+		s.listeners[l.Name] = lis
+		netListeners = append(netListeners, lis)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Serve(netListeners...)
+	}()
+
+	select {
+	case <-s.Ready():
+	case err := <-errs:
+		t.Fatalf("Failed to serve: %v", err)
+	case <-time.After(readyTimeout):
+		t.Fatalf("The server isn't ready after %s", readyTimeout)
+	}
+
+	t.Cleanup(func() {
+		s.GracefulStop()
+		if err := <-errs; err != nil {
+			t.Errorf("Failed to serve: %v", err)
+		}
+	})
+
+	s.Conn = s.Dial(t, s.Listeners()[0].Name)
+
+	return s
+}
+
+// Dial returns a client connection to the named listener, closed at the end of the test.
+func (s *Server) Dial(t testing.TB, listener string, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+
+	lis, ok := s.listeners[listener]
+	if !ok {
+		t.Fatalf("Unknown listener %q", listener)
+	}
+
+	opts = append([]grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+	}, opts...)
+	conn, err := grpc.Dial("bufnet", opts...)
+	if err != nil {
+		t.Fatalf("Failed to dial the %s listener: %v", listener, err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
@@ -0,0 +1,126 @@
+package grpcdtest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+	"github.com/sliide/template-grpc-service/internal/grpcd"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/identity"
+	"github.com/sliide/template-grpc-service/internal/ipfilter"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+)
+
+const unaryEcho = "/template.v2.Echo/UnaryEcho"
+
+func TestStart(t *testing.T) {
+	s := Start(t, grpcd.SetAuditMethods(unaryEcho))
+	assert.True(t, s.Serving())
+
+	resp, err := templatev2.NewEchoClient(s.Conn).UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp.GetMessage())
+
+	entries := s.Logs.Find("Request completed")
+	require.Len(t, entries, 1)
+	assert.Equal(t, "UnaryEcho", entries[0]["grpc_method"])
+	assert.Equal(t, "info", entries[0].Level())
+
+	records := s.Audit.Records()
+	require.Len(t, records, 1, "Must audit into the in-memory sink")
+	assert.Equal(t, unaryEcho, records[0].Method)
+
+	s.Logs.Reset()
+	assert.Empty(t, s.Logs.Entries())
+}
+
+func TestStartListeners(t *testing.T) {
+	s := Start(t,
+		grpcd.SetAuditMethods(unaryEcho),
+		grpcd.AddListener(grpcd.Listener{Name: "public"}),
+		grpcd.AddListener(grpcd.Listener{Name: "internal", RequireAuth: true}),
+		grpcd.SetAuthenticator(func(ctx context.Context) (string, identity.Claims, error) {
+			return "", nil, errors.New("no credentials")
+		}),
+	)
+
+	_, err := templatev2.NewEchoClient(s.Conn).UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "hello"})
+	assert.NoError(t, err, "Must connect to the first listener")
+
+	_, err = templatev2.NewEchoClient(s.Dial(t, "internal")).UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "hello"})
+	AssertCode(t, err, codes.Unauthenticated)
+
+	records := s.Audit.Records()
+	require.Len(t, records, 2, "Must audit the calls denied by the authentication")
+	assert.Equal(t, "denied", records[1].Decision)
+	assert.Equal(t, "Unauthenticated", records[1].Code)
+}
+
+func TestIPFilterFirst(t *testing.T) {
+	filter, err := ipfilter.NewFilter(ipfilter.Config{"internal": {Rules: ipfilter.Rules{Allow: []string{"10.0.0.0/8"}}}})
+	require.NoError(t, err)
+	s := Start(t,
+		grpcd.SetAuditMethods(unaryEcho),
+		grpcd.SetIPFilter(filter),
+		grpcd.AddListener(grpcd.Listener{Name: "internal", RequireAuth: true}),
+		grpcd.SetAuthenticator(func(ctx context.Context) (string, identity.Claims, error) {
+			return "", nil, errors.New("no credentials")
+		}),
+	)
+
+	_, err = templatev2.NewEchoClient(s.Conn).UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "hello"})
+	RequireErrorInfo(t, err, codes.PermissionDenied, "IP_BLOCKED")
+	assert.Empty(t, s.Audit.Records(), "Must block the call before the authentication and the audit")
+	assert.Empty(t, s.Logs.Find("Request completed"))
+}
+
+func TestTenancyAfterValidation(t *testing.T) {
+	s := Start(t, grpcd.SetTenancy(tenant.New(tenant.Params{
+		Quotas: tenant.Quotas{Tenants: map[string]tenant.Quota{"team-a": {Daily: 1}}},
+		Store:  tenant.NewMemoryStore(),
+	})))
+	client := templatev2.NewEchoClient(s.Conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tenant.MetaKeyTenant, "team-a")
+
+	_, err := client.UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: strings.Repeat("a", 500)})
+	AssertCode(t, err, codes.InvalidArgument)
+
+	_, err = client.UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: "hello"})
+	assert.NoError(t, err, "Must not count the invalid calls in the quota")
+
+	_, err = client.UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: "hello"})
+	AssertCode(t, err, codes.ResourceExhausted)
+
+	_, err = client.UnaryEcho(metadata.AppendToOutgoingContext(context.Background(), tenant.MetaKeyTenant, "team-b"),
+		&templatev2.UnaryEchoRequest{Message: "hello"})
+	AssertCode(t, err, codes.PermissionDenied)
+}
+
+func TestStatusAssertions(t *testing.T) {
+	s := Start(t)
+
+	_, err := templatev2.NewEchoClient(s.Conn).UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: strings.Repeat("a", 500)})
+	AssertCode(t, err, codes.InvalidArgument)
+	AssertDetail(t, err, grpcerr.BadRequest{
+		FieldViolations: []grpcerr.FieldViolation{
+			{Field: "message", Description: "must be at most 499 bytes long"},
+		},
+	})
+	info := RequireErrorInfo(t, err, codes.InvalidArgument, "INVALID_ARGUMENT")
+	assert.Equal(t, "test", info.Domain)
+
+	t.Run("Failures", func(t *testing.T) {
+		mock := &testing.T{}
+		assert.False(t, AssertCode(mock, err, codes.OK))
+		assert.False(t, AssertDetail(mock, err, grpcerr.RetryInfo{}))
+		assert.True(t, mock.Failed())
+	})
+}
@@ -0,0 +1,93 @@
+package grpcdtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry is a log entry parsed from JSON, e.g. {"level": "info", "msg": "Request completed", ...}.
+type Entry map[string]interface{}
+
+// Message returns the message of the entry.
+func (e Entry) Message() string {
+	msg, _ := e["msg"].(string)
+
+	return msg
+}
+
+// Level returns the level of the entry.
+func (e Entry) Level() string {
+	level, _ := e["level"].(string)
+
+	return level
+}
+
+// Logs captures the logs of a logger in the JSON format.
+type Logs struct {
+	m      sync.Mutex
+	b      bytes.Buffer
+	logger *logrus.Logger
+}
+
+// NewLogs returns new logs capturing all levels.
+func NewLogs() *Logs {
+	l := &Logs{logger: logrus.New()}
+	l.logger.SetFormatter(&logrus.JSONFormatter{})
+	l.logger.SetLevel(logrus.DebugLevel)
+	l.logger.SetOutput(l)
+
+	return l
+}
+
+// Entry returns a log entry writing into the logs, to be passed to the server.
+func (l *Logs) Entry() *logrus.Entry {
+	return logrus.NewEntry(l.logger)
+}
+
+// Write implements the io.Writer interface.
+func (l *Logs) Write(p []byte) (int, error) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	return l.b.Write(p)
+}
+
+// Entries returns the entries logged so far, the lines which aren't JSON objects are skipped.
+func (l *Logs) Entries() []Entry {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	var entries []Entry
+	for _, line := range bytes.Split(l.b.Bytes(), []byte("\n")) {
+		e := Entry{}
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// Find returns the entries of the message.
+func (l *Logs) Find(msg string) []Entry {
+	var entries []Entry
+	for _, e := range l.Entries() {
+		if e.Message() == msg {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// Reset drops the entries logged so far.
+func (l *Logs) Reset() {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	l.b.Reset()
+}
@@ -0,0 +1,59 @@
+package grpcdtest
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+)
+
+// AssertCode asserts the status code of the error, returns true if it matches.
+func AssertCode(t testing.TB, err error, code codes.Code) bool {
+	t.Helper()
+
+	if got := status.Code(err); got != code {
+		t.Errorf("Status code %s, expected %s: %v", got, code, err)
+
+		return false
+	}
+
+	return true
+}
+
+// AssertDetail asserts the status of the error contains the detail, e.g. a grpcerr.RetryInfo.
+func AssertDetail(t testing.TB, err error, detail grpcerr.Detail) bool {
+	t.Helper()
+
+	details := grpcerr.Details(err)
+	for _, d := range details {
+		if reflect.DeepEqual(d, detail) {
+			return true
+		}
+	}
+	t.Errorf("Status details %+v, expected to contain %+v", details, detail)
+
+	return false
+}
+
+// RequireErrorInfo requires the code of the error and its ErrorInfo detail of the reason, and returns the detail,
+// e.g. to assert its metadata.
+func RequireErrorInfo(t testing.TB, err error, code codes.Code, reason string) grpcerr.ErrorInfo {
+	t.Helper()
+
+	if !AssertCode(t, err, code) {
+		t.FailNow()
+	}
+
+	details := grpcerr.Details(err)
+	for _, d := range details {
+		if info, ok := d.(grpcerr.ErrorInfo); ok && info.Reason == reason {
+			return info
+		}
+	}
+	t.Fatalf("Status details %+v, expected an ErrorInfo of the %s reason", details, reason)
+
+	return grpcerr.ErrorInfo{}
+}
@@ -0,0 +1,135 @@
+package grpcerr
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Detail represents an error detail which is attached to the google.rpc.Status returned to the caller,
+// encoded as the google.rpc error detail messages of the canonical errdetails package.
+type Detail interface {
+	message() proto.Message
+}
+
+// FieldViolation describes a single bad request field.
+type FieldViolation struct {
+	// Field is a path that leads to a field in the request body, e.g. "message".
+	Field string
+	// Description of why the request element is bad.
+	Description string
+}
+
+// BadRequest describes violations in a client request, see google.rpc.BadRequest.
+type BadRequest struct {
+	FieldViolations []FieldViolation
+}
+
+// ErrorInfo describes the cause of the error with structured details, see google.rpc.ErrorInfo.
+type ErrorInfo struct {
+	// Reason is a constant value in UPPER_SNAKE_CASE that identifies the proximate cause of the error.
+	Reason string
+	// Domain is the logical grouping to which the reason belongs, usually the service name.
+	Domain string
+	// Metadata contains additional structured details about the error.
+	Metadata map[string]string
+}
+
+// RetryInfo describes when the clients can retry a failed request, see google.rpc.RetryInfo.
+type RetryInfo struct {
+	RetryDelay time.Duration
+}
+
+// LocalizedMessage provides an error message that is safe to return to the end user,
+// see google.rpc.LocalizedMessage.
+type LocalizedMessage struct {
+	// Locale follows the specification defined at https://www.rfc-editor.org/rfc/bcp/bcp47.txt, e.g. "en-US".
+	Locale  string
+	Message string
+}
+
+func (d BadRequest) message() proto.Message {
+	m := &errdetails.BadRequest{}
+	for _, v := range d.FieldViolations {
+		m.FieldViolations = append(m.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	return m
+}
+
+func (d ErrorInfo) message() proto.Message {
+	return &errdetails.ErrorInfo{
+		Reason:   d.Reason,
+		Domain:   d.Domain,
+		Metadata: d.Metadata,
+	}
+}
+
+func (d RetryInfo) message() proto.Message {
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryDelay)}
+}
+
+func (d LocalizedMessage) message() proto.Message {
+	return &errdetails.LocalizedMessage{
+		Locale:  d.Locale,
+		Message: d.Message,
+	}
+}
+
+// toAny wraps the detail into an Any message which is used in google.rpc.Status.
+func toAny(d Detail) *anypb.Any {
+	a, err := anypb.New(d.message())
+	if err != nil {
+		// The detail messages are generated, thus always serializable
+		panic(err)
+	}
+
+	return a
+}
+
+// fromAny decodes a detail from the Any message, returns false if the type is not supported.
+func fromAny(a *anypb.Any) (Detail, bool, error) {
+	var m proto.Message
+	switch a.MessageName() {
+	case "google.rpc.BadRequest":
+		m = &errdetails.BadRequest{}
+	case "google.rpc.ErrorInfo":
+		m = &errdetails.ErrorInfo{}
+	case "google.rpc.RetryInfo":
+		m = &errdetails.RetryInfo{}
+	case "google.rpc.LocalizedMessage":
+		m = &errdetails.LocalizedMessage{}
+	default:
+		return nil, false, nil
+	}
+	if err := a.UnmarshalTo(m); err != nil {
+		return nil, true, err
+	}
+
+	switch m := m.(type) {
+	case *errdetails.BadRequest:
+		d := BadRequest{}
+		for _, v := range m.GetFieldViolations() {
+			d.FieldViolations = append(d.FieldViolations, FieldViolation{
+				Field:       v.GetField(),
+				Description: v.GetDescription(),
+			})
+		}
+
+		return d, true, nil
+	case *errdetails.ErrorInfo:
+		return ErrorInfo{Reason: m.GetReason(), Domain: m.GetDomain(), Metadata: m.GetMetadata()}, true, nil
+	case *errdetails.RetryInfo:
+		return RetryInfo{RetryDelay: m.GetRetryDelay().AsDuration()}, true, nil
+	case *errdetails.LocalizedMessage:
+		return LocalizedMessage{Locale: m.GetLocale(), Message: m.GetMessage()}, true, nil
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,81 @@
+package grpcerr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestDetailsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		detail Detail
+	}{
+		{
+			name: "BadRequest",
+			detail: BadRequest{FieldViolations: []FieldViolation{
+				{Field: "message", Description: "too long"},
+				{Field: "name", Description: "required"},
+			}},
+		},
+		{
+			name: "ErrorInfo",
+			detail: ErrorInfo{
+				Reason:   "QUOTA_EXCEEDED",
+				Domain:   "template-grpc-service",
+				Metadata: map[string]string{"b": "2", "a": "1"},
+			},
+		},
+		{
+			name:   "RetryInfo",
+			detail: RetryInfo{RetryDelay: 2*time.Second + 500*time.Millisecond},
+		},
+		{
+			name:   "LocalizedMessage",
+			detail: LocalizedMessage{Locale: "en-US", Message: "Please try again later"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok, err := fromAny(toAny(tt.detail))
+
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, tt.detail, actual)
+		})
+	}
+}
+
+func TestDetailsCanonical(t *testing.T) {
+	// The clients decode the details with the canonical errdetails package
+	a := toAny(RetryInfo{RetryDelay: 3*time.Second + 7})
+	assert.Equal(t, "type.googleapis.com/google.rpc.RetryInfo", a.GetTypeUrl())
+
+	m := &errdetails.RetryInfo{}
+	require.NoError(t, a.UnmarshalTo(m))
+	assert.True(t, proto.Equal(durationpb.New(3*time.Second+7), m.GetRetryDelay()))
+}
+
+func TestFromAnyUnsupported(t *testing.T) {
+	a := toAny(LocalizedMessage{})
+	a.TypeUrl = "type.googleapis.com/google.rpc.Help"
+
+	_, ok, err := fromAny(a)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestFromAnyMalformed(t *testing.T) {
+	a := toAny(LocalizedMessage{})
+	a.Value = []byte{0x0a, 0x10, 'a'}
+
+	_, ok, err := fromAny(a)
+	assert.True(t, ok)
+	assert.Error(t, err)
+}
@@ -0,0 +1,231 @@
+// Package grpcerr maps the domain errors returned by the handlers into gRPC statuses
+// with rich error details (google.rpc.BadRequest, ErrorInfo, RetryInfo and LocalizedMessage).
+package grpcerr
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// The error kinds that handlers could return, or wrap with `fmt.Errorf("...: %w", ErrNotFound)`,
+// they are mapped to the gRPC codes by the interceptor. The wrapping errors are returned with a generic message
+// of the kind, use New to return a custom message to the caller.
+var (
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrFailedPrecondition = errors.New("failed precondition")
+	ErrAborted            = errors.New("aborted")
+	ErrResourceExhausted  = errors.New("resource exhausted")
+	ErrUnavailable        = errors.New("unavailable")
+)
+
+// kindCodes is the single place mapping the domain error kinds to gRPC codes, and to the generic messages
+// returned for the errors wrapping the kinds, whose own messages could carry internal details.
+var kindCodes = []struct {
+	kind    error
+	code    codes.Code
+	message string
+}{
+	{ErrInvalidArgument, codes.InvalidArgument, "Invalid argument"},
+	{ErrNotFound, codes.NotFound, "Not found"},
+	{ErrAlreadyExists, codes.AlreadyExists, "Already exists"},
+	{ErrPermissionDenied, codes.PermissionDenied, "Permission denied"},
+	{ErrUnauthenticated, codes.Unauthenticated, "Unauthenticated"},
+	{ErrFailedPrecondition, codes.FailedPrecondition, "Failed precondition"},
+	{ErrAborted, codes.Aborted, "Aborted"},
+	{ErrResourceExhausted, codes.ResourceExhausted, "Resource exhausted"},
+	{ErrUnavailable, codes.Unavailable, "Unavailable"},
+}
+
+// Error represents a domain error with the details returned to the caller.
+type Error struct {
+	// Kind is one of the Err* kinds defined in this package, it decides the gRPC code.
+	// The errors of the other kinds are returned as Internal errors, and their messages are redacted.
+	Kind error
+	// Reason is a constant value in UPPER_SNAKE_CASE that is returned in the ErrorInfo detail.
+	// The reason is derived from the gRPC code when it's empty.
+	Reason string
+	// Message is the developer-facing message returned in the status.
+	Message string
+	// Metadata is returned in the ErrorInfo detail.
+	Metadata map[string]string
+	// Details are the extra details attached to the status.
+	Details []Detail
+}
+
+// New returns a new domain error.
+func New(kind error, reason, message string, details ...Detail) *Error {
+	return &Error{
+		Kind:    kind,
+		Reason:  reason,
+		Message: message,
+		Details: details,
+	}
+}
+
+// NewBadRequest returns an invalid argument error with the given field violations.
+func NewBadRequest(message string, violations ...FieldViolation) *Error {
+	return New(ErrInvalidArgument, "", message, BadRequest{FieldViolations: violations})
+}
+
+// WithRetryDelay attaches a RetryInfo detail which tells the caller when to retry.
+func (e *Error) WithRetryDelay(d time.Duration) *Error {
+	e.Details = append(e.Details, RetryInfo{RetryDelay: d})
+
+	return e
+}
+
+// WithLocalizedMessage attaches a LocalizedMessage detail which could be shown to the end user.
+func (e *Error) WithLocalizedMessage(locale, message string) *Error {
+	e.Details = append(e.Details, LocalizedMessage{Locale: locale, Message: message})
+
+	return e
+}
+
+// WithMetadata sets a key and value into the ErrorInfo metadata.
+func (e *Error) WithMetadata(key, value string) *Error {
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]string)
+	}
+	e.Metadata[key] = value
+
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.Kind != nil {
+		return e.Kind.Error()
+	}
+
+	return "unknown error"
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// GRPCStatus returns the gRPC status of the error, which makes status.FromError and status.Code
+// work on the error without the interceptor, the ErrorInfo domain is left empty in this case.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status("")
+}
+
+// status returns the status of the error, or a redacted Internal status if its kind is unknown.
+func (e *Error) status(domain string) *status.Status {
+	code, ok := codeOfKind(e.Kind)
+	if !ok {
+		return newStatus(codes.Internal, internalMessage, ErrorInfo{
+			Reason: reasonOfCode(codes.Internal),
+			Domain: domain,
+		})
+	}
+
+	reason := e.Reason
+	if reason == "" {
+		reason = reasonOfCode(code)
+	}
+
+	details := make([]Detail, 0, len(e.Details)+1)
+	details = append(details, ErrorInfo{
+		Reason:   reason,
+		Domain:   domain,
+		Metadata: e.Metadata,
+	})
+	details = append(details, e.Details...)
+
+	return newStatus(code, e.Error(), details...)
+}
+
+// Details returns the supported details attached to the status of the error,
+// unsupported or malformed details are skipped.
+func Details(err error) []Detail {
+	s, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	var details []Detail
+	for _, a := range s.Proto().GetDetails() {
+		d, ok, err := fromAny(a)
+		if !ok || err != nil {
+			continue
+		}
+		details = append(details, d)
+	}
+
+	return details
+}
+
+func newStatus(code codes.Code, message string, details ...Detail) *status.Status {
+	anys := make([]*anypb.Any, 0, len(details))
+	for _, d := range details {
+		anys = append(anys, toAny(d))
+	}
+
+	return status.FromProto(&spb.Status{
+		Code:    int32(code),
+		Message: message,
+		Details: anys,
+	})
+}
+
+// codeOfKind returns the gRPC code of the error kind, Internal and false if the kind is nil or unknown.
+func codeOfKind(kind error) (codes.Code, bool) {
+	for _, kc := range kindCodes {
+		if errors.Is(kind, kc.kind) {
+			return kc.code, true
+		}
+	}
+
+	return codes.Internal, false
+}
+
+// messageOfCode returns the generic message of the kind mapped to the gRPC code.
+func messageOfCode(code codes.Code) string {
+	for _, kc := range kindCodes {
+		if kc.code == code {
+			return kc.message
+		}
+	}
+
+	return code.String()
+}
+
+// KindOfCode returns the error kind of the gRPC code, the reverse of the mapping of the interceptor,
+// or nil if no kind maps to the code.
+func KindOfCode(code codes.Code) error {
+	for _, kc := range kindCodes {
+		if kc.code == code {
+			return kc.kind
+		}
+	}
+
+	return nil
+}
+
+// reasonOfCode returns the code name in UPPER_SNAKE_CASE, e.g. INVALID_ARGUMENT.
+func reasonOfCode(code codes.Code) string {
+	name := code.String()
+
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+
+	return strings.ToUpper(b.String())
+}
@@ -0,0 +1,31 @@
+package grpcerr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestKindOfCode(t *testing.T) {
+	for _, kc := range kindCodes {
+		assert.Equal(t, kc.kind, KindOfCode(kc.code), kc.code.String())
+		code, ok := codeOfKind(KindOfCode(kc.code))
+		assert.True(t, ok, kc.code.String())
+		assert.Equal(t, kc.code, code, kc.code.String())
+	}
+
+	assert.Nil(t, KindOfCode(codes.Internal))
+	assert.Nil(t, KindOfCode(codes.OK))
+}
+
+func TestUnknownKind(t *testing.T) {
+	for name, kind := range map[string]error{"nil": nil, "foreign": errors.New("pq: password authentication failed")} {
+		t.Run(name, func(t *testing.T) {
+			s := New(kind, "DB_FAILED", "pq: password authentication failed").GRPCStatus()
+			assert.Equal(t, codes.Internal, s.Code())
+			assert.Equal(t, internalMessage, s.Message(), "Must redact the message")
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+)
+
+const (
+	// internalMessage is the message returned to the caller instead of the unknown error,
+	// same as the message returned by the Recovery interceptor.
+	internalMessage = "InternalServerError"
+
+	// MetaKeyRequestID is the ErrorInfo metadata key of the request ID,
+	// the callers could report it to find the logs of a redacted internal error.
+	MetaKeyRequestID = "request_id"
+)
+
+// UnaryServerInterceptor returns a unary interceptor that maps the errors returned by the handlers into
+// gRPC statuses, the domain is used as the ErrorInfo domain, usually the service name.
+//
+// NOTE: Must be chained after the Entry interceptor, because it reads the request context.
+func UnaryServerInterceptor(domain string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		return resp, ToStatus(ctx, domain, err).Err()
+	}
+}
+
+// ToStatus converts the error into a gRPC status.
+//
+// Errors of type *Error of the Err* kinds are converted with their details, errors which already carry a gRPC status
+// are returned as-is, and errors wrapping one of the Err* kinds or a context error are mapped to the relevant code,
+// with a generic message of the kind, the full error is logged.
+// Other errors, including the *Error of the other kinds, and the Internal and Unknown statuses which could carry
+// internal messages, are logged and converted
+// into an Internal status, the message is redacted and the request ID is returned in the ErrorInfo detail instead.
+func ToStatus(ctx context.Context, domain string, err error) *status.Status {
+	code, ok := classify(err)
+	if !ok {
+		return redact(ctx, domain, err)
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.status(domain)
+	}
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	switch code {
+	case codes.DeadlineExceeded:
+		return status.New(code, "Deadline exceeded")
+	case codes.Canceled:
+		return status.New(code, "Canceled by caller")
+	}
+
+	// The message of the wrapping error could carry internal details, e.g. of a DB error
+	coremiddleware.Logger(ctx).WithError(err).Info("Replaced the message of the domain error")
+
+	return newStatus(code, messageOfCode(code), ErrorInfo{
+		Reason: reasonOfCode(code),
+		Domain: domain,
+	})
+}
+
+// Code returns the gRPC code of the status ToStatus converts the error into, OK if nil,
+// e.g. for the metrics of the interceptors chained after the UnaryServerInterceptor.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+
+	code, _ := classify(err)
+
+	return code
+}
+
+// classify returns the gRPC code of the error as described in ToStatus, and false if the error is redacted
+// into an Internal status.
+func classify(err error) (codes.Code, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return codeOfKind(e.Kind)
+	}
+
+	if s, ok := status.FromError(err); ok {
+		if s.Code() == codes.Internal || s.Code() == codes.Unknown {
+			return codes.Internal, false
+		}
+
+		return s.Code(), true
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded, true
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled, true
+	}
+
+	for _, kc := range kindCodes {
+		if errors.Is(err, kc.kind) {
+			return kc.code, true
+		}
+	}
+
+	return codes.Internal, false
+}
+
+// redact logs the error and returns the Internal status with the request ID instead of the message of the error.
+func redact(ctx context.Context, domain string, err error) *status.Status {
+	requestID := coremiddleware.RequestContext(ctx).RequestID()
+	coremiddleware.Logger(ctx).WithError(err).Error("Caught unknown error in request")
+
+	return newStatus(codes.Internal, internalMessage, ErrorInfo{
+		Reason:   reasonOfCode(codes.Internal),
+		Domain:   domain,
+		Metadata: map[string]string{MetaKeyRequestID: requestID},
+	})
+}
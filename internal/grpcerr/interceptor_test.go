@@ -0,0 +1,155 @@
+package grpcerr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedCode    codes.Code
+		expectedMessage string
+		expectedDetails []Detail
+	}{
+		{
+			name:            "domain error",
+			err:             NewBadRequest("Bad request", FieldViolation{Field: "message", Description: "required"}),
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "Bad request",
+			expectedDetails: []Detail{
+				ErrorInfo{Reason: "INVALID_ARGUMENT", Domain: "test-service"},
+				BadRequest{FieldViolations: []FieldViolation{{Field: "message", Description: "required"}}},
+			},
+		},
+		{
+			name: "wrapped domain error with custom reason",
+			err: fmt.Errorf("wrapped: %w", New(ErrResourceExhausted, "QUOTA_EXCEEDED", "Quota exceeded").
+				WithRetryDelay(30e9).
+				WithLocalizedMessage("en-US", "Try again later")),
+			expectedCode:    codes.ResourceExhausted,
+			expectedMessage: "Quota exceeded",
+			expectedDetails: []Detail{
+				ErrorInfo{Reason: "QUOTA_EXCEEDED", Domain: "test-service"},
+				RetryInfo{RetryDelay: 30e9},
+				LocalizedMessage{Locale: "en-US", Message: "Try again later"},
+			},
+		},
+		{
+			name:            "wrapped error kind",
+			err:             fmt.Errorf("device %q: %w", "abc", ErrNotFound),
+			expectedCode:    codes.NotFound,
+			expectedMessage: "Not found",
+			expectedDetails: []Detail{
+				ErrorInfo{Reason: "NOT_FOUND", Domain: "test-service"},
+			},
+		},
+		{
+			name:            "wrapped error kind with internal details",
+			err:             fmt.Errorf("select failed: %v: %w", errors.New("dial tcp 10.0.0.5:5432: i/o timeout"), ErrUnavailable),
+			expectedCode:    codes.Unavailable,
+			expectedMessage: "Unavailable",
+			expectedDetails: []Detail{
+				ErrorInfo{Reason: "UNAVAILABLE", Domain: "test-service"},
+			},
+		},
+		{
+			name:            "status error",
+			err:             status.Error(codes.Unavailable, "Try later"),
+			expectedCode:    codes.Unavailable,
+			expectedMessage: "Try later",
+		},
+		{
+			name:            "context error",
+			err:             fmt.Errorf("query: %w", context.DeadlineExceeded),
+			expectedCode:    codes.DeadlineExceeded,
+			expectedMessage: "Deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnaryServerInterceptor("test-service")(context.Background(), nil, &grpc.UnaryServerInfo{},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return nil, tt.err
+				})
+
+			s, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedCode, s.Code())
+			assert.Equal(t, tt.expectedCode, Code(tt.err), "Must return the code of the status")
+			assert.Equal(t, tt.expectedMessage, s.Message())
+			assert.Equal(t, tt.expectedDetails, Details(err))
+		})
+	}
+
+	unknownErrors := []struct {
+		name string
+		err  error
+	}{
+		{name: "unknown error is redacted", err: errors.New("pq: password authentication failed")},
+		{name: "internal status is redacted", err: status.Error(codes.Internal, "pq: password authentication failed")},
+		{name: "unknown status is redacted", err: status.Error(codes.Unknown, "pq: password authentication failed")},
+		{name: "error without kind is redacted", err: New(nil, "DB_FAILED", "pq: password authentication failed")},
+		{name: "error of foreign kind is redacted", err: fmt.Errorf("query: %w", New(errors.New("pq"), "", "pq: password authentication failed"))},
+	}
+	for _, tt := range unknownErrors {
+		t.Run(tt.name, func(t *testing.T) {
+			testRedacted(t, tt.err)
+			assert.Equal(t, codes.Internal, Code(tt.err))
+		})
+	}
+
+	t.Run("no error", func(t *testing.T) {
+		resp, err := UnaryServerInterceptor("test-service")(context.Background(), nil, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "ok", nil
+			})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+}
+
+// testRedacted asserts the error returned by the handler is logged, and redacted in the status.
+func testRedacted(t *testing.T, handlerErr error) {
+	b := bytes.NewBuffer(nil)
+	l := logrus.New()
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.SetOutput(b)
+
+	ctx := coremiddleware.NewContextWithLogger(context.Background(), logrus.NewEntry(l))
+	reqCtx := coremiddleware.BuildRequestContext(ctx, coremiddleware.EntryConfigs{})
+	ctx = coremiddleware.NewContextWithRequestCtx(ctx, reqCtx)
+
+	_, err := UnaryServerInterceptor("test-service")(ctx, nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		})
+
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, s.Code())
+	assert.Equal(t, "InternalServerError", s.Message())
+	assert.Equal(t, []Detail{
+		ErrorInfo{
+			Reason:   "INTERNAL",
+			Domain:   "test-service",
+			Metadata: map[string]string{MetaKeyRequestID: reqCtx.RequestID()},
+		},
+	}, Details(err))
+	assert.Contains(t, b.String(), "pq: password authentication failed")
+}
@@ -0,0 +1,134 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// idempotencyRecord is the database model of a Record.
+type idempotencyRecord struct {
+	Key         string `gorm:"column:idempotency_key;primaryKey;size:64"`
+	Fingerprint string `gorm:"size:64;not null"`
+	Token       string `gorm:"size:32;not null;default:''"`
+	Response    []byte
+	Completed   bool      `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+}
+
+func (idempotencyRecord) TableName() string {
+	return "idempotency_records"
+}
+
+// GormStore is a Store backed by the database, the records are shared across the instances.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a new store backed by the database, and migrates the table.
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.AutoMigrate(&idempotencyRecord{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate idempotency records: %w", err)
+	}
+
+	return &GormStore{db: db}, nil
+}
+
+// Reserve implements the Store interface.
+func (s *GormStore) Reserve(ctx context.Context, key, fingerprint string, lease time.Duration) (string, *Record, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	db := s.db.WithContext(ctx)
+
+	tx := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&idempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Token:       token,
+		ExpiresAt:   now.Add(lease),
+	})
+	if tx.Error != nil {
+		return "", nil, tx.Error
+	}
+	if tx.RowsAffected == 1 {
+		return token, nil, nil
+	}
+
+	// Take over the expired record, the condition makes sure only one request could take it over
+	tx = db.Model(&idempotencyRecord{}).
+		Where("idempotency_key = ? AND expires_at <= ?", key, now).
+		Updates(map[string]interface{}{
+			"fingerprint": fingerprint,
+			"token":       token,
+			"response":    nil,
+			"completed":   false,
+			"expires_at":  now.Add(lease),
+			"created_at":  now,
+		})
+	if tx.Error != nil {
+		return "", nil, tx.Error
+	}
+	if tx.RowsAffected == 1 {
+		return token, nil, nil
+	}
+
+	r := idempotencyRecord{}
+	if err := db.Where("idempotency_key = ?", key).Take(&r).Error; err != nil {
+		return "", nil, err
+	}
+
+	return "", &Record{
+		Fingerprint: r.Fingerprint,
+		Response:    r.Response,
+		Completed:   r.Completed,
+		ExpiresAt:   r.ExpiresAt,
+	}, nil
+}
+
+// Complete implements the Store interface.
+func (s *GormStore) Complete(ctx context.Context, key, token string, response []byte, ttl time.Duration) error {
+	tx := s.db.WithContext(ctx).Model(&idempotencyRecord{}).
+		Where("idempotency_key = ? AND token = ? AND completed = ?", key, token, false).
+		Updates(map[string]interface{}{
+			"response":   response,
+			"completed":  true,
+			"expires_at": time.Now().Add(ttl),
+		})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrNotReserved
+	}
+
+	return nil
+}
+
+// Release implements the Store interface.
+func (s *GormStore) Release(ctx context.Context, key, token string) error {
+	tx := s.db.WithContext(ctx).
+		Where("idempotency_key = ? AND token = ? AND completed = ?", key, token, false).
+		Delete(&idempotencyRecord{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return ErrNotReserved
+	}
+
+	return nil
+}
+
+// DeleteExpired implements the Sweeper interface.
+func (s *GormStore) DeleteExpired(ctx context.Context) (int64, error) {
+	tx := s.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&idempotencyRecord{})
+
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,192 @@
+// Package idempotency makes the mutating unary RPCs safe to retry, the first response of a request
+// with an `idempotency-key` metadata entry is stored and replayed for the retries with the same key.
+//
+// The keys are scoped by identity.Caller, which is the client IP of the anonymous callers, so the retry of an
+// anonymous caller from another address is processed again. Require authentication for the idempotent methods
+// whose duplicates are harmful.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/identity"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+const (
+	// MetaKeyIdempotencyKey represents the meta key to get the idempotency key from client's request.
+	MetaKeyIdempotencyKey = "idempotency-key"
+
+	// maxKeyLength is the maximum length of the idempotency key sent by the clients.
+	maxKeyLength = 256
+
+	// DefaultTTL is the default duration to keep the responses.
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultLease is the default duration to keep the key of a request in progress, the key of a request
+	// that never completes, e.g. its instance crashed, is reserved again after the lease.
+	// Must be longer than the timeout of the requests.
+	DefaultLease = 30 * time.Second
+
+	// storeTimeout is the timeout of persisting the outcome of a request, which outlives the request context.
+	storeTimeout = 5 * time.Second
+
+	// inProgressRetryDelay is the retry delay suggested to the clients when the first request is still in progress.
+	inProgressRetryDelay = time.Second
+)
+
+// Interceptor replays the stored responses of the requests with the same idempotency key.
+type Interceptor struct {
+	store   Store
+	lease   time.Duration
+	ttl     time.Duration
+	methods map[string]bool
+}
+
+// NewInterceptor returns a new interceptor for the given full method names, e.g. "/template.v1.Service/CreateItem".
+// The keys of the requests in progress are kept for the lease, and the responses for the ttl.
+func NewInterceptor(store Store, lease, ttl time.Duration, methods ...string) *Interceptor {
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	m := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		m[method] = true
+	}
+
+	return &Interceptor{
+		store:   store,
+		lease:   lease,
+		ttl:     ttl,
+		methods: m,
+	}
+}
+
+// UnaryServerInterceptor returns a unary interceptor that handles the idempotency keys.
+//
+// NOTE: Should be chained after the Timeout interceptor, otherwise a timed-out request
+// may still complete in the background after its key is released.
+func (i *Interceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !i.methods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		idemKey := idempotencyKeyFromIncomingMetadata(ctx)
+		if idemKey == "" {
+			return handler(ctx, req)
+		}
+		if len(idemKey) > maxKeyLength {
+			return nil, grpcerr.NewBadRequest("Idempotency key is too long", grpcerr.FieldViolation{
+				Field:       MetaKeyIdempotencyKey,
+				Description: fmt.Sprintf("must be at most %d bytes long", maxKeyLength),
+			})
+		}
+
+		fingerprint, err := fingerprintOf(req)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		l := coremiddleware.Logger(ctx)
+		key := storeKey(identity.Caller(ctx), info.FullMethod, idemKey)
+
+		token, existing, err := i.store.Reserve(ctx, key, fingerprint, i.lease)
+		if err != nil {
+			l.WithError(err).Error("Failed to reserve the idempotency key")
+
+			return nil, grpcerr.New(grpcerr.ErrUnavailable, "IDEMPOTENCY_STORE_UNAVAILABLE", "Idempotency store unavailable").
+				WithRetryDelay(inProgressRetryDelay)
+		}
+
+		if existing != nil {
+			return replay(existing, fingerprint)
+		}
+
+		resp, err := handler(ctx, req)
+
+		// The outcome is persisted even if the request is cancelled, otherwise the retries are aborted until the lease expires
+		storeCtx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+
+		if err != nil {
+			// Release the key, so the client could retry the failed request with the same key
+			if rErr := i.store.Release(storeCtx, key, token); rErr != nil {
+				l.WithError(rErr).Warn("Failed to release the idempotency key")
+			}
+
+			return resp, err
+		}
+
+		if b, mErr := protoutil.MarshalAny(resp); mErr != nil {
+			l.WithError(mErr).Warn("Failed to marshal the response for the idempotency key")
+			_ = i.store.Release(storeCtx, key, token)
+		} else if cErr := i.store.Complete(storeCtx, key, token, b, i.ttl); cErr != nil {
+			l.WithError(cErr).Warn("Failed to store the response of the idempotency key")
+		}
+
+		return resp, nil
+	}
+}
+
+func replay(r *Record, fingerprint string) (interface{}, error) {
+	if r.Fingerprint != fingerprint {
+		return nil, grpcerr.New(grpcerr.ErrFailedPrecondition, "IDEMPOTENCY_KEY_REUSED",
+			"Idempotency key was used with a different request payload")
+	}
+
+	if !r.Completed {
+		return nil, grpcerr.New(grpcerr.ErrAborted, "IDEMPOTENCY_KEY_IN_PROGRESS",
+			"A request with the same idempotency key is in progress").
+			WithRetryDelay(inProgressRetryDelay)
+	}
+
+	return protoutil.UnmarshalAny(r.Response)
+}
+
+func idempotencyKeyFromIncomingMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	v := md.Get(MetaKeyIdempotencyKey)
+	if len(v) == 0 {
+		return ""
+	}
+
+	return v[0]
+}
+
+// storeKey hashes the caller, method and idempotency key into a fixed length key.
+func storeKey(caller, method, idemKey string) string {
+	h := sha256.New()
+	for _, s := range []string{caller, method, idemKey} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func fingerprintOf(req interface{}) (string, error) {
+	sum, err := protoutil.Hash(req)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sum), nil
+}
@@ -0,0 +1,150 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	"github.com/sliide/template-grpc-service/internal/identity"
+)
+
+const testMethod = "/template.v1.Echo/UnaryEcho"
+
+func TestInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+	newCtx := func(key string) context.Context {
+		ctx := identity.NewContext(context.Background(), "caller")
+
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(MetaKeyIdempotencyKey, key))
+	}
+
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+
+		return &templatev1.UnaryEchoResponse{Message: req.(*templatev1.UnaryEchoRequest).GetMessage()}, nil
+	}
+
+	t.Run("Replays the first response", func(t *testing.T) {
+		calls = 0
+		interceptor := NewInterceptor(NewMemoryStore(), time.Minute, time.Hour, testMethod).UnaryServerInterceptor()
+
+		resp, err := interceptor(newCtx("key-1"), &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", resp.(*templatev1.UnaryEchoResponse).GetMessage())
+
+		resp, err = interceptor(newCtx("key-1"), &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", resp.(*templatev1.UnaryEchoResponse).GetMessage())
+		assert.Equal(t, 1, calls, "Must not call the handler again")
+
+		_, err = interceptor(newCtx("key-2"), &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls, "Must call the handler with another key")
+	})
+
+	t.Run("Keys are scoped by caller", func(t *testing.T) {
+		calls = 0
+		interceptor := NewInterceptor(NewMemoryStore(), time.Minute, time.Hour, testMethod).UnaryServerInterceptor()
+
+		_, err := interceptor(newCtx("key"), &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+
+		ctx := identity.NewContext(newCtx("key"), "another-caller")
+		_, err = interceptor(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Conflicting payload", func(t *testing.T) {
+		interceptor := NewInterceptor(NewMemoryStore(), time.Minute, time.Hour, testMethod).UnaryServerInterceptor()
+
+		_, err := interceptor(newCtx("key"), &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+
+		_, err = interceptor(newCtx("key"), &templatev1.UnaryEchoRequest{Message: "bye"}, info, handler)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		store := NewMemoryStore()
+		interceptor := NewInterceptor(store, time.Minute, time.Hour, testMethod).UnaryServerInterceptor()
+		req := &templatev1.UnaryEchoRequest{Message: "hello"}
+
+		_, err := interceptor(newCtx("key"), req, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			_, err := interceptor(newCtx("key"), req, info, handler)
+			assert.Equal(t, codes.Aborted, status.Code(err))
+
+			return &templatev1.UnaryEchoResponse{}, nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Failed request releases the key", func(t *testing.T) {
+		calls = 0
+		interceptor := NewInterceptor(NewMemoryStore(), time.Minute, time.Hour, testMethod).UnaryServerInterceptor()
+
+		_, err := interceptor(newCtx("key"), &templatev1.UnaryEchoRequest{}, info, func(context.Context, interface{}) (interface{}, error) {
+			return nil, errors.New("failed")
+		})
+		require.Error(t, err)
+
+		_, err = interceptor(newCtx("key"), &templatev1.UnaryEchoRequest{}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Cancelled request stores the response", func(t *testing.T) {
+		calls = 0
+		interceptor := NewInterceptor(ctxStore{NewMemoryStore()}, time.Minute, time.Hour, testMethod).UnaryServerInterceptor()
+
+		ctx, cancel := context.WithCancel(newCtx("key"))
+		_, err := interceptor(ctx, &templatev1.UnaryEchoRequest{Message: "hello"}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			cancel()
+
+			return handler(ctx, req)
+		})
+		require.NoError(t, err)
+
+		resp, err := interceptor(newCtx("key"), &templatev1.UnaryEchoRequest{Message: "hello"}, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", resp.(*templatev1.UnaryEchoResponse).GetMessage())
+		assert.Equal(t, 1, calls, "Must replay the response of the cancelled request")
+	})
+
+	t.Run("Requests without key or other methods are not stored", func(t *testing.T) {
+		calls = 0
+		store := NewMemoryStore()
+		interceptor := NewInterceptor(store, time.Minute, time.Hour, testMethod).UnaryServerInterceptor()
+
+		_, err := interceptor(context.Background(), &templatev1.UnaryEchoRequest{}, info, handler)
+		require.NoError(t, err)
+		_, err = interceptor(newCtx("key"), &templatev1.UnaryEchoRequest{}, &grpc.UnaryServerInfo{FullMethod: "/other/Method"}, handler)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, store.records)
+	})
+}
+
+// ctxStore is a MemoryStore failing with the cancelled contexts, like the stores doing I/O.
+type ctxStore struct {
+	*MemoryStore
+}
+
+func (s ctxStore) Complete(ctx context.Context, key, token string, response []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.MemoryStore.Complete(ctx, key, token, response, ttl)
+}
@@ -0,0 +1,145 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotReserved is returned when completing or releasing a key which is not reserved with the token,
+// e.g. when the lease expired and the key was reserved again by another request.
+var ErrNotReserved = errors.New("idempotency key is not reserved")
+
+// Record represents the state of an idempotency key.
+type Record struct {
+	// Fingerprint is the hash of the request payload which reserved the key.
+	Fingerprint string
+	// Response is the serialized response, it's nil until the request is completed.
+	Response []byte
+	// Completed reports whether the request reserving the key has completed.
+	Completed bool
+	ExpiresAt time.Time
+
+	// token identifies the reservation in the MemoryStore.
+	token string
+}
+
+// Store persists the idempotency records.
+type Store interface {
+	// Reserve reserves the key for a request with the fingerprint if the key doesn't exist or is expired,
+	// returns the token of the reservation in this case, otherwise returns the existing record. The reservation
+	// expires after the lease, so a key of a request that never completed could be reserved again.
+	Reserve(ctx context.Context, key, fingerprint string, lease time.Duration) (token string, existing *Record, err error)
+
+	// Complete stores the response of the key reserved with the token, which is kept for the ttl.
+	Complete(ctx context.Context, key, token string, response []byte, ttl time.Duration) error
+
+	// Release deletes the key reserved with the token, so a request with the same key could be processed again.
+	Release(ctx context.Context, key, token string) error
+}
+
+// MemoryStore is an in-memory Store, the records are not shared across the instances.
+type MemoryStore struct {
+	m       sync.Mutex
+	records map[string]*Record
+
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// sweepInterval is the minimum interval between the sweeps of the expired records.
+const sweepInterval = time.Minute
+
+// NewMemoryStore returns a new in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*Record),
+		now:     time.Now,
+	}
+}
+
+// Reserve implements the Store interface.
+func (s *MemoryStore) Reserve(_ context.Context, key, fingerprint string, lease time.Duration) (string, *Record, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if r, ok := s.records[key]; ok && now.Before(r.ExpiresAt) {
+		existing := *r
+		existing.token = ""
+
+		return "", &existing, nil
+	}
+
+	s.records[key] = &Record{
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(lease),
+		token:       token,
+	}
+
+	return token, nil, nil
+}
+
+// Complete implements the Store interface.
+func (s *MemoryStore) Complete(_ context.Context, key, token string, response []byte, ttl time.Duration) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	r, ok := s.records[key]
+	if !ok || r.Completed || r.token != token {
+		return ErrNotReserved
+	}
+	r.Response = response
+	r.Completed = true
+	r.ExpiresAt = s.now().Add(ttl)
+
+	return nil
+}
+
+// Release implements the Store interface.
+func (s *MemoryStore) Release(_ context.Context, key, token string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	r, ok := s.records[key]
+	if !ok || r.Completed || r.token != token {
+		return ErrNotReserved
+	}
+	delete(s.records, key)
+
+	return nil
+}
+
+// sweep deletes the expired records, must be called with the lock held.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, r := range s.records {
+		if !now.Before(r.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
+
+// newToken returns a random token of a reservation.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
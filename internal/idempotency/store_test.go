@@ -0,0 +1,78 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	token, existing, err := s.Reserve(ctx, "key", "fp", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, existing, "Must reserve a new key")
+	assert.NotEmpty(t, token)
+
+	other, existing, err := s.Reserve(ctx, "key", "fp", time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, other)
+	assert.Equal(t, &Record{Fingerprint: "fp", ExpiresAt: now.Add(time.Minute)}, existing, "Must keep the reservation for the lease")
+
+	assert.ErrorIs(t, s.Complete(ctx, "key", "other", []byte("response"), time.Hour), ErrNotReserved, "Must not complete another reservation")
+	require.NoError(t, s.Complete(ctx, "key", token, []byte("response"), time.Hour))
+	assert.ErrorIs(t, s.Complete(ctx, "key", token, []byte("again"), time.Hour), ErrNotReserved)
+	assert.ErrorIs(t, s.Release(ctx, "key", token), ErrNotReserved, "Must not release a completed key")
+
+	_, existing, err = s.Reserve(ctx, "key", "other", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, &Record{Fingerprint: "fp", Response: []byte("response"), Completed: true, ExpiresAt: now.Add(time.Hour)}, existing,
+		"Must keep the response for the ttl")
+
+	t.Run("Expired key is reserved again", func(t *testing.T) {
+		now = now.Add(time.Hour)
+
+		_, existing, err := s.Reserve(ctx, "key", "new", time.Hour)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+	})
+
+	t.Run("Key is reserved again after the lease", func(t *testing.T) {
+		stale, _, err := s.Reserve(ctx, "abandoned", "fp", time.Minute)
+		require.NoError(t, err)
+
+		now = now.Add(time.Minute)
+		token, existing, err := s.Reserve(ctx, "abandoned", "fp", time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+
+		// The late outcome of the first request must not affect the new reservation
+		assert.ErrorIs(t, s.Complete(ctx, "abandoned", stale, []byte("late"), time.Hour), ErrNotReserved)
+		assert.ErrorIs(t, s.Release(ctx, "abandoned", stale), ErrNotReserved)
+		require.NoError(t, s.Complete(ctx, "abandoned", token, []byte("response"), time.Hour))
+	})
+
+	t.Run("Released key is reserved again", func(t *testing.T) {
+		token, _, err := s.Reserve(ctx, "released", "fp", time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, s.Release(ctx, "released", token))
+
+		_, existing, err := s.Reserve(ctx, "released", "fp", time.Hour)
+		require.NoError(t, err)
+		assert.Nil(t, existing)
+	})
+
+	t.Run("Expired records are swept", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+
+		_, _, err := s.Reserve(ctx, "another", "fp", time.Hour)
+		require.NoError(t, err)
+		assert.Len(t, s.records, 1)
+	})
+}
@@ -0,0 +1,53 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSweepInterval is the default interval between the deletions of the expired records.
+const DefaultSweepInterval = 10 * time.Minute
+
+// Sweeper is implemented by the stores whose expired records must be deleted periodically.
+type Sweeper interface {
+	// DeleteExpired deletes the expired records, returns the number of deleted records.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// StartSweeper deletes the expired records of the store every interval until the returned stop function is called.
+func StartSweeper(s Sweeper, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := s.DeleteExpired(ctx)
+				if err != nil {
+					logrus.WithError(err).Error("Failed to delete the expired idempotency records")
+					continue
+				}
+				logrus.WithField("deleted", n).Debug("Deleted the expired idempotency records")
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,32 @@
+package idempotency
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingSweeper struct {
+	calls int32
+}
+
+func (s *countingSweeper) DeleteExpired(context.Context) (int64, error) {
+	atomic.AddInt32(&s.calls, 1)
+
+	return 0, nil
+}
+
+func TestStartSweeper(t *testing.T) {
+	s := &countingSweeper{}
+	stop := StartSweeper(s, time.Millisecond)
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&s.calls) >= 2 }, time.Second, time.Millisecond)
+
+	stop()
+	calls := atomic.LoadInt32(&s.calls)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, calls, atomic.LoadInt32(&s.calls), "Must not sweep after stopped")
+}
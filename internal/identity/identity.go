@@ -0,0 +1,87 @@
+// Package identity carries the identity of the caller in the request context.
+package identity
+
+import (
+	"context"
+	"sync"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+)
+
+// anonymousPrefix is the prefix of the identity of the callers which are not authenticated.
+const anonymousPrefix = "anonymous:"
+
+type ctxIdentityKey struct{}
+
+type ctxClaimsKey struct{}
+
+type ctxTrackerKey struct{}
+
+// tracker records the identity set in the descendant contexts.
+type tracker struct {
+	m  sync.Mutex
+	id string
+}
+
+// Claims are the claims of the authenticated caller, e.g. the claims of its token.
+type Claims map[string]string
+
+// NewContext returns a new context which sets the authenticated identity of the caller.
+func NewContext(ctx context.Context, id string) context.Context {
+	if t, ok := ctx.Value(ctxTrackerKey{}).(*tracker); ok {
+		t.m.Lock()
+		t.id = id
+		t.m.Unlock()
+	}
+
+	return context.WithValue(ctx, ctxIdentityKey{}, id)
+}
+
+// Track returns a new context which records the identity set by NewContext in its descendant contexts,
+// and a function returning the Caller, for the interceptors chained before the authentication.
+func Track(ctx context.Context) (context.Context, func() string) {
+	t := &tracker{}
+
+	return context.WithValue(ctx, ctxTrackerKey{}, t), func() string {
+		t.m.Lock()
+		defer t.m.Unlock()
+
+		if t.id != "" {
+			return t.id
+		}
+
+		return Caller(ctx)
+	}
+}
+
+// FromContext returns the authenticated identity of the caller from the context.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxIdentityKey{}).(string)
+	if !ok || id == "" {
+		return "", false
+	}
+
+	return id, true
+}
+
+// NewContextWithClaims returns a new context which sets the claims of the authenticated caller.
+func NewContextWithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, ctxClaimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated caller from the context, nil if not set.
+func ClaimsFromContext(ctx context.Context) Claims {
+	claims, _ := ctx.Value(ctxClaimsKey{}).(Claims)
+
+	return claims
+}
+
+// Caller returns the identity of the caller, falls back to the remote address of the request context
+// when the caller is not authenticated, e.g. "anonymous:192.0.2.1".
+func Caller(ctx context.Context) string {
+	if id, ok := FromContext(ctx); ok {
+		return id
+	}
+
+	return anonymousPrefix + coremiddleware.RequestContext(ctx).RemoteAddr()
+}
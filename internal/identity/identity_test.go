@@ -0,0 +1,51 @@
+package identity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+)
+
+func TestCaller(t *testing.T) {
+	t.Run("Authenticated", func(t *testing.T) {
+		ctx := NewContext(context.Background(), "service-a")
+
+		id, ok := FromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "service-a", id)
+		assert.Equal(t, "service-a", Caller(ctx))
+	})
+
+	t.Run("Anonymous", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("X-Forwarded-For", "192.0.2.1"))
+		ctx = coremiddleware.NewContextWithRequestCtx(ctx, coremiddleware.BuildRequestContext(ctx, coremiddleware.EntryConfigs{}))
+
+		_, ok := FromContext(ctx)
+		assert.False(t, ok)
+		assert.Equal(t, "anonymous:192.0.2.1", Caller(ctx))
+	})
+}
+
+func TestTrack(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("X-Forwarded-For", "192.0.2.1"))
+	ctx = coremiddleware.NewContextWithRequestCtx(ctx, coremiddleware.BuildRequestContext(ctx, coremiddleware.EntryConfigs{}))
+
+	tracked, caller := Track(ctx)
+	assert.Equal(t, "anonymous:192.0.2.1", caller())
+
+	_ = NewContext(tracked, "service-a")
+	assert.Equal(t, "service-a", caller(), "Must return the identity set in the descendant context")
+	assert.Equal(t, "anonymous:192.0.2.1", Caller(tracked))
+}
+
+func TestClaims(t *testing.T) {
+	assert.Nil(t, ClaimsFromContext(context.Background()))
+
+	ctx := NewContextWithClaims(context.Background(), Claims{"tenant": "team-a"})
+	assert.Equal(t, Claims{"tenant": "team-a"}, ClaimsFromContext(ctx))
+	assert.Equal(t, "", ClaimsFromContext(context.Background())["tenant"], "Must be safe to read from nil claims")
+}
@@ -0,0 +1,157 @@
+// Package inflight keeps the registry of the calls currently running, so they can be inspected and cancelled.
+package inflight
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/identity"
+)
+
+// Kinds of the calls.
+const (
+	KindUnary  = "unary"
+	KindStream = "stream"
+)
+
+// Request is a call currently running.
+type Request struct {
+	RequestID string        `json:"request_id"`
+	Method    string        `json:"method"`
+	Kind      string        `json:"kind"`
+	StartTime time.Time     `json:"start_time"`
+	Elapsed   time.Duration `json:"elapsed"`
+	TraceID   string        `json:"trace_id"`
+	Caller    string        `json:"caller"`
+}
+
+// Registry keeps the calls currently running.
+type Registry struct {
+	m        sync.Mutex
+	requests map[string]*entry
+
+	now func() time.Time
+}
+
+type entry struct {
+	req    Request
+	cancel context.CancelFunc
+}
+
+// NewRegistry returns a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		requests: make(map[string]*entry),
+		now:      time.Now,
+	}
+}
+
+// List returns the calls currently running, from the oldest to the newest.
+func (r *Registry) List() []Request {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	now := r.now()
+	list := make([]Request, 0, len(r.requests))
+	for _, e := range r.requests {
+		req := e.req
+		req.Elapsed = now.Sub(req.StartTime)
+		list = append(list, req)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StartTime.Before(list[j].StartTime)
+	})
+
+	return list
+}
+
+// Cancel cancels the context of the call, the bool reports whether the call is found.
+func (r *Registry) Cancel(requestID string) bool {
+	r.m.Lock()
+	e, ok := r.requests[requestID]
+	r.m.Unlock()
+	if !ok {
+		return false
+	}
+
+	e.cancel()
+
+	return true
+}
+
+// register adds the call into the registry, returns the cancellable context of the call and the function
+// removing it from the registry.
+func (r *Registry) register(ctx context.Context, kind, fullMethod string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	reqCtx := coremiddleware.RequestContext(ctx)
+
+	e := &entry{
+		req: Request{
+			RequestID: reqCtx.RequestID(),
+			Method:    fullMethod,
+			Kind:      kind,
+			StartTime: r.now(),
+			TraceID:   reqCtx.TraceID(),
+			Caller:    identity.Caller(ctx),
+		},
+		cancel: cancel,
+	}
+
+	r.m.Lock()
+	r.requests[e.req.RequestID] = e
+	r.m.Unlock()
+
+	return ctx, func() {
+		r.m.Lock()
+		delete(r.requests, e.req.RequestID)
+		r.m.Unlock()
+		cancel()
+	}
+}
+
+// UnaryServerInterceptor returns a unary interceptor that registers the calls,
+// the interceptor does nothing if the registry is nil.
+//
+// NOTE: Must be chained after the Entry interceptor, because the calls are registered by their request IDs.
+func (r *Registry) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if r == nil {
+			return handler(ctx, req)
+		}
+
+		ctx, done := r.register(ctx, KindUnary, info.FullMethod)
+		defer done()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that registers the calls,
+// the interceptor does nothing if the registry is nil.
+func (r *Registry) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if r == nil {
+			return handler(srv, ss)
+		}
+
+		// Keep the request context of the stream, or build it once if the stream has no entry interceptor,
+		// so the request ID is stable
+		ctx := ss.Context()
+		ctx = coremiddleware.NewContextWithRequestCtx(ctx, coremiddleware.RequestContext(ctx))
+
+		ctx, done := r.register(ctx, KindStream, info.FullMethod)
+		defer done()
+
+		wrapped := grpcmiddleware.WrapServerStream(ss)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
+}
@@ -0,0 +1,101 @@
+package inflight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/identity"
+)
+
+type testStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestRegistryUnaryServerInterceptor(t *testing.T) {
+	r := NewRegistry()
+	info := &grpc.UnaryServerInfo{FullMethod: "/template.v1.Echo/UnaryEcho"}
+
+	ctx := coremiddleware.NewContextWithRequestCtx(context.Background(),
+		coremiddleware.BuildRequestContext(context.Background(), coremiddleware.EntryConfigs{}))
+	ctx = identity.NewContext(ctx, "user-1")
+	requestID := coremiddleware.RequestContext(ctx).RequestID()
+
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := r.UnaryServerInterceptor()(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		})
+		done <- err
+	}()
+	<-started
+
+	list := r.List()
+	require.Len(t, list, 1)
+	assert.Equal(t, requestID, list[0].RequestID)
+	assert.Equal(t, info.FullMethod, list[0].Method)
+	assert.Equal(t, KindUnary, list[0].Kind)
+	assert.Equal(t, "user-1", list[0].Caller)
+	assert.Equal(t, coremiddleware.RequestContext(ctx).TraceID(), list[0].TraceID)
+
+	assert.False(t, r.Cancel("unknown"))
+	assert.True(t, r.Cancel(requestID))
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, r.List(), "Must remove the completed call")
+}
+
+func TestRegistryStreamServerInterceptor(t *testing.T) {
+	r := NewRegistry()
+	info := &grpc.StreamServerInfo{FullMethod: "/template.v1.Echo/ServerStreamingEcho"}
+
+	err := r.StreamServerInterceptor()(nil, &testStream{ctx: context.Background()}, info,
+		func(srv interface{}, ss grpc.ServerStream) error {
+			list := r.List()
+			require.Len(t, list, 1)
+			assert.Equal(t, KindStream, list[0].Kind)
+			assert.Equal(t, list[0].RequestID, coremiddleware.RequestContext(ss.Context()).RequestID(),
+				"Must keep the request ID in the stream context")
+
+			assert.True(t, r.Cancel(list[0].RequestID))
+			assert.ErrorIs(t, ss.Context().Err(), context.Canceled)
+
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Empty(t, r.List())
+}
+
+func TestRegistryList(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := NewRegistry()
+	r.now = func() time.Time { return now }
+
+	for _, id := range []string{"first", "second"} {
+		ctx := coremiddleware.NewContextWithRequestCtx(context.Background(),
+			coremiddleware.BuildRequestContext(context.Background(), coremiddleware.EntryConfigs{}))
+		_, done := r.register(ctx, KindUnary, "/test.Service/"+id)
+		defer done()
+		now = now.Add(time.Second)
+	}
+
+	list := r.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "/test.Service/first", list[0].Method)
+	assert.Equal(t, 2*time.Second, list[0].Elapsed)
+	assert.Equal(t, time.Second, list[1].Elapsed)
+}
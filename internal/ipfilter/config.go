@@ -0,0 +1,174 @@
+package ipfilter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sliide/template-grpc-service/internal/clientip"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+// Rules represents the CIDR allow and deny lists of a listener or a method, e.g. "10.0.0.0/8".
+type Rules struct {
+	// Allow only allows the client IPs in these networks if not empty.
+	Allow []string `yaml:"allow"`
+	// Deny blocks the client IPs in these networks, even if allowed.
+	Deny []string `yaml:"deny"`
+}
+
+// ListenerRules represents the rules of a listener, and optionally of its methods by their full names.
+type ListenerRules struct {
+	Rules   `yaml:",inline"`
+	Methods map[string]Rules `yaml:"methods"`
+}
+
+// Config maps the listener names to their rules, the calls of the listeners without rules are allowed.
+type Config map[string]ListenerRules
+
+// LoadFile loads the config from a YAML (or JSON) file in the following format.
+//
+//	public:
+//	  deny: [192.0.2.0/24]
+//	internal:
+//	  allow: [10.0.0.0/8]
+//	  methods:
+//	    /template.v2.Echo/UnaryEcho:
+//	      allow: [10.1.0.0/16]
+func LoadFile(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IP filter config: %w", err)
+	}
+
+	return Parse(b)
+}
+
+// Parse parses the config from YAML (or JSON) content, see LoadFile for the format.
+func Parse(b []byte) (Config, error) {
+	cfg := Config{}
+
+	// The unknown keys are rejected, a misspelled allow list would silently allow every client IP
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse IP filter config: %w", err)
+	}
+
+	// Validate the networks before the config is used
+	if _, err := compile(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// lists represents the parsed allow and deny lists.
+type lists struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// allowed returns true if the IP is not denied, and allowed if there is an allow list.
+func (l lists) allowed(ip net.IP) bool {
+	if ip != nil && contains(l.deny, ip) {
+		return false
+	}
+	if len(l.allow) == 0 {
+		return true
+	}
+
+	return ip != nil && contains(l.allow, ip)
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listenerPolicy represents the parsed rules of a listener.
+type listenerPolicy struct {
+	lists
+	methods map[string]lists
+}
+
+// policy maps the listener names to their parsed rules.
+type policy map[string]listenerPolicy
+
+func compile(cfg Config) (policy, error) {
+	p := make(policy, len(cfg))
+	for listener, rules := range cfg {
+		l, err := compileRules(rules.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP filter rules of the %s listener: %w", listener, err)
+		}
+
+		lp := listenerPolicy{
+			lists:   l,
+			methods: make(map[string]lists, len(rules.Methods)),
+		}
+		for method, r := range rules.Methods {
+			ml, err := compileRules(r)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IP filter rules of %s on the %s listener: %w", method, listener, err)
+			}
+			lp.methods[method] = ml
+		}
+		p[listener] = lp
+	}
+
+	return p, nil
+}
+
+// check returns an error if the policy has rules of a listener not in the given names, or of a method which isn't
+// registered.
+func (p policy) check(listeners []string) error {
+	known := make(map[string]bool, len(listeners))
+	for _, name := range listeners {
+		known[name] = true
+	}
+
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("invalid IP filter rules: unknown listener %s", name)
+		}
+		for method := range p[name].methods {
+			if _, err := protoutil.MethodDescriptor(method); err != nil {
+				return fmt.Errorf("invalid IP filter rules of %s on the %s listener: %w", method, name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func compileRules(r Rules) (lists, error) {
+	allow, err := clientip.ParseCIDRs(r.Allow)
+	if err != nil {
+		return lists{}, err
+	}
+
+	deny, err := clientip.ParseCIDRs(r.Deny)
+	if err != nil {
+		return lists{}, err
+	}
+
+	return lists{allow: allow, deny: deny}, nil
+}
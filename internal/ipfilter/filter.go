@@ -0,0 +1,169 @@
+// Package ipfilter enforces the CIDR allow and deny lists of the listeners and their methods.
+package ipfilter
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/filewatch"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+var blockedCalls = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "grpc_ipfilter_blocked_total",
+		Help: "Total number of calls blocked by the IP allow and deny lists.",
+	},
+	[]string{"listener", "grpc_service", "grpc_method"},
+)
+
+// Filter enforces the allow and deny lists, which can be replaced while serving.
+type Filter struct {
+	policy atomic.Value // policy
+
+	m sync.Mutex
+	// listeners are the names of the listeners the configs are checked against, once set by Check.
+	listeners []string
+}
+
+// NewFilter returns a new filter of the given config.
+func NewFilter(cfg Config) (*Filter, error) {
+	f := &Filter{}
+	if err := f.Update(cfg); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Update replaces the config of the filter, the previous config is kept if the new one is invalid.
+func (f *Filter) Update(cfg Config) error {
+	p, err := compile(cfg)
+	if err != nil {
+		return err
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.listeners != nil {
+		if err := p.check(f.listeners); err != nil {
+			return err
+		}
+	}
+	f.policy.Store(p)
+
+	return nil
+}
+
+// Check returns an error if the config has rules of a listener not in the given names, or of a method which isn't
+// registered, so a typo fails instead of disabling the rules silently. The configs updated afterwards are checked
+// against the same listeners, nothing is checked if the filter is nil.
+func (f *Filter) Check(listeners ...string) error {
+	if f == nil {
+		return nil
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	p, _ := f.policy.Load().(policy)
+	if err := p.check(listeners); err != nil {
+		return err
+	}
+	f.listeners = listeners
+
+	return nil
+}
+
+// Allowed returns true if the client IP is allowed to call the method on the listener,
+// both the rules of the listener and of the method must allow it.
+func (f *Filter) Allowed(listener, fullMethod string, ip net.IP) bool {
+	p, _ := f.policy.Load().(policy)
+	lp, ok := p[listener]
+	if !ok {
+		return true
+	}
+	if !lp.allowed(ip) {
+		return false
+	}
+	if ml, ok := lp.methods[fullMethod]; ok {
+		return ml.allowed(ip)
+	}
+
+	return true
+}
+
+// Watch reloads the config file when modified, checking it every interval until the stop channel is closed.
+// The previous config is kept if the file cannot be loaded.
+func (f *Filter) Watch(path string, interval time.Duration, stop <-chan struct{}) {
+	filewatch.Poll(path, interval, stop, func() error {
+		cfg, err := LoadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return f.Update(cfg)
+	})
+}
+
+// check returns a PermissionDenied error if the client IP of the request context is not allowed.
+func (f *Filter) check(ctx context.Context, listener, fullMethod string) error {
+	ip := net.ParseIP(coremiddleware.RequestContext(ctx).RemoteAddr())
+	if f.Allowed(listener, fullMethod, ip) {
+		return nil
+	}
+
+	service, method := protoutil.SplitMethodName(fullMethod)
+	blockedCalls.With(prometheus.Labels{
+		"listener":     listener,
+		"grpc_service": strings.ToLower(service),
+		"grpc_method":  strings.ToLower(method),
+	}).Inc()
+
+	return grpcerr.New(grpcerr.ErrPermissionDenied, "IP_BLOCKED", "Client address is not allowed")
+}
+
+// UnaryServerInterceptor returns a unary interceptor that blocks the calls of the listener from the client IPs
+// which are not allowed, the interceptor does nothing if the filter is nil.
+//
+// NOTE: Must be chained after the Entry interceptor, which resolves the client IP.
+func (f *Filter) UnaryServerInterceptor(listener string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if f == nil {
+			return handler(ctx, req)
+		}
+
+		if err := f.check(ctx, listener, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that blocks the streams of the listener from the client IPs
+// which are not allowed, the interceptor does nothing if the filter is nil.
+func (f *Filter) StreamServerInterceptor(listener string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if f == nil {
+			return handler(srv, ss)
+		}
+
+		if err := f.check(ss.Context(), listener, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,150 @@
+package ipfilter
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	_ "github.com/sliide/template-grpc-service/api/template/v2"
+)
+
+const testConfig = `
+public:
+  deny: [192.0.2.0/24]
+internal:
+  allow: [10.0.0.0/8]
+  deny: [10.0.0.66]
+  methods:
+    /test.Service/Delete:
+      allow: [10.1.0.0/16]
+`
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.0/24"}, cfg["public"].Deny)
+	assert.Equal(t, []string{"10.1.0.0/16"}, cfg["internal"].Methods["/test.Service/Delete"].Allow)
+
+	_, err = Parse([]byte("public:\n  deny: [invalid]\n"))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte("internal:\n  methods:\n    /test.Service/Delete:\n      allow: [10.0.0.0/33]\n"))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte("public:\n  alow: [10.0.0.0/8]\n"))
+	assert.Error(t, err, "Must reject the unknown keys")
+}
+
+func TestFilterCheck(t *testing.T) {
+	cfg, err := Parse([]byte("internal:\n  methods:\n    /template.v2.Echo/UnaryEcho:\n      allow: [10.0.0.0/8]\n"))
+	require.NoError(t, err)
+	f, err := NewFilter(cfg)
+	require.NoError(t, err)
+
+	assert.Error(t, f.Check("public"), "Must reject the unknown listeners")
+	require.NoError(t, f.Check("public", "internal"))
+
+	assert.Error(t, f.Update(Config{"publc": {}}), "Must check the updates against the listeners")
+	assert.Error(t, f.Update(Config{"internal": {Methods: map[string]Rules{"/template.v2.Echo/UnaryEko": {}}}}),
+		"Must reject the unknown methods")
+	assert.NoError(t, f.Update(Config{"public": {}}))
+
+	var nilFilter *Filter
+	assert.NoError(t, nilFilter.Check("public"))
+}
+
+func TestFilterAllowed(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	require.NoError(t, err)
+	f, err := NewFilter(cfg)
+	require.NoError(t, err)
+
+	tests := []struct {
+		listener string
+		method   string
+		ip       string
+		expected bool
+	}{
+		{listener: "public", method: "/test.Service/Get", ip: "198.51.100.1", expected: true},
+		{listener: "public", method: "/test.Service/Get", ip: "192.0.2.1", expected: false},
+		{listener: "public", method: "/test.Service/Get", ip: "", expected: true},
+		{listener: "internal", method: "/test.Service/Get", ip: "10.2.0.1", expected: true},
+		{listener: "internal", method: "/test.Service/Get", ip: "10.0.0.66", expected: false},
+		{listener: "internal", method: "/test.Service/Get", ip: "198.51.100.1", expected: false},
+		{listener: "internal", method: "/test.Service/Get", ip: "", expected: false},
+		{listener: "internal", method: "/test.Service/Delete", ip: "10.1.0.1", expected: true},
+		{listener: "internal", method: "/test.Service/Delete", ip: "10.2.0.1", expected: false},
+		{listener: "other", method: "/test.Service/Delete", ip: "192.0.2.1", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.listener+" "+tt.method+" "+tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.expected, f.Allowed(tt.listener, tt.method, net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	cfg, err := Parse([]byte(testConfig))
+	require.NoError(t, err)
+	f, err := NewFilter(cfg)
+	require.NoError(t, err)
+
+	call := func(f *Filter, remoteAddr string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", remoteAddr))
+		ctx = coremiddleware.NewContextWithRequestCtx(ctx, coremiddleware.BuildRequestContext(ctx, coremiddleware.EntryConfigs{}))
+
+		_, err := f.UnaryServerInterceptor("public")(ctx, "req", &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "resp", nil
+			})
+
+		return err
+	}
+
+	assert.NoError(t, call(f, "198.51.100.1"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(call(f, "192.0.2.1")))
+	assert.NoError(t, call(nil, "192.0.2.1"), "Must allow all calls without a filter")
+}
+
+func TestFilterWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ipfilter.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("public:\n  deny: [192.0.2.0/24]\n"), 0o600))
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	f, err := NewFilter(cfg)
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go f.Watch(path, time.Millisecond, stop)
+
+	blocked := net.ParseIP("192.0.2.1")
+	assert.False(t, f.Allowed("public", "/test.Service/Get", blocked))
+
+	// Keep the previous config if invalid
+	require.NoError(t, ioutil.WriteFile(path, []byte("public:\n  deny: [invalid]\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, f.Allowed("public", "/test.Service/Get", blocked))
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("public:\n  deny: [198.51.100.0/24]\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+	assert.Eventually(t, func() bool {
+		return f.Allowed("public", "/test.Service/Get", blocked)
+	}, time.Second, time.Millisecond)
+	assert.False(t, f.Allowed("public", "/test.Service/Get", net.ParseIP("198.51.100.1")))
+}
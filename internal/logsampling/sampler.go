@@ -0,0 +1,210 @@
+// Package logsampling samples the repeated log messages, so a failing dependency cannot flood the log output
+// with identical messages.
+package logsampling
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FieldSuppressedCount is the field of the summary logs with the number of the suppressed messages.
+	FieldSuppressedCount = "suppressed_count"
+
+	// DefaultInterval is the default interval of the sampling windows and the summaries.
+	DefaultInterval = time.Minute
+
+	// maxKeys bounds the number of the messages tracked in a window, the others are not sampled.
+	maxKeys = 10000
+)
+
+var suppressedLogs = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Subsystem: "logrus",
+		Name:      "logs_suppressed_total",
+		Help:      "Total number of logs suppressed by the sampling.",
+	},
+	[]string{"level"},
+)
+
+// Params represents the parameters of the sampling.
+type Params struct {
+	// First is the number of the messages with the same level and message kept in a window.
+	First int
+	// Thereafter keeps one in every Thereafter messages after the First, none if zero.
+	Thereafter int
+	// Interval is the duration of the sampling windows, the DefaultInterval is used if zero.
+	Interval time.Duration
+}
+
+// Sampler is a logrus formatter wrapper sampling the messages by (level, message) in every window,
+// the first N are kept and then one in M. A summary of the suppressed messages is logged at the end of the windows.
+//
+// The suppressed messages are counted in the `logrus_logs_suppressed_total` metric by level.
+//
+// NOTE: The hooks are fired before the formatter, so the suppressed messages are still counted
+// by the `prometheus.NewLogsMetrics` hook, which must be wrapped by ExcludeSummaries not to count them twice.
+type Sampler struct {
+	logrus.Formatter
+
+	logger     *logrus.Logger
+	first      uint64
+	thereafter uint64
+
+	m      sync.Mutex
+	counts map[sampleKey]*sampleCount
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type sampleKey struct {
+	level   logrus.Level
+	message string
+}
+
+type sampleCount struct {
+	total      uint64
+	suppressed uint64
+}
+
+// Wrap wraps the formatter of the logger with a sampler, and starts logging the summaries.
+func Wrap(logger *logrus.Logger, p Params) *Sampler {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	s := &Sampler{
+		Formatter:  logger.Formatter,
+		logger:     logger,
+		first:      uint64(p.First),
+		thereafter: uint64(p.Thereafter),
+		counts:     make(map[sampleKey]*sampleCount),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	logger.SetFormatter(s)
+
+	go s.run(interval)
+
+	return s
+}
+
+// Format implements the logrus.Formatter interface, returns no bytes for the suppressed messages.
+func (s *Sampler) Format(entry *logrus.Entry) ([]byte, error) {
+	if s.sampled(entry) {
+		return s.Formatter.Format(entry)
+	}
+
+	return nil, nil
+}
+
+// ExcludeSummaries wraps a hook, e.g. the `prometheus.NewLogsMetrics` hook, so it's not fired for the summary logs,
+// whose suppressed messages already fired the hook.
+func ExcludeSummaries(hook logrus.Hook) logrus.Hook {
+	return summaryExcluder{hook}
+}
+
+type summaryExcluder struct {
+	logrus.Hook
+}
+
+// Fire implements the logrus.Hook interface.
+func (h summaryExcluder) Fire(entry *logrus.Entry) error {
+	if isSummary(entry) {
+		return nil
+	}
+
+	return h.Hook.Fire(entry)
+}
+
+func isSummary(entry *logrus.Entry) bool {
+	_, ok := entry.Data[FieldSuppressedCount]
+
+	return ok
+}
+
+// Stop stops the sampling windows, and logs the summary of the last window, it does nothing if the sampler is nil.
+func (s *Sampler) Stop() {
+	if s == nil {
+		return
+	}
+
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Sampler) sampled(entry *logrus.Entry) bool {
+	if entry.Level <= logrus.FatalLevel {
+		return true
+	}
+	if isSummary(entry) {
+		return true
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	key := sampleKey{level: entry.Level, message: entry.Message}
+	c, ok := s.counts[key]
+	if !ok {
+		if len(s.counts) >= maxKeys {
+			return true
+		}
+		c = &sampleCount{}
+		s.counts[key] = c
+	}
+
+	c.total++
+	if c.total <= s.first || (s.thereafter > 0 && (c.total-s.first)%s.thereafter == 0) {
+		return true
+	}
+
+	c.suppressed++
+	suppressedLogs.WithLabelValues(strings.ToLower(entry.Level.String())).Inc()
+
+	return false
+}
+
+func (s *Sampler) run(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			s.summarize()
+
+			return
+		case <-ticker.C:
+			s.summarize()
+		}
+	}
+}
+
+// summarize logs the summary of the suppressed messages, and starts a new window.
+func (s *Sampler) summarize() {
+	s.m.Lock()
+	counts := s.counts
+	s.counts = make(map[sampleKey]*sampleCount)
+	s.m.Unlock()
+
+	for key, c := range counts {
+		if c.suppressed == 0 {
+			continue
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			FieldSuppressedCount: c.suppressed,
+			"suppressed_message": key.message,
+		}).Logf(key.level, "Suppressed %d messages: %s", c.suppressed, key.message)
+	}
+}
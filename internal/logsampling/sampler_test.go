@@ -0,0 +1,111 @@
+package logsampling
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sliide/template-grpc-service/internal/metrictest"
+)
+
+func newTestLogger() (*logrus.Logger, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+
+	return logger, buf
+}
+
+func TestSampler(t *testing.T) {
+	logger, buf := newTestLogger()
+	s := Wrap(logger, Params{First: 2, Thereafter: 3, Interval: time.Hour})
+
+	for i := 0; i < 10; i++ {
+		logger.Warn("Caught timeout while processing the request")
+	}
+	logger.Error("Caught timeout while processing the request")
+	logger.Warn("Another message")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2+2+1+1, "Must keep the first 2, then one in 3 (5th and 8th)")
+	assert.Contains(t, lines[4], "level=error", "Must sample by level")
+	assert.Contains(t, lines[5], "Another message")
+
+	buf.Reset()
+	s.Stop()
+
+	assert.Equal(t, `level=warning msg="Suppressed 6 messages: Caught timeout while processing the request" `+
+		`suppressed_count=6 suppressed_message="Caught timeout while processing the request"`+"\n", buf.String())
+}
+
+func TestSamplerNewWindow(t *testing.T) {
+	logger, buf := newTestLogger()
+	s := Wrap(logger, Params{First: 1, Interval: time.Hour})
+	defer s.Stop()
+
+	logger.Info("message")
+	logger.Info("message")
+	assert.Equal(t, 1, strings.Count(buf.String(), "msg=message"))
+
+	s.summarize()
+	assert.Contains(t, buf.String(), "Suppressed 1 messages: message")
+
+	logger.Info("message")
+	assert.Equal(t, 2, strings.Count(buf.String(), "msg=message"), "Must keep the first message of the new window")
+
+	buf.Reset()
+	s.summarize()
+	assert.Empty(t, buf.String(), "Must not summarize without suppressed messages")
+}
+
+type countingHook struct {
+	fired int
+}
+
+func (h *countingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *countingHook) Fire(*logrus.Entry) error {
+	h.fired++
+
+	return nil
+}
+
+func TestExcludeSummaries(t *testing.T) {
+	logger, _ := newTestLogger()
+	hook := &countingHook{}
+	logger.AddHook(ExcludeSummaries(hook))
+	s := Wrap(logger, Params{First: 1, Interval: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		logger.Info("message")
+	}
+	s.Stop()
+
+	assert.Equal(t, 3, hook.fired, "Must count the suppressed messages once, without the summary")
+}
+
+func TestSuppressedLogs(t *testing.T) {
+	logger, _ := newTestLogger()
+	s := Wrap(logger, Params{First: 1, Interval: time.Hour})
+	defer s.Stop()
+	suppressed := suppressedLogs.WithLabelValues("debug")
+	logger.SetLevel(logrus.DebugLevel)
+
+	before := metrictest.CounterValue(t, suppressed)
+	for i := 0; i < 3; i++ {
+		logger.Debug("message")
+	}
+
+	assert.Equal(t, before+2, metrictest.CounterValue(t, suppressed), "Must count the suppressed messages")
+}
+
+func TestStopNil(t *testing.T) {
+	assert.NotPanics(t, (*Sampler)(nil).Stop)
+}
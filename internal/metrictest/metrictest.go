@@ -0,0 +1,20 @@
+// Package metrictest reads the values of the Prometheus metrics in the tests.
+package metrictest
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+// CounterValue returns the current value of the counter.
+func CounterValue(t testing.TB, c prometheus.Counter) float64 {
+	t.Helper()
+
+	m := &dto.Metric{}
+	require.NoError(t, c.Write(m))
+
+	return m.GetCounter().GetValue()
+}
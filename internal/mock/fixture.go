@@ -0,0 +1,190 @@
+package mock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture represents the canned answer to the calls matching the method and the request.
+// The payloads are in the proto text format, as in the capture files.
+type Fixture struct {
+	// Name identifies the fixture, it's returned in the `mock-fixture` response header.
+	Name string `yaml:"name" json:"name"`
+	// Method is the full method name, or the service prefix ending with a slash, e.g. "/template.v2.Echo/".
+	Method string `yaml:"method" json:"method"`
+	// Request is the fields the requests must have, e.g. `message: "hello"`, all requests match if empty.
+	// The set fields are compared as a whole, the unset ones are ignored.
+	Request string `yaml:"request" json:"request"`
+
+	// Response is the response, the default response if empty.
+	Response string `yaml:"response" json:"response"`
+	// Responses are the responses of the server streaming methods, in order.
+	Responses []string `yaml:"responses" json:"responses"`
+	// Code is the gRPC code returned instead of the response, e.g. "NOT_FOUND".
+	Code string `yaml:"code" json:"code"`
+	// Message is the status message returned with the code.
+	Message string `yaml:"message" json:"message"`
+	// Latency delays the answer.
+	Latency time.Duration `yaml:"latency" json:"latency"`
+}
+
+// validate checks the fields of the fixture not depending on the method.
+func (f *Fixture) validate() error {
+	if f.Name == "" {
+		return errors.New("name is required")
+	}
+	if !strings.HasPrefix(f.Method, "/") {
+		return fmt.Errorf("%s: method must be a full method name or a service prefix", f.Name)
+	}
+	if f.Response != "" && len(f.Responses) > 0 {
+		return fmt.Errorf("%s: response and responses are mutually exclusive", f.Name)
+	}
+	if f.Code != "" && (f.Response != "" || len(f.Responses) > 0) {
+		return fmt.Errorf("%s: code and responses are mutually exclusive", f.Name)
+	}
+	if f.Latency < 0 {
+		return fmt.Errorf("%s: latency must not be negative", f.Name)
+	}
+
+	return nil
+}
+
+// matches returns true if the fixture applies to the method.
+func (f *Fixture) matches(fullMethod string) bool {
+	return f.Method == fullMethod || (strings.HasSuffix(f.Method, "/") && strings.HasPrefix(fullMethod, f.Method))
+}
+
+// Config represents the fixtures, in the following YAML (or JSON) format.
+// The first fixture matching a call answers it, thus the specific fixtures go before the generic ones.
+//
+//	fixtures:
+//	  - name: hello
+//	    method: /template.v2.Echo/UnaryEcho
+//	    request: 'message: "hello"'
+//	    response: 'message: "hello from the mock"'
+//	  - name: stream
+//	    method: /template.v2.Echo/ServerStreamingEcho
+//	    responses: ['message: "one"', 'message: "two"']
+//	  - name: v1-unavailable
+//	    method: /template.v1.Echo/
+//	    code: UNAVAILABLE
+//	    latency: 500ms
+type Config struct {
+	Fixtures []Fixture `yaml:"fixtures" json:"fixtures"`
+}
+
+// LoadFile loads the fixtures from a YAML (or JSON) file.
+func LoadFile(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read mock fixtures: %w", err)
+	}
+
+	cfg := Config{}
+
+	// The unknown fields are rejected, a misspelled field would silently answer with the default response
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("failed to parse mock fixtures: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// answer is a fixture resolved against the types of a method.
+type answer struct {
+	name      string
+	request   proto.Message
+	responses []proto.Message
+	err       error
+	latency   time.Duration
+}
+
+// resolve parses the payloads of the fixture into the request and response types of the method.
+func (f *Fixture) resolve(m *method) (answer, error) {
+	a := answer{name: f.Name, latency: f.Latency}
+
+	if f.Request != "" {
+		req, err := parse(m.input, f.Request)
+		if err != nil {
+			return answer{}, fmt.Errorf("%s: invalid request for %s: %w", f.Name, m.desc.FullName(), err)
+		}
+		a.request = req
+	}
+
+	if f.Code != "" {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(f.Code))); err != nil || code == codes.OK {
+			return answer{}, fmt.Errorf("%s: invalid code %q", f.Name, f.Code)
+		}
+		a.err = status.Error(code, f.Message)
+
+		return a, nil
+	}
+
+	responses := f.Responses
+	if f.Response != "" {
+		responses = []string{f.Response}
+	}
+	if len(responses) > 1 && !m.desc.IsStreamingServer() {
+		return answer{}, fmt.Errorf("%s: %s returns a single response", f.Name, m.desc.FullName())
+	}
+	for _, text := range responses {
+		res, err := parse(m.output, text)
+		if err != nil {
+			return answer{}, fmt.Errorf("%s: invalid response for %s: %w", f.Name, m.desc.FullName(), err)
+		}
+		a.responses = append(a.responses, res)
+	}
+	if len(a.responses) == 0 {
+		a.responses = []proto.Message{m.output.New().Interface()}
+	}
+
+	return a, nil
+}
+
+// matches returns true if the request has the fields of the fixture request.
+func (a *answer) matches(req proto.Message) bool {
+	if a.request == nil {
+		return true
+	}
+
+	// Clears the fields unset in the fixture, so only the set ones are compared
+	got := proto.Clone(req).ProtoReflect()
+	var unset []protoreflect.FieldDescriptor
+	got.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !a.request.ProtoReflect().Has(fd) {
+			unset = append(unset, fd)
+		}
+
+		return true
+	})
+	for _, fd := range unset {
+		got.Clear(fd)
+	}
+
+	return proto.Equal(a.request, got.Interface())
+}
+
+func parse(mt protoreflect.MessageType, text string) (proto.Message, error) {
+	m := mt.New().Interface()
+	if err := prototext.Unmarshal([]byte(text), m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
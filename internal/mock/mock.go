@@ -0,0 +1,190 @@
+// Package mock serves the services of the API answering from fixtures instead of their implementations, so the
+// consumers of the service could develop against it without running its dependencies, e.g. the database.
+// The calls matching no fixture get the default response of the method, the response with the default values.
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	protov1 "github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// MetaKeyFixture is the response header naming the fixture answering the call, unset for the default responses.
+const MetaKeyFixture = "mock-fixture"
+
+// method serves a method of the services from the fixtures.
+type method struct {
+	desc    protoreflect.MethodDescriptor
+	input   protoreflect.MessageType
+	output  protoreflect.MessageType
+	answers []answer
+}
+
+// Register registers the mocks of the services on the server, answering from the fixtures.
+// Returns an error if a fixture is invalid or matches none of the methods.
+func Register(server *grpc.Server, services []protoreflect.ServiceDescriptor, cfg Config) error {
+	for i := range cfg.Fixtures {
+		if err := cfg.Fixtures[i].validate(); err != nil {
+			return fmt.Errorf("invalid mock fixture: %w", err)
+		}
+	}
+
+	used := make([]bool, len(cfg.Fixtures))
+	for _, sd := range services {
+		desc := &grpc.ServiceDesc{
+			ServiceName: string(sd.FullName()),
+			Metadata:    sd.ParentFile().Path(),
+		}
+
+		for i := 0; i < sd.Methods().Len(); i++ {
+			m, err := newMethod(sd.Methods().Get(i))
+			if err != nil {
+				return err
+			}
+
+			fullMethod := fmt.Sprintf("/%s/%s", sd.FullName(), m.desc.Name())
+			for j := range cfg.Fixtures {
+				if !cfg.Fixtures[j].matches(fullMethod) {
+					continue
+				}
+				a, err := cfg.Fixtures[j].resolve(m)
+				if err != nil {
+					return fmt.Errorf("invalid mock fixture: %w", err)
+				}
+				m.answers = append(m.answers, a)
+				used[j] = true
+			}
+
+			// The unary methods are served as streams too, the wire format is the same
+			desc.Streams = append(desc.Streams, grpc.StreamDesc{
+				StreamName:    string(m.desc.Name()),
+				Handler:       m.handle,
+				ServerStreams: m.desc.IsStreamingServer(),
+				ClientStreams: m.desc.IsStreamingClient(),
+			})
+		}
+
+		server.RegisterService(desc, nil)
+	}
+
+	for i, ok := range used {
+		if !ok {
+			return fmt.Errorf("invalid mock fixture: %s: method %s not found", cfg.Fixtures[i].Name, cfg.Fixtures[i].Method)
+		}
+	}
+
+	return nil
+}
+
+func newMethod(desc protoreflect.MethodDescriptor) (*method, error) {
+	input, err := protoregistry.GlobalTypes.FindMessageByName(desc.Input().FullName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the request type of %s: %w", desc.FullName(), err)
+	}
+	output, err := protoregistry.GlobalTypes.FindMessageByName(desc.Output().FullName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the response type of %s: %w", desc.FullName(), err)
+	}
+
+	return &method{desc: desc, input: input, output: output}, nil
+}
+
+// handle answers the calls of the method: the bidirectional streams get an answer per request, the other calls get
+// a single answer to their first request.
+func (m *method) handle(_ interface{}, stream grpc.ServerStream) error {
+	if m.desc.IsStreamingClient() && m.desc.IsStreamingServer() {
+		for {
+			req, err := m.recv(stream)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := m.answer(stream, req); err != nil {
+				return err
+			}
+		}
+	}
+
+	req, err := m.recv(stream)
+	if err != nil {
+		return err
+	}
+	if m.desc.IsStreamingClient() {
+		// Drains the stream, the response is sent once the client closes it
+		for {
+			if _, err := m.recv(stream); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+
+	return m.answer(stream, req)
+}
+
+func (m *method) recv(stream grpc.ServerStream) (proto.Message, error) {
+	req := m.input.New().Interface()
+	if err := stream.RecvMsg(protov1.MessageV1(req)); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// answer sends the answer of the first fixture matching the request, or the default response.
+func (m *method) answer(stream grpc.ServerStream, req proto.Message) error {
+	for i := range m.answers {
+		a := &m.answers[i]
+		if !a.matches(req) {
+			continue
+		}
+
+		// The headers are sent with the first response, thus only the first answer of the streams is named
+		_ = stream.SetHeader(metadata.Pairs(MetaKeyFixture, a.name))
+		if err := sleep(stream.Context(), a.latency); err != nil {
+			return err
+		}
+		if a.err != nil {
+			return a.err
+		}
+		for _, res := range a.responses {
+			if err := stream.SendMsg(protov1.MessageV1(res)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return stream.SendMsg(protov1.MessageV1(m.output.New().Interface()))
+}
+
+// sleep waits for the duration, or returns the error of the context if it's done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
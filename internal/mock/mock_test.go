@@ -0,0 +1,227 @@
+package mock
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/sliide/template-grpc-service/api"
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+)
+
+func dialMock(t *testing.T, cfg Config) *grpc.ClientConn {
+	services, err := api.Services()
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	require.NoError(t, Register(s, services, cfg))
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func TestUnary(t *testing.T) {
+	conn := dialMock(t, Config{Fixtures: []Fixture{
+		{
+			Name:     "hello",
+			Method:   "/template.v2.Echo/UnaryEcho",
+			Request:  `message: "hello"`,
+			Response: `message: "hello from the mock"`,
+		},
+		{Name: "slow", Method: "/template.v2.Echo/UnaryEcho", Request: `message: "slow"`, Latency: time.Minute},
+		{Name: "v1-unavailable", Method: "/template.v1.Echo/", Code: "UNAVAILABLE", Message: "v1 is gone"},
+	}})
+	client := templatev2.NewEchoClient(conn)
+
+	tests := []struct {
+		name     string
+		req      *templatev2.UnaryEchoRequest
+		expected string
+		fixture  []string
+	}{
+		{name: "Matching request", req: &templatev2.UnaryEchoRequest{Message: "hello"}, expected: "hello from the mock", fixture: []string{"hello"}},
+		{name: "Ignored unset fields", req: &templatev2.UnaryEchoRequest{Message: "hello", UpperCase: true}, expected: "hello from the mock", fixture: []string{"hello"}},
+		{name: "Default response", req: &templatev2.UnaryEchoRequest{Message: "other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header metadata.MD
+			resp, err := client.UnaryEcho(context.Background(), tt.req, grpc.Header(&header))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.GetMessage())
+			assert.Equal(t, tt.fixture, header.Get(MetaKeyFixture))
+		})
+	}
+
+	t.Run("Canned error", func(t *testing.T) {
+		var header metadata.MD
+		_, err := templatev1.NewEchoClient(conn).UnaryEcho(context.Background(), &templatev1.UnaryEchoRequest{}, grpc.Header(&header))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, "v1 is gone", status.Convert(err).Message())
+		assert.Equal(t, []string{"v1-unavailable"}, header.Get(MetaKeyFixture))
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := client.UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: "slow"})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	})
+}
+
+func TestStreams(t *testing.T) {
+	conn := dialMock(t, Config{Fixtures: []Fixture{
+		{Name: "server", Method: "/template.v2.Echo/ServerStreamingEcho", Responses: []string{`message: "one"`, `message: "two"`}},
+		{Name: "client", Method: "/template.v2.Echo/ClientStreamingEcho", Request: `message: "first"`, Response: `message: "joined"`},
+		{Name: "bidi", Method: "/template.v2.Echo/BidirectionalStreamingEcho", Request: `message: "ping"`, Response: `message: "pong"`},
+	}})
+	client := templatev2.NewEchoClient(conn)
+
+	t.Run("Server streaming", func(t *testing.T) {
+		stream, err := client.ServerStreamingEcho(context.Background(), &templatev2.ServerStreamingEchoRequest{})
+		require.NoError(t, err)
+
+		var messages []string
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			messages = append(messages, resp.GetMessage())
+		}
+		assert.Equal(t, []string{"one", "two"}, messages)
+	})
+
+	t.Run("Client streaming", func(t *testing.T) {
+		stream, err := client.ClientStreamingEcho(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&templatev2.ClientStreamingEchoRequest{Message: "first"}))
+		require.NoError(t, stream.Send(&templatev2.ClientStreamingEchoRequest{Message: "second"}))
+
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+		assert.Equal(t, "joined", resp.GetMessage())
+	})
+
+	t.Run("Bidirectional streaming", func(t *testing.T) {
+		stream, err := client.BidirectionalStreamingEcho(context.Background())
+		require.NoError(t, err)
+
+		for _, tt := range []struct{ req, expected string }{{"ping", "pong"}, {"other", ""}, {"ping", "pong"}} {
+			require.NoError(t, stream.Send(&templatev2.BidirectionalStreamingEchoRequest{Message: tt.req}))
+			resp, err := stream.Recv()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.GetMessage(), tt.req)
+		}
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestRegisterInvalidFixtures(t *testing.T) {
+	services, err := api.Services()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		fixture Fixture
+	}{
+		{name: "Missing name", fixture: Fixture{Method: "/template.v2.Echo/UnaryEcho"}},
+		{name: "Relative method", fixture: Fixture{Name: "f", Method: "template.v2.Echo/UnaryEcho"}},
+		{name: "Unknown method", fixture: Fixture{Name: "f", Method: "/template.v2.Echo/Unknown"}},
+		{name: "Unknown field", fixture: Fixture{Name: "f", Method: "/template.v2.Echo/UnaryEcho", Request: `unknown: "x"`}},
+		{name: "Invalid response", fixture: Fixture{Name: "f", Method: "/template.v2.Echo/UnaryEcho", Response: `message: 1`}},
+		{name: "Multiple unary responses", fixture: Fixture{Name: "f", Method: "/template.v2.Echo/UnaryEcho", Responses: []string{"", ""}}},
+		{name: "Invalid code", fixture: Fixture{Name: "f", Method: "/template.v2.Echo/", Code: "Gone"}},
+		{name: "Code and response", fixture: Fixture{Name: "f", Method: "/template.v2.Echo/", Code: "NOT_FOUND", Response: `message: "x"`}},
+		{name: "Negative latency", fixture: Fixture{Name: "f", Method: "/template.v2.Echo/", Latency: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Register(grpc.NewServer(), services, Config{Fixtures: []Fixture{tt.fixture}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	expected := Config{Fixtures: []Fixture{{
+		Name:     "hello",
+		Method:   "/template.v2.Echo/UnaryEcho",
+		Request:  `message: "hello"`,
+		Response: `message: "hello from the mock"`,
+		Latency:  100 * time.Millisecond,
+	}}}
+
+	files := map[string]string{
+		"fixtures.yaml": `
+fixtures:
+  - name: hello
+    method: /template.v2.Echo/UnaryEcho
+    request: 'message: "hello"'
+    response: 'message: "hello from the mock"'
+    latency: 100ms
+`,
+		"fixtures.json": `{"fixtures": [{"name": "hello", "method": "/template.v2.Echo/UnaryEcho",
+"request": "message: \"hello\"", "response": "message: \"hello from the mock\"", "latency": "100ms"}]}`,
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+			cfg, err := LoadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, expected, cfg)
+		})
+	}
+
+	_, err := LoadFile(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+
+	t.Run("Unknown field", func(t *testing.T) {
+		path := filepath.Join(dir, "unknown.yaml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(`
+fixtures:
+  - name: hello
+    method: /template.v2.Echo/UnaryEcho
+    reponse: 'message: "hello from the mock"'
+`), 0600))
+
+		_, err := LoadFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field reponse not found")
+	})
+}
@@ -0,0 +1,166 @@
+package payloadlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// redacted replaces the values of the redacted fields.
+	redacted = "[REDACTED]"
+
+	// debugRedactFieldNumber is the field number of the `debug_redact` option in google.protobuf.FieldOptions.
+	debugRedactFieldNumber = 16
+)
+
+// redactor decides which fields are redacted, by the configured names or by the `debug_redact` field option.
+type redactor struct {
+	// fields are the full names (e.g. "template.v2.User.password") or the bare names (e.g. "password") to redact.
+	fields map[string]bool
+
+	// options caches the `debug_redact` option of the field descriptors.
+	options sync.Map
+}
+
+func newRedactor(fields []string) *redactor {
+	m := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		m[f] = true
+	}
+
+	return &redactor{fields: m}
+}
+
+func (r *redactor) redacted(fd protoreflect.FieldDescriptor) bool {
+	if r.fields[string(fd.FullName())] || r.fields[string(fd.Name())] {
+		return true
+	}
+
+	if v, ok := r.options.Load(fd); ok {
+		return v.(bool)
+	}
+	v := debugRedact(fd)
+	r.options.Store(fd, v)
+
+	return v
+}
+
+// encode serializes the message into JSON, the keys are the proto field names like the logged request_object.
+func (r *redactor) encode(m protoreflect.Message) ([]byte, error) {
+	return json.Marshal(r.messageValue(m))
+}
+
+func (r *redactor) messageValue(m protoreflect.Message) map[string]interface{} {
+	out := make(map[string]interface{})
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if r.redacted(fd) {
+			out[string(fd.Name())] = redacted
+
+			return true
+		}
+
+		switch {
+		case fd.IsList():
+			list := v.List()
+			values := make([]interface{}, 0, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				values = append(values, r.singularValue(fd, list.Get(i)))
+			}
+			out[string(fd.Name())] = values
+		case fd.IsMap():
+			values := make(map[string]interface{})
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				values[k.String()] = r.singularValue(fd.MapValue(), mv)
+
+				return true
+			})
+			out[string(fd.Name())] = values
+		default:
+			out[string(fd.Name())] = r.singularValue(fd, v)
+		}
+
+		return true
+	})
+
+	return out
+}
+
+func (r *redactor) singularValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return r.messageValue(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+
+		return int32(v.Enum())
+	case protoreflect.StringKind:
+		s := v.String()
+		if !utf8.ValidString(s) {
+			return []byte(s)
+		}
+
+		return s
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			// Not supported by JSON numbers
+			return fmt.Sprint(f)
+		}
+
+		return f
+	default:
+		return v.Interface()
+	}
+}
+
+// debugRedact reports whether the `debug_redact` option is set on the field,
+// the option is read from the unknown fields because it's newer than the protobuf runtime.
+func debugRedact(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := fd.Options().(*descriptorpb.FieldOptions)
+	if !ok || opts == nil {
+		return false
+	}
+
+	m := opts.ProtoReflect()
+	if known := m.Descriptor().Fields().ByNumber(debugRedactFieldNumber); known != nil {
+		return m.Get(known).Bool()
+	}
+
+	b := m.GetUnknown()
+	set := false
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return false
+		}
+		b = b[n:]
+
+		if num == debugRedactFieldNumber && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return false
+			}
+			set = v != 0
+			b = b[n:]
+
+			continue
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return false
+		}
+		b = b[n:]
+	}
+
+	return set
+}
@@ -0,0 +1,75 @@
+package payloadlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/runtime/protoimpl"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestDebugRedact(t *testing.T) {
+	redactOpts := &descriptorpb.FieldOptions{}
+	redactOpts.ProtoReflect().SetUnknown(protowire.AppendVarint(
+		protowire.AppendTag(nil, debugRedactFieldNumber, protowire.VarintType), 1))
+
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("payloadlog/test.proto"),
+		Package: proto.String("payloadlog.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{
+					Name:     proto.String("name"),
+					Number:   proto.Int32(1),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					JsonName: proto.String("name"),
+				},
+				{
+					Name:     proto.String("password"),
+					Number:   proto.Int32(2),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					JsonName: proto.String("password"),
+					Options:  redactOpts,
+				},
+			},
+		}},
+	}
+	b, err := proto.Marshal(fd)
+	require.NoError(t, err)
+
+	file := protoimpl.DescBuilder{RawDescriptor: b, NumMessages: 1}.Build().File
+	fields := file.Messages().ByName("User").Fields()
+
+	r := newRedactor(nil)
+	assert.False(t, r.redacted(fields.ByName("name")))
+	assert.True(t, r.redacted(fields.ByName("password")))
+	assert.True(t, r.redacted(fields.ByName("password")), "Must be cached")
+
+	r = newRedactor([]string{"payloadlog.test.User.name"})
+	assert.True(t, r.redacted(fields.ByName("name")))
+	assert.False(t, r.redacted(descriptorpb.File_google_protobuf_descriptor_proto.Messages().
+		ByName("FileDescriptorProto").Fields().ByName(protoreflect.Name("name"))))
+}
+
+func TestRedactorEncode(t *testing.T) {
+	m := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String("field"),
+		Number: proto.Int32(3),
+		Type:   descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum(),
+		Options: &descriptorpb.FieldOptions{
+			Deprecated: proto.Bool(true),
+		},
+	}
+
+	b, err := newRedactor([]string{"number"}).encode(m.ProtoReflect())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"field","number":"[REDACTED]","type":"TYPE_BYTES","options":{"deprecated":true}}`, string(b))
+}
@@ -0,0 +1,168 @@
+// Package payloadlog adds the request and response payloads into the "Request completed" log of the EntryLogs
+// interceptor, for a sample of the calls and with the sensitive fields redacted.
+package payloadlog
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+const (
+	// FieldRequestPayload is the log field of the serialized request.
+	FieldRequestPayload = "request_payload"
+	// FieldResponsePayload is the log field of the serialized response.
+	FieldResponsePayload = "response_payload"
+	// truncatedSuffix is the suffix of the field names reporting the truncated payloads.
+	truncatedSuffix = "_truncated"
+
+	// DefaultMaxBytes is the default maximum size of a logged payload.
+	DefaultMaxBytes = 4096
+)
+
+// Params represents the parameters of the payload logging interceptor.
+type Params struct {
+	// Rate is the sampling rate of the logged calls, between 0 (none) and 1 (all).
+	Rate float64
+	// MethodRates overrides the Rate for the full method names.
+	MethodRates map[string]float64
+	// RedactFields are the field names to redact, either full names (e.g. "template.v2.User.password")
+	// or bare names (e.g. "password"). The fields with the `debug_redact` option are always redacted.
+	RedactFields []string
+	// MaxBytes is the maximum size of a logged payload, DefaultMaxBytes is used if zero.
+	MaxBytes int
+}
+
+// Logger logs the payloads of the sampled calls.
+type Logger struct {
+	rate        float64
+	methodRates map[string]float64
+	maxBytes    int
+	redactor    *redactor
+
+	m      sync.Mutex
+	random func() float64
+}
+
+// New returns a new payload logger of the given params.
+func New(p Params) *Logger {
+	maxBytes := p.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	return &Logger{
+		rate:        p.Rate,
+		methodRates: p.MethodRates,
+		maxBytes:    maxBytes,
+		redactor:    newRedactor(p.RedactFields),
+		random:      rand.New(rand.NewSource(rand.Int63())).Float64, // nolint: gosec
+	}
+}
+
+// ParseMethodRates parses the per method sampling rates in the `<full method>=<rate>` format,
+// e.g. "/template.v2.Echo/UnaryEcho=0.5".
+func ParseMethodRates(values []string) (map[string]float64, error) {
+	rates := make(map[string]float64, len(values))
+	for _, v := range values {
+		i := strings.LastIndex(v, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid method rate %q, expected <full method>=<rate>", v)
+		}
+
+		rate, err := strconv.ParseFloat(v[i+1:], 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid rate of method rate %q, expected a number between 0 and 1", v)
+		}
+		rates[v[:i]] = rate
+	}
+
+	return rates, nil
+}
+
+// UnaryServerInterceptor returns a unary interceptor that logs the payloads.
+//
+// NOTE: Must be chained after the EntryLogs interceptor, because the payloads are added into its log.
+func (l *Logger) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !l.sampled(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		fields := make(logrus.Fields)
+		l.addPayload(fields, FieldRequestPayload, req)
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			l.addPayload(fields, FieldResponsePayload, resp)
+		}
+
+		if aErr := coremiddleware.AppendFieldsIntoEntryLogger(ctx, fields); aErr != nil {
+			coremiddleware.Logger(ctx).WithError(aErr).Warn("Failed to log the payloads")
+		}
+
+		return resp, err
+	}
+}
+
+func (l *Logger) sampled(fullMethod string) bool {
+	rate, ok := l.methodRates[fullMethod]
+	if !ok {
+		rate = l.rate
+	}
+
+	switch {
+	case rate <= 0:
+		return false
+	case rate >= 1:
+		return true
+	}
+
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	return l.random() < rate
+}
+
+func (l *Logger) addPayload(fields logrus.Fields, key string, v interface{}) {
+	m, ok := protoutil.Reflect(v)
+	if !ok {
+		return
+	}
+
+	b, err := l.redactor.encode(m)
+	if err != nil {
+		fields[key] = fmt.Sprintf("failed to serialize the payload: %v", err)
+
+		return
+	}
+
+	if len(b) > l.maxBytes {
+		b = truncate(b, l.maxBytes)
+		fields[key+truncatedSuffix] = true
+	}
+	fields[key] = string(b)
+}
+
+// truncate cuts the bytes to the max size, without splitting a UTF-8 character.
+func truncate(b []byte, max int) []byte {
+	b = b[:max]
+	for len(b) > 0 {
+		if r, size := utf8.DecodeLastRune(b); r != utf8.RuneError || size > 1 {
+			break
+		}
+		b = b[:len(b)-1]
+	}
+
+	return b
+}
@@ -0,0 +1,118 @@
+package payloadlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/sliide/logstash"
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+)
+
+const testMethod = "/template.v1.Echo/UnaryEcho"
+
+func TestLoggerUnaryServerInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &templatev1.UnaryEchoResponse{Message: "response " + req.(*templatev1.UnaryEchoRequest).GetMessage()}, nil
+	}
+
+	// call returns the fields of the "Request completed" log written by the EntryLogs interceptor
+	call := func(t *testing.T, p Params, req interface{}, handler grpc.UnaryHandler) map[string]interface{} {
+		buf := &bytes.Buffer{}
+		logger := logrus.New()
+		logger.SetOutput(buf)
+		logger.SetFormatter(&logstash.LogstashJsonFormatter{Env: "test", Service: "test"})
+
+		interceptor := grpcmiddleware.ChainUnaryServer(
+			coremiddleware.Logging(logrus.NewEntry(logger)),
+			coremiddleware.EntryLogs(),
+			New(p).UnaryServerInterceptor(),
+		)
+		_, _ = interceptor(context.Background(), req, info, handler)
+
+		fields := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &fields))
+
+		return fields
+	}
+
+	t.Run("Logs the payloads", func(t *testing.T) {
+		fields := call(t, Params{Rate: 1}, &templatev1.UnaryEchoRequest{Message: "hello"}, handler)
+
+		assert.Equal(t, `{"message":"hello"}`, fields[FieldRequestPayload])
+		assert.Equal(t, `{"message":"response hello"}`, fields[FieldResponsePayload])
+		assert.NotContains(t, fields, FieldRequestPayload+truncatedSuffix)
+	})
+
+	t.Run("Not sampled", func(t *testing.T) {
+		fields := call(t, Params{Rate: 1, MethodRates: map[string]float64{testMethod: 0}},
+			&templatev1.UnaryEchoRequest{Message: "hello"}, handler)
+
+		assert.NotContains(t, fields, FieldRequestPayload)
+		assert.NotContains(t, fields, FieldResponsePayload)
+	})
+
+	t.Run("Redacts the fields", func(t *testing.T) {
+		fields := call(t, Params{Rate: 1, RedactFields: []string{"template.v1.UnaryEchoResponse.message"}},
+			&templatev1.UnaryEchoRequest{Message: "hello"}, handler)
+
+		assert.Equal(t, `{"message":"hello"}`, fields[FieldRequestPayload])
+		assert.Equal(t, `{"message":"[REDACTED]"}`, fields[FieldResponsePayload])
+
+		fields = call(t, Params{Rate: 1, RedactFields: []string{"message"}}, &templatev1.UnaryEchoRequest{Message: "hello"}, handler)
+
+		assert.Equal(t, `{"message":"[REDACTED]"}`, fields[FieldRequestPayload])
+		assert.Equal(t, `{"message":"[REDACTED]"}`, fields[FieldResponsePayload])
+	})
+
+	t.Run("Truncates the payloads", func(t *testing.T) {
+		fields := call(t, Params{Rate: 1, MaxBytes: 16}, &templatev1.UnaryEchoRequest{Message: strings.Repeat("€", 10)}, handler)
+
+		assert.Equal(t, `{"message":"€`, fields[FieldRequestPayload])
+		assert.Equal(t, true, fields[FieldRequestPayload+truncatedSuffix])
+	})
+
+	t.Run("No response payload on error", func(t *testing.T) {
+		failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.New("failed")
+		}
+		fields := call(t, Params{Rate: 1}, &templatev1.UnaryEchoRequest{Message: "hello"}, failing)
+
+		assert.Equal(t, `{"message":"hello"}`, fields[FieldRequestPayload])
+		assert.NotContains(t, fields, FieldResponsePayload)
+	})
+}
+
+func TestLoggerSampled(t *testing.T) {
+	l := New(Params{Rate: 0.5, MethodRates: map[string]float64{"/a.Service/All": 1, "/a.Service/None": 0}})
+	l.random = func() float64 { return 0.4 }
+
+	assert.True(t, l.sampled("/a.Service/All"))
+	assert.False(t, l.sampled("/a.Service/None"))
+	assert.True(t, l.sampled(testMethod))
+
+	l.random = func() float64 { return 0.6 }
+	assert.False(t, l.sampled(testMethod))
+}
+
+func TestParseMethodRates(t *testing.T) {
+	rates, err := ParseMethodRates([]string{testMethod + "=0.25", "/a.Service/All=1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{testMethod: 0.25, "/a.Service/All": 1}, rates)
+
+	for _, v := range []string{testMethod, testMethod + "=abc", testMethod + "=2"} {
+		_, err := ParseMethodRates([]string{v})
+		assert.Error(t, err, v)
+	}
+}
@@ -0,0 +1,43 @@
+package protoutil
+
+import (
+	"errors"
+)
+
+// errNotFrame is returned when the RawCodec is used with another type than Frame.
+var errNotFrame = errors.New("value is not a frame")
+
+// Frame is a serialized message passed as is by the RawCodec.
+type Frame struct {
+	Bytes []byte
+}
+
+// RawCodec is a gRPC codec passing the serialized messages as is, so the calls could be forwarded without knowing
+// their types, e.g. `conn.Invoke(ctx, method, &Frame{Bytes: req}, resp, grpc.ForceCodec(RawCodec{}))`.
+type RawCodec struct{}
+
+// Marshal implements the encoding.Codec interface.
+func (RawCodec) Marshal(v interface{}) ([]byte, error) {
+	f, ok := v.(*Frame)
+	if !ok {
+		return nil, errNotFrame
+	}
+
+	return f.Bytes, nil
+}
+
+// Unmarshal implements the encoding.Codec interface.
+func (RawCodec) Unmarshal(data []byte, v interface{}) error {
+	f, ok := v.(*Frame)
+	if !ok {
+		return errNotFrame
+	}
+	f.Bytes = append(f.Bytes[:0], data...)
+
+	return nil
+}
+
+// Name implements the encoding.Codec interface, the frames are sent with the proto content type.
+func (RawCodec) Name() string {
+	return "proto"
+}
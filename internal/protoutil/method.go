@@ -0,0 +1,40 @@
+package protoutil
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// SplitMethodName returns the service and method names of the full method name, e.g. "template.v2.Echo" and
+// "UnaryEcho" of "/template.v2.Echo/UnaryEcho", the service is "unknown" if the name has no service.
+func SplitMethodName(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+
+	return "unknown", fullMethod
+}
+
+// MethodDescriptor returns the descriptor of the full method name from the registered proto files,
+// so the method names of the configs could be checked.
+func MethodDescriptor(fullMethod string) (protoreflect.MethodDescriptor, error) {
+	service, method := SplitMethodName(fullMethod)
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service %s", service)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, fmt.Errorf("unknown method %s", method)
+	}
+
+	return md, nil
+}
@@ -0,0 +1,121 @@
+// Package protoutil contains helpers for handling the request and response messages in the interceptors,
+// which are passed as interface{} and could be generated by either protobuf API version.
+package protoutil
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	protov1 "github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ErrNotProtoMessage is returned when the value is not a proto message.
+var ErrNotProtoMessage = errors.New("value is not a proto message")
+
+// Reflect returns the reflection interface of the message.
+func Reflect(v interface{}) (protoreflect.Message, bool) {
+	m, ok := v.(protov1.Message)
+	if !ok || m == nil {
+		return nil, false
+	}
+
+	return protov1.MessageReflect(m), true
+}
+
+// Marshal serializes the message deterministically, so the same message always produces the same bytes.
+func Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(protov1.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+
+	return proto.MarshalOptions{Deterministic: true}.Marshal(protov1.MessageV2(m))
+}
+
+// Hash returns the SHA-256 hash of the deterministic serialization of the message.
+func Hash(v interface{}) ([]byte, error) {
+	b, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+
+	return sum[:], nil
+}
+
+// Clone returns a deep copy of the message, or the value itself if it's not a proto message.
+func Clone(v interface{}) interface{} {
+	m, ok := v.(protov1.Message)
+	if !ok || m == nil {
+		return v
+	}
+
+	return protov1.Clone(m)
+}
+
+// MarshalAny serializes the message with its type, so it could be unmarshalled without knowing the type.
+func MarshalAny(v interface{}) ([]byte, error) {
+	m, ok := v.(protov1.Message)
+	if !ok {
+		return nil, ErrNotProtoMessage
+	}
+
+	a, err := anypb.New(protov1.MessageV2(m))
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(a)
+}
+
+// UnmarshalAny unmarshals the bytes serialized by MarshalAny, the message type must be registered.
+func UnmarshalAny(b []byte) (interface{}, error) {
+	a := &anypb.Any{}
+	if err := proto.Unmarshal(b, a); err != nil {
+		return nil, err
+	}
+
+	m, err := a.UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+
+	return protov1.MessageV1(m), nil
+}
+
+// DiffFields returns the names of the top-level fields differing between two messages of the same type,
+// nil if they're equal.
+func DiffFields(a, b proto.Message) []string {
+	if proto.Equal(a, b) {
+		return nil
+	}
+
+	ma, mb := a.ProtoReflect(), b.ProtoReflect()
+	var diff []string
+	fields := ma.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !proto.Equal(onlyField(ma, fd), onlyField(mb, fd)) {
+			diff = append(diff, string(fd.Name()))
+		}
+	}
+	if len(diff) == 0 {
+		// Only the unknown fields differ
+		diff = append(diff, "<unknown>")
+	}
+
+	return diff
+}
+
+// onlyField returns a new message with only the field of the message set.
+func onlyField(m protoreflect.Message, fd protoreflect.FieldDescriptor) proto.Message {
+	c := m.New()
+	if m.Has(fd) {
+		c.Set(fd, m.Get(fd))
+	}
+
+	return c.Interface()
+}
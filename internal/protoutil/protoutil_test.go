@@ -0,0 +1,102 @@
+package protoutil
+
+import (
+	"testing"
+
+	protov1 "github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+)
+
+func TestMarshalAny(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  interface{}
+	}{
+		{name: "API v1 message", msg: &dto.LabelPair{Name: protov1.String("name"), Value: protov1.String("value")}},
+		{name: "API v2 message", msg: &templatev1.UnaryEchoResponse{Message: "hello"}},
+		{name: "API v2 well-known message", msg: durationpb.New(3e9)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := MarshalAny(tt.msg)
+			require.NoError(t, err)
+
+			actual, err := UnmarshalAny(b)
+			require.NoError(t, err)
+			assert.IsType(t, tt.msg, actual)
+			assert.Equal(t, tt.msg.(interface{ String() string }).String(), actual.(interface{ String() string }).String())
+		})
+	}
+
+	t.Run("Not a proto message", func(t *testing.T) {
+		_, err := MarshalAny("hello")
+		assert.ErrorIs(t, err, ErrNotProtoMessage)
+	})
+
+	t.Run("Malformed bytes", func(t *testing.T) {
+		_, err := UnmarshalAny([]byte{0xff})
+		assert.Error(t, err)
+	})
+}
+
+func TestHash(t *testing.T) {
+	a, err := Hash(&templatev1.UnaryEchoRequest{Message: "a"})
+	require.NoError(t, err)
+	b, err := Hash(&templatev1.UnaryEchoRequest{Message: "b"})
+	require.NoError(t, err)
+	a2, err := Hash(&templatev1.UnaryEchoRequest{Message: "a"})
+	require.NoError(t, err)
+
+	assert.Len(t, a, 32)
+	assert.Equal(t, a, a2)
+	assert.NotEqual(t, a, b)
+}
+
+func TestReflect(t *testing.T) {
+	m, ok := Reflect(&templatev1.UnaryEchoRequest{Message: "a"})
+	require.True(t, ok)
+	assert.Equal(t, "template.v1.UnaryEchoRequest", string(m.Descriptor().FullName()))
+
+	_, ok = Reflect(struct{}{})
+	assert.False(t, ok)
+}
+
+func TestClone(t *testing.T) {
+	m := &templatev1.UnaryEchoRequest{Message: "hello"}
+
+	clone := Clone(m)
+	assert.Equal(t, m.GetMessage(), clone.(*templatev1.UnaryEchoRequest).GetMessage())
+	assert.NotSame(t, m, clone)
+	assert.Equal(t, "not proto", Clone("not proto"))
+}
+
+func TestSplitMethodName(t *testing.T) {
+	service, method := SplitMethodName("/template.v2.Echo/UnaryEcho")
+	assert.Equal(t, "template.v2.Echo", service)
+	assert.Equal(t, "UnaryEcho", method)
+
+	service, method = SplitMethodName("UnaryEcho")
+	assert.Equal(t, "unknown", service)
+	assert.Equal(t, "UnaryEcho", method)
+}
+
+func TestMethodDescriptor(t *testing.T) {
+	md, err := MethodDescriptor("/template.v1.Echo/UnaryEcho")
+	require.NoError(t, err)
+	assert.Equal(t, "template.v1.UnaryEchoRequest", string(md.Input().FullName()))
+
+	for fullMethod, msg := range map[string]string{
+		"/template.v1.Missing/UnaryEcho":   "unknown service template.v1.Missing",
+		"/template.v1.UnaryEchoRequest/Do": "template.v1.UnaryEchoRequest is not a service",
+		"/template.v1.Echo/Missing":        "unknown method Missing",
+	} {
+		_, err := MethodDescriptor(fullMethod)
+		assert.EqualError(t, err, msg, fullMethod)
+	}
+}
@@ -0,0 +1,282 @@
+// Package proxyproto parses the HAProxy PROXY protocol v1 and v2 headers of the accepted connections,
+// so the remote address of the connections is the client address behind a load balancer (e.g. AWS NLB).
+//
+// See https://www.haproxy.org/download/2.3/doc/proxy-protocol.txt
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHeaderTimeout is the default duration to wait for the header of a connection.
+const DefaultHeaderTimeout = 5 * time.Second
+
+const (
+	// v1MaxLength is the maximum length of a v1 header including the CRLF.
+	v1MaxLength = 107
+
+	// v2HeaderLength is the length of the fixed part of a v2 header.
+	v2HeaderLength = 16
+
+	v2CommandLocal = 0x0
+	v2CommandProxy = 0x1
+
+	v2FamilyTCP4 = 0x11
+	v2FamilyTCP6 = 0x21
+)
+
+var (
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	// ErrInvalidHeader is returned when the connection doesn't start with a valid header.
+	ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+
+	// ErrUntrustedSource is returned when the connection doesn't come from a trusted proxy.
+	ErrUntrustedSource = errors.New("PROXY protocol header from an untrusted source")
+)
+
+// Params represents the parameters of the PROXY protocol listener.
+type Params struct {
+	// Trusted are the networks of the proxies allowed to send the header, no source is allowed if empty.
+	Trusted []*net.IPNet
+	// HeaderTimeout is the duration to wait for the header, DefaultHeaderTimeout is used if zero.
+	HeaderTimeout time.Duration
+}
+
+// Listener wraps a listener whose connections start with a PROXY protocol header,
+// the connections without a valid header are closed on the first read.
+type Listener struct {
+	net.Listener
+
+	trusted       []*net.IPNet
+	headerTimeout time.Duration
+}
+
+// NewListener returns a new PROXY protocol listener wrapping the given listener.
+func NewListener(l net.Listener, p Params) *Listener {
+	timeout := p.HeaderTimeout
+	if timeout <= 0 {
+		timeout = DefaultHeaderTimeout
+	}
+
+	return &Listener{
+		Listener:      l,
+		trusted:       p.Trusted,
+		headerTimeout: timeout,
+	}
+}
+
+// Accept implements net.Listener, the header is parsed lazily on the first use of the connection,
+// so a slow client cannot block the accepting loop.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{
+		Conn:          c,
+		r:             bufio.NewReader(c),
+		trusted:       l.trusted,
+		headerTimeout: l.headerTimeout,
+	}, nil
+}
+
+// Conn is a connection whose remote and local addresses are read from the PROXY protocol header.
+type Conn struct {
+	net.Conn
+
+	r             *bufio.Reader
+	trusted       []*net.IPNet
+	headerTimeout time.Duration
+
+	once       sync.Once
+	err        error
+	remoteAddr net.Addr
+	localAddr  net.Addr
+}
+
+// Read implements net.Conn, returns an error if the header is invalid.
+func (c *Conn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.r.Read(b)
+}
+
+// RemoteAddr returns the source address of the header,
+// or the address of the proxy for the LOCAL and UNKNOWN headers.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+// LocalAddr returns the destination address of the header,
+// or the local address of the proxy connection for the LOCAL and UNKNOWN headers.
+func (c *Conn) LocalAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.localAddr != nil {
+		return c.localAddr
+	}
+
+	return c.Conn.LocalAddr()
+}
+
+func (c *Conn) readHeader() {
+	if !c.trustedSource() {
+		c.err = ErrUntrustedSource
+
+		return
+	}
+
+	_ = c.Conn.SetReadDeadline(time.Now().Add(c.headerTimeout))
+	defer func() {
+		_ = c.Conn.SetReadDeadline(time.Time{})
+	}()
+
+	c.remoteAddr, c.localAddr, c.err = parseHeader(c.r)
+	if c.err != nil {
+		_ = c.Conn.Close()
+	}
+}
+
+func (c *Conn) trustedSource() bool {
+	addr, ok := c.Conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, n := range c.trusted {
+		if n.Contains(addr.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseHeader parses a v1 or v2 header, returns nil addresses for the LOCAL and UNKNOWN headers.
+func parseHeader(r *bufio.Reader) (src, dst net.Addr, err error) {
+	b, err := r.Peek(len(v1Prefix))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	if bytes.Equal(b, v1Prefix) {
+		return parseV1(r)
+	}
+
+	b, err = r.Peek(len(v2Signature))
+	if err != nil || !bytes.Equal(b, v2Signature) {
+		return nil, nil, ErrInvalidHeader
+	}
+
+	return parseV2(r)
+}
+
+// parseV1 parses a v1 header, e.g. "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func parseV1(r *bufio.Reader) (src, dst net.Addr, err error) {
+	var line []byte
+	for len(line) < v1MaxLength {
+		c, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+		}
+		line = append(line, c)
+		if c == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, nil, fmt.Errorf("%w: v1 header too long", ErrInvalidHeader)
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(line), "\r\n"), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, nil, fmt.Errorf("%w: %q", ErrInvalidHeader, line)
+	}
+
+	src, err = v1Addr(fields[1], fields[2], fields[4])
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err = v1Addr(fields[1], fields[3], fields[5])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return src, dst, nil
+}
+
+func v1Addr(protocol, ip, port string) (net.Addr, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil || (protocol == "TCP4") != (addr.To4() != nil) {
+		return nil, fmt.Errorf("%w: invalid %s address %q", ErrInvalidHeader, protocol, ip)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid port %q", ErrInvalidHeader, port)
+	}
+
+	return &net.TCPAddr{IP: addr, Port: int(p)}, nil
+}
+
+// parseV2 parses a v2 header, the TLVs are skipped.
+func parseV2(r *bufio.Reader) (src, dst net.Addr, err error) {
+	header := make([]byte, v2HeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	version, command := header[12]>>4, header[12]&0x0f
+	if version != 2 || (command != v2CommandLocal && command != v2CommandProxy) {
+		return nil, nil, fmt.Errorf("%w: unsupported version or command 0x%x", ErrInvalidHeader, header[12])
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	if command == v2CommandLocal {
+		return nil, nil, nil
+	}
+
+	switch header[13] {
+	case v2FamilyTCP4:
+		if len(payload) < 12 {
+			return nil, nil, fmt.Errorf("%w: short TCP4 addresses", ErrInvalidHeader)
+		}
+
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))},
+			&net.TCPAddr{IP: net.IP(payload[4:8]), Port: int(binary.BigEndian.Uint16(payload[10:12]))}, nil
+	case v2FamilyTCP6:
+		if len(payload) < 36 {
+			return nil, nil, fmt.Errorf("%w: short TCP6 addresses", ErrInvalidHeader)
+		}
+
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))},
+			&net.TCPAddr{IP: net.IP(payload[16:32]), Port: int(binary.BigEndian.Uint16(payload[34:36]))}, nil
+	}
+
+	// The other families (UDP, unix sockets or unspecified) keep the addresses of the proxy connection
+	return nil, nil, nil
+}
@@ -0,0 +1,154 @@
+package proxyproto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func v2Header(command, family byte, addresses []byte) []byte {
+	b := append([]byte{}, v2Signature...)
+	b = append(b, 0x20|command, family, 0, 0)
+	binary.BigEndian.PutUint16(b[14:16], uint16(len(addresses)))
+
+	return append(b, addresses...)
+}
+
+func TestParseHeader(t *testing.T) {
+	tcp4 := []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb}
+	tcp6 := make([]byte, 36)
+	copy(tcp6[0:16], net.ParseIP("2001:db8::1"))
+	copy(tcp6[16:32], net.ParseIP("2001:db8::2"))
+	binary.BigEndian.PutUint16(tcp6[32:34], 56324)
+	binary.BigEndian.PutUint16(tcp6[34:36], 443)
+
+	tests := []struct {
+		name   string
+		header string
+		src    string
+		dst    string
+		err    bool
+	}{
+		{name: "v1 TCP4", header: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n", src: "192.0.2.1:56324", dst: "198.51.100.1:443"},
+		{name: "v1 TCP6", header: "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n", src: "[2001:db8::1]:56324", dst: "[2001:db8::2]:443"},
+		{name: "v1 UNKNOWN", header: "PROXY UNKNOWN\r\n"},
+		{name: "v1 family mismatch", header: "PROXY TCP4 2001:db8::1 2001:db8::2 56324 443\r\n", err: true},
+		{name: "v1 invalid port", header: "PROXY TCP4 192.0.2.1 198.51.100.1 70000 443\r\n", err: true},
+		{name: "v1 too long", header: "PROXY TCP4 " + strings.Repeat("1", 200) + "\r\n", err: true},
+		{name: "v2 TCP4", header: string(v2Header(v2CommandProxy, v2FamilyTCP4, tcp4)), src: "192.0.2.1:56324", dst: "198.51.100.1:443"},
+		{name: "v2 TCP4 with TLVs", header: string(v2Header(v2CommandProxy, v2FamilyTCP4, append(tcp4, 0x04, 0x00, 0x01, 0xff))), src: "192.0.2.1:56324", dst: "198.51.100.1:443"},
+		{name: "v2 TCP6", header: string(v2Header(v2CommandProxy, v2FamilyTCP6, tcp6)), src: "[2001:db8::1]:56324", dst: "[2001:db8::2]:443"},
+		{name: "v2 LOCAL", header: string(v2Header(v2CommandLocal, 0, nil))},
+		{name: "v2 short addresses", header: string(v2Header(v2CommandProxy, v2FamilyTCP4, tcp4[:8])), err: true},
+		{name: "no header", header: "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.header + "payload"))
+			src, dst, err := parseHeader(r)
+			if tt.err {
+				assert.True(t, errors.Is(err, ErrInvalidHeader), "unexpected error: %v", err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.src == "" {
+				assert.Nil(t, src)
+				assert.Nil(t, dst)
+			} else {
+				assert.Equal(t, tt.src, src.String())
+				assert.Equal(t, tt.dst, dst.String())
+			}
+
+			rest, err := ioutil.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, "payload", string(rest), "Must not consume the payload")
+		})
+	}
+}
+
+func TestListener(t *testing.T) {
+	_, loopback, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+	trusted := []*net.IPNet{loopback}
+
+	accept := func(t *testing.T, p Params, data string) net.Conn {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = lis.Close()
+		})
+
+		go func() {
+			c, err := net.Dial("tcp", lis.Addr().String())
+			if err != nil {
+				return
+			}
+			_, _ = c.Write([]byte(data))
+			t.Cleanup(func() {
+				_ = c.Close()
+			})
+		}()
+
+		c, err := NewListener(lis, p).Accept()
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = c.Close()
+		})
+
+		return c
+	}
+
+	t.Run("Remote address from header", func(t *testing.T) {
+		c := accept(t, Params{Trusted: trusted}, "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello")
+
+		assert.Equal(t, "192.0.2.1:56324", c.RemoteAddr().String())
+		assert.Equal(t, "198.51.100.1:443", c.LocalAddr().String())
+
+		b := make([]byte, 5)
+		_, err := c.Read(b)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(b))
+	})
+
+	t.Run("Invalid header", func(t *testing.T) {
+		c := accept(t, Params{Trusted: trusted}, "hello world\r\n")
+
+		_, err := c.Read(make([]byte, 5))
+		assert.True(t, errors.Is(err, ErrInvalidHeader))
+		assert.Equal(t, "127.0.0.1", c.RemoteAddr().(*net.TCPAddr).IP.String())
+	})
+
+	t.Run("Header timeout", func(t *testing.T) {
+		c := accept(t, Params{Trusted: trusted, HeaderTimeout: 10 * time.Millisecond}, "PROXY")
+
+		_, err := c.Read(make([]byte, 5))
+		assert.True(t, errors.Is(err, ErrInvalidHeader))
+	})
+
+	t.Run("Untrusted source", func(t *testing.T) {
+		_, other, err := net.ParseCIDR("10.0.0.0/8")
+		require.NoError(t, err)
+		c := accept(t, Params{Trusted: []*net.IPNet{other}}, "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello")
+
+		_, err = c.Read(make([]byte, 5))
+		assert.Equal(t, ErrUntrustedSource, err)
+	})
+
+	t.Run("No trusted source", func(t *testing.T) {
+		c := accept(t, Params{}, "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello")
+
+		_, err := c.Read(make([]byte, 5))
+		assert.Equal(t, ErrUntrustedSource, err)
+	})
+}
@@ -0,0 +1,183 @@
+// Package rotate writes the log files rotated by size, e.g. the access log and the capture files.
+package rotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMaxFileSize is the default size of the file to rotate it, same as the logstash log file.
+	DefaultMaxFileSize int64 = 2 << 30 // 2GiB
+
+	// rotationCheckInterval is the interval of checking the size of the file, same as the logstash log file.
+	rotationCheckInterval = 10 * time.Second
+)
+
+// File is a file writer rotated when exceeding the max size, with the same policy as `logstash.Init`,
+// which rotates the output of the global logger only.
+//
+// The size is checked periodically, and the file is renamed with the `.1` suffix when it's too large.
+// The previous `.1` file is renamed with a timestamp suffix and compressed in the background.
+type File struct {
+	path    string
+	maxSize int64
+
+	m    sync.Mutex
+	f    *os.File
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Open opens the file for appending and starts checking its size,
+// the DefaultMaxFileSize is used if the maxSize is zero.
+func Open(path string, maxSize int64) (*File, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFileSize
+	}
+
+	f, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &File{
+		path:    path,
+		maxSize: maxSize,
+		f:       f,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go r.run(rotationCheckInterval)
+
+	return r, nil
+}
+
+// Write implements the io.Writer interface.
+func (r *File) Write(p []byte) (int, error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.f.Write(p)
+}
+
+// Close stops the rotation and closes the file.
+func (r *File) Close() error {
+	close(r.stop)
+	<-r.done
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return r.f.Close()
+}
+
+func (r *File) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := r.rotateIfExceeded(); err != nil {
+				logrus.WithError(err).WithField("file", r.path).Error("Failed to rotate the file")
+			}
+		}
+	}
+}
+
+func (r *File) rotateIfExceeded() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	info, err := r.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() <= r.maxSize {
+		return nil
+	}
+
+	rotated := r.path + ".1"
+	if _, err := os.Stat(rotated); err == nil {
+		archived := rotated + time.Now().Format(time.RFC3339)
+		if err := os.Rename(rotated, archived); err != nil {
+			return fmt.Errorf("failed to rename the rotated file %s: %w", rotated, err)
+		}
+		go compress(archived)
+	}
+
+	if err := os.Rename(r.path, rotated); err != nil {
+		return fmt.Errorf("failed to rename the file %s: %w", r.path, err)
+	}
+
+	f, err := openFile(r.path)
+	if err != nil {
+		return err
+	}
+	_ = r.f.Close()
+	r.f = f
+
+	return nil
+}
+
+func openFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the file %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// compress gzips the file into the `.gz` file and removes it.
+func compress(path string) {
+	l := logrus.WithField("file", path)
+	if err := gzipFile(path); err != nil {
+		l.WithError(err).Error("Failed to compress the rotated file")
+
+		return
+	}
+	if err := os.Remove(path); err != nil {
+		l.WithError(err).Error("Failed to remove the compressed file")
+	}
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = dst.Close()
+
+		return err
+	}
+
+	return dst.Close()
+}
@@ -0,0 +1,59 @@
+package rotate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "access.log")
+
+	r, err := Open(path, 10)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, r.Close())
+	}()
+
+	_, err = r.Write([]byte("0123456789"))
+	require.NoError(t, err)
+	require.NoError(t, r.rotateIfExceeded())
+	assert.NoFileExists(t, path+".1", "Must not rotate within the max size")
+
+	_, err = r.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.NoError(t, r.rotateIfExceeded())
+	assertFileContent(t, path+".1", "0123456789first\n")
+	assertFileContent(t, path, "")
+
+	_, err = r.Write([]byte("second file\n"))
+	require.NoError(t, err)
+	require.NoError(t, r.rotateIfExceeded())
+	assertFileContent(t, path+".1", "second file\n")
+
+	// The previous rotated file is archived and compressed in the background
+	assert.Eventually(t, func() bool {
+		matches, _ := filepath.Glob(path + ".1*.gz")
+
+		return len(matches) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestOpenError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "capture.log")
+
+	_, err := Open(path, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path, "Must name the file")
+}
+
+func assertFileContent(t *testing.T, path, expected string) {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(b))
+}
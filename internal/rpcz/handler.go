@@ -0,0 +1,82 @@
+package rpcz
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strconv"
+)
+
+// page is the HTML template of the debug page.
+var page = template.Must(template.New("rpcz").Funcs(template.FuncMap{
+	"bound": func(b Bucket) string {
+		if b.UpperBound == 0 {
+			return "+Inf"
+		}
+
+		return strconv.FormatFloat(b.UpperBound, 'g', -1, 64)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>rpcz</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<h1>rpcz</h1>
+<p><a href="?format=json">JSON</a></p>
+{{range .}}
+<h2 id="{{.Method}}"><a href="?method={{.Method}}">{{.Method}}</a></h2>
+<p>Total: {{.Total}}, errors: {{.Errors}}</p>
+<table>
+<tr><th>Latency (s)</th>{{range .Latency}}<th>&le; {{bound .}}</th>{{end}}</tr>
+<tr><td>Count</td>{{range .Latency}}<td>{{.Count}}</td>{{end}}</tr>
+</table>
+<h3>Recent requests</h3>
+{{template "calls" .Requests}}
+<h3>Recent errors</h3>
+{{template "calls" .Failures}}
+{{else}}
+<p>No requests yet.</p>
+{{end}}
+</body>
+</html>
+{{define "calls"}}<table>
+<tr><th>Time</th><th>Duration</th><th>Code</th><th>Trace ID</th><th>Request ID</th><th>Payload</th><th>Error</th></tr>
+{{range .}}<tr{{if .Error}} class="error"{{end}}><td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td><td>{{.Duration}}</td><td>{{.Code}}</td><td>{{.TraceID}}</td><td>{{.RequestID}}</td><td>{{.Payload}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>{{end}}
+`))
+
+// Handler returns the handler of the debug page, the stats of a single method are shown with the `method` query,
+// and served as JSON with the `format=json` query.
+func (s *Store) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var stats []MethodStats
+		if method := r.URL.Query().Get("method"); method != "" {
+			st, ok := s.MethodSnapshot(method)
+			if !ok {
+				http.NotFound(w, r)
+
+				return
+			}
+			stats = []MethodStats{st}
+		} else {
+			stats = s.Snapshot()
+		}
+
+		if r.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(stats)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = page.Execute(w, stats)
+	})
+}
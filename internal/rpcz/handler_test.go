@@ -0,0 +1,69 @@
+package rpcz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+)
+
+func TestStoreHandler(t *testing.T) {
+	s := NewStore(10)
+	interceptor := s.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/template.v1.Echo/UnaryEcho"}
+
+	_, _ = interceptor(context.Background(), &templatev1.UnaryEchoRequest{Message: "secret"}, info,
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "<not found>")
+		})
+
+	t.Run("JSON", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/rpcz?format=json", nil))
+
+		var stats []MethodStats
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+		require.Len(t, stats, 1)
+		require.Len(t, stats[0].Failures, 1)
+
+		c := stats[0].Failures[0]
+		assert.Equal(t, "NotFound", c.Code)
+		assert.Equal(t, "<not found>", c.Error)
+		assert.Equal(t, "template.v1.UnaryEchoRequest (8 bytes)", c.Payload)
+		assert.NotEmpty(t, c.RequestID)
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/rpcz", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "/template.v1.Echo/UnaryEcho")
+		assert.Contains(t, rec.Body.String(), "&lt;not found&gt;")
+		assert.NotContains(t, rec.Body.String(), "secret", "Must not expose the payload values")
+	})
+
+	t.Run("Unknown method", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/rpcz?method=/unknown", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Nil store", func(t *testing.T) {
+		var s *Store
+		resp, err := s.UnaryServerInterceptor()(context.Background(), "req", info,
+			func(ctx context.Context, req interface{}) (interface{}, error) { return "resp", nil })
+		require.NoError(t, err)
+		assert.Equal(t, "resp", resp)
+	})
+}
@@ -0,0 +1,93 @@
+package rpcz
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+// maxErrorLength is the maximum length of the error messages kept.
+const maxErrorLength = 256
+
+// UnaryServerInterceptor returns a unary interceptor that adds the calls into the store,
+// the interceptor does nothing if the store is nil.
+//
+// NOTE: Should be chained before the grpcerr interceptor, so the calls have the codes returned to the callers.
+func (s *Store) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if s == nil {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		s.add(ctx, info.FullMethod, start, payloadSummary(req), err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that adds the calls into the store,
+// the interceptor does nothing if the store is nil.
+func (s *Store) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if s == nil {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		err := handler(srv, ss)
+		s.add(ss.Context(), info.FullMethod, start, "stream", err)
+
+		return err
+	}
+}
+
+func (s *Store) add(ctx context.Context, fullMethod string, start time.Time, payload string, err error) {
+	st := status.Convert(err)
+	reqCtx := coremiddleware.RequestContext(ctx)
+
+	c := Call{
+		Time:      start,
+		Duration:  time.Since(start),
+		Code:      st.Code().String(),
+		TraceID:   reqCtx.TraceID(),
+		RequestID: reqCtx.RequestID(),
+		Payload:   payload,
+	}
+	if err != nil {
+		c.Error = truncate(st.Message(), maxErrorLength)
+	}
+
+	s.Add(fullMethod, c, st.Code() != codes.OK)
+}
+
+// payloadSummary returns the type and the size of the request, the values are not exposed in the debug pages.
+func payloadSummary(req interface{}) string {
+	m, ok := protoutil.Reflect(req)
+	if !ok {
+		return fmt.Sprintf("%T", req)
+	}
+
+	b, err := protoutil.Marshal(req)
+	if err != nil {
+		return string(m.Descriptor().FullName())
+	}
+
+	return fmt.Sprintf("%s (%d bytes)", m.Descriptor().FullName(), len(b))
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+
+	return s[:max] + "..."
+}
@@ -0,0 +1,211 @@
+// Package rpcz keeps the recent requests and errors of every method in memory,
+// and serves them in the `/debug/rpcz` debug pages.
+package rpcz
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSize is the default number of the recent requests and errors kept per method.
+	DefaultSize = 20
+
+	// maxMethods bounds the number of the methods tracked, the calls of the other methods are ignored.
+	maxMethods = 500
+)
+
+// latencyBuckets are the upper bounds of the latency histogram buckets in seconds, same as the Prometheus defaults.
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Call is a recent call of a method.
+type Call struct {
+	Time      time.Time     `json:"time"`
+	Duration  time.Duration `json:"duration"`
+	Code      string        `json:"code"`
+	TraceID   string        `json:"trace_id"`
+	RequestID string        `json:"request_id"`
+	// Payload is a short summary of the request payload, its type and size, the values are never kept.
+	Payload string `json:"payload"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Bucket is a latency histogram bucket.
+type Bucket struct {
+	// UpperBound is the upper bound of the bucket in seconds, zero for the last unbounded bucket.
+	UpperBound float64 `json:"upper_bound"`
+	Count      uint64  `json:"count"`
+}
+
+// MethodStats is the snapshot of the recent calls of a method.
+type MethodStats struct {
+	Method   string   `json:"method"`
+	Total    uint64   `json:"total"`
+	Errors   uint64   `json:"errors"`
+	Requests []Call   `json:"requests"`
+	Failures []Call   `json:"failures"`
+	Latency  []Bucket `json:"latency"`
+}
+
+// Store keeps the recent calls in fixed size ring buffers, the memory use is bounded by the size and the number
+// of the methods.
+type Store struct {
+	size int
+
+	m       sync.RWMutex
+	methods map[string]*methodCalls
+}
+
+type methodCalls struct {
+	m sync.Mutex
+
+	total    uint64
+	errors   uint64
+	requests ring
+	failures ring
+	buckets  []uint64
+}
+
+// ring is a fixed size ring buffer of the calls.
+type ring struct {
+	calls []Call
+	next  int
+	full  bool
+}
+
+func (r *ring) add(c Call) {
+	r.calls[r.next] = c
+	r.next = (r.next + 1) % len(r.calls)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot returns the calls from the newest to the oldest.
+func (r *ring) snapshot() []Call {
+	n := r.next
+	if r.full {
+		n = len(r.calls)
+	}
+
+	out := make([]Call, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, r.calls[(r.next-i+len(r.calls))%len(r.calls)])
+	}
+
+	return out
+}
+
+// NewStore returns a new store keeping the given number of the recent requests and errors per method,
+// the DefaultSize is used if zero.
+func NewStore(size int) *Store {
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	return &Store{
+		size:    size,
+		methods: make(map[string]*methodCalls),
+	}
+}
+
+// Add adds a call of the method, the failed calls are also kept in the recent errors.
+func (s *Store) Add(method string, c Call, failed bool) {
+	mc := s.method(method)
+	if mc == nil {
+		return
+	}
+
+	mc.m.Lock()
+	defer mc.m.Unlock()
+
+	mc.total++
+	mc.requests.add(c)
+	if failed {
+		mc.errors++
+		mc.failures.add(c)
+	}
+
+	i := sort.SearchFloat64s(latencyBuckets, c.Duration.Seconds())
+	mc.buckets[i]++
+}
+
+// Snapshot returns the stats of all the methods sorted by the method name.
+func (s *Store) Snapshot() []MethodStats {
+	s.m.RLock()
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	s.m.RUnlock()
+	sort.Strings(names)
+
+	stats := make([]MethodStats, 0, len(names))
+	for _, name := range names {
+		if st, ok := s.MethodSnapshot(name); ok {
+			stats = append(stats, st)
+		}
+	}
+
+	return stats
+}
+
+// MethodSnapshot returns the stats of the method, the bool reports whether the method is found.
+func (s *Store) MethodSnapshot(method string) (MethodStats, bool) {
+	s.m.RLock()
+	mc, ok := s.methods[method]
+	s.m.RUnlock()
+	if !ok {
+		return MethodStats{}, false
+	}
+
+	mc.m.Lock()
+	defer mc.m.Unlock()
+
+	latency := make([]Bucket, len(mc.buckets))
+	for i, count := range mc.buckets {
+		latency[i].Count = count
+		if i < len(latencyBuckets) {
+			latency[i].UpperBound = latencyBuckets[i]
+		}
+	}
+
+	return MethodStats{
+		Method:   method,
+		Total:    mc.total,
+		Errors:   mc.errors,
+		Requests: mc.requests.snapshot(),
+		Failures: mc.failures.snapshot(),
+		Latency:  latency,
+	}, true
+}
+
+// method returns the calls of the method, nil if too many methods are tracked.
+func (s *Store) method(method string) *methodCalls {
+	s.m.RLock()
+	mc, ok := s.methods[method]
+	s.m.RUnlock()
+	if ok {
+		return mc
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if mc, ok := s.methods[method]; ok {
+		return mc
+	}
+	if len(s.methods) >= maxMethods {
+		return nil
+	}
+
+	mc = &methodCalls{
+		requests: ring{calls: make([]Call, s.size)},
+		failures: ring{calls: make([]Call, s.size)},
+		buckets:  make([]uint64, len(latencyBuckets)+1),
+	}
+	s.methods[method] = mc
+
+	return mc
+}
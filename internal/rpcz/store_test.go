@@ -0,0 +1,61 @@
+package rpcz
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	s := NewStore(3)
+	for i := 0; i < 5; i++ {
+		s.Add("/test.Service/Get", Call{RequestID: fmt.Sprint(i), Duration: time.Duration(i) * 100 * time.Millisecond}, i == 1)
+	}
+	s.Add("/test.Service/Create", Call{RequestID: "create", Duration: time.Minute}, false)
+
+	stats := s.Snapshot()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "/test.Service/Create", stats[0].Method)
+
+	get := stats[1]
+	assert.Equal(t, uint64(5), get.Total)
+	assert.Equal(t, uint64(1), get.Errors)
+	assert.Equal(t, []string{"4", "3", "2"}, requestIDs(get.Requests), "Must keep the newest calls first")
+	assert.Equal(t, []string{"1"}, requestIDs(get.Failures))
+
+	counts := make(map[float64]uint64)
+	for _, b := range get.Latency {
+		counts[b.UpperBound] = b.Count
+	}
+	assert.Equal(t, map[float64]uint64{
+		.005: 1, .01: 0, .025: 0, .05: 0, .1: 1, .25: 1, .5: 2, 1: 0, 2.5: 0, 5: 0, 10: 0, 0: 0,
+	}, counts)
+
+	create, ok := s.MethodSnapshot("/test.Service/Create")
+	require.True(t, ok)
+	assert.Equal(t, uint64(1), create.Latency[len(create.Latency)-1].Count, "Must count in the unbounded bucket")
+
+	_, ok = s.MethodSnapshot("/test.Service/Unknown")
+	assert.False(t, ok)
+}
+
+func TestStoreBoundedMethods(t *testing.T) {
+	s := NewStore(1)
+	for i := 0; i < maxMethods+10; i++ {
+		s.Add(fmt.Sprintf("/test.Service/Method%d", i), Call{}, false)
+	}
+
+	assert.Len(t, s.Snapshot(), maxMethods)
+}
+
+func requestIDs(calls []Call) []string {
+	ids := make([]string, 0, len(calls))
+	for _, c := range calls {
+		ids = append(ids, c.RequestID)
+	}
+
+	return ids
+}
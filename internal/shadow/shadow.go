@@ -0,0 +1,254 @@
+// Package shadow mirrors a sample of the unary calls to a shadow target asynchronously,
+// and compares the shadow responses with the primary ones, e.g. to validate a new version before releasing it.
+package shadow
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+const (
+	// MetaKeyShadow represents the meta key set on the shadow calls, so the shadow target could skip the side effects.
+	MetaKeyShadow = "x-shadow-request"
+
+	// DefaultTimeout is the default time limit of the shadow calls.
+	DefaultTimeout = time.Second
+	// DefaultMaxConcurrency is the default maximum number of shadow calls running at the same time.
+	DefaultMaxConcurrency = 10
+
+	// Results of the comparisons used in the metrics.
+	resultMatch            = "match"
+	resultCodeMismatch     = "code_mismatch"
+	resultResponseMismatch = "response_mismatch"
+)
+
+// ErrMethodsRequired is returned when no method is mirrored, all methods are never mirrored implicitly.
+var ErrMethodsRequired = errors.New("shadow methods are required")
+
+var (
+	shadowCalls = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_shadow_calls_total",
+			Help: "Total number of shadow calls by result of the comparison with the primary call (match, code_mismatch or response_mismatch).",
+		},
+		[]string{"grpc_service", "grpc_method", "result"},
+	)
+
+	shadowSkipped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_shadow_skipped_total",
+			Help: "Total number of shadow calls skipped due to the concurrency limit.",
+		},
+		[]string{"grpc_service", "grpc_method"},
+	)
+)
+
+// Params represents the parameters of the traffic shadowing.
+type Params struct {
+	// Rate is the sampling rate of the calls mirrored to the shadow target, between 0 (none) and 1 (all).
+	Rate float64
+	// Methods are the full method names mirrored, required.
+	// The methods must be safe to be called twice, e.g. read-only.
+	Methods []string
+	// MetadataKeys are the request metadata keys forwarded to the shadow target, the other keys
+	// (e.g. "authorization") are dropped.
+	MetadataKeys []string
+	// Timeout is the time limit of the shadow calls, DefaultTimeout is used if zero.
+	Timeout time.Duration
+	// MaxConcurrency is the maximum number of shadow calls running at the same time, the calls over the limit
+	// are not mirrored. DefaultMaxConcurrency is used if zero.
+	MaxConcurrency int
+	// LogRate is the sample rate of the logs of the differences, from 0 to 1.
+	LogRate float64
+}
+
+// Shadow mirrors the calls to the shadow target.
+type Shadow struct {
+	conn    *grpc.ClientConn
+	p       Params
+	methods map[string]bool
+	slots   chan struct{}
+
+	random func() float64
+	// done is called once a shadow call is compared, it's only used in the tests.
+	done func()
+}
+
+// New returns a new shadow mirroring the calls to the connection of the shadow target,
+// returns ErrMethodsRequired if no method is given.
+func New(conn *grpc.ClientConn, p Params) (*Shadow, error) {
+	if len(p.Methods) == 0 {
+		return nil, ErrMethodsRequired
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = DefaultTimeout
+	}
+	if p.MaxConcurrency <= 0 {
+		p.MaxConcurrency = DefaultMaxConcurrency
+	}
+
+	methods := make(map[string]bool, len(p.Methods))
+	for _, m := range p.Methods {
+		methods[m] = true
+	}
+
+	return &Shadow{
+		conn:    conn,
+		p:       p,
+		methods: methods,
+		slots:   make(chan struct{}, p.MaxConcurrency),
+		random:  rand.Float64,
+		done:    func() {},
+	}, nil
+}
+
+// UnaryServerInterceptor returns a unary interceptor that mirrors the selected calls to the shadow target
+// once the primary call completes, the shadow calls never affect the primary ones.
+//
+// NOTE: Could be chained after the grpcerr interceptor, the primary errors are compared with the codes they're mapped into.
+func (s *Shadow) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if s == nil || !s.selected(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		// Serialize the request before the handler could modify it
+		b, err := protoutil.Marshal(req)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		resp, err := handler(ctx, req)
+
+		select {
+		case s.slots <- struct{}{}:
+			go s.mirror(coremiddleware.Logger(ctx), info.FullMethod, s.metadata(ctx), b, resp, err)
+		default:
+			service, method := protoutil.SplitMethodName(info.FullMethod)
+			shadowSkipped.With(prometheus.Labels{
+				"grpc_service": strings.ToLower(service),
+				"grpc_method":  strings.ToLower(method),
+			}).Inc()
+		}
+
+		return resp, err
+	}
+}
+
+// selected returns true if the call of the method is mirrored.
+func (s *Shadow) selected(fullMethod string) bool {
+	if !s.methods[fullMethod] {
+		return false
+	}
+
+	switch {
+	case s.p.Rate <= 0:
+		return false
+	case s.p.Rate >= 1:
+		return true
+	}
+
+	return s.random() < s.p.Rate
+}
+
+// metadata returns the request metadata forwarded to the shadow target.
+func (s *Shadow) metadata(ctx context.Context) metadata.MD {
+	in, _ := metadata.FromIncomingContext(ctx)
+
+	md := metadata.MD{}
+	for _, k := range s.p.MetadataKeys {
+		if values := in.Get(k); len(values) > 0 {
+			md.Set(k, values...)
+		}
+	}
+
+	return md
+}
+
+// mirror calls the shadow target with the request, and compares the response with the primary one.
+func (s *Shadow) mirror(l *logrus.Entry, fullMethod string, md metadata.MD, req []byte, resp interface{}, respErr error) {
+	defer func() {
+		<-s.slots
+		if r := recover(); r != nil {
+			l.WithField("panic", r).Error("Caught panic in shadow call")
+		}
+		s.done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.p.Timeout)
+	defer cancel()
+
+	md.Set(MetaKeyShadow, "true")
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	shadowResp := &protoutil.Frame{}
+	err := s.conn.Invoke(ctx, fullMethod, &protoutil.Frame{Bytes: req}, shadowResp, grpc.ForceCodec(protoutil.RawCodec{}))
+
+	// The primary error isn't mapped yet, unlike the error of the shadow target
+	primaryCode, shadowCode := grpcerr.Code(respErr), status.Code(err)
+	result := resultMatch
+	var diff []string
+	switch {
+	case primaryCode != shadowCode:
+		result = resultCodeMismatch
+	case primaryCode == codes.OK:
+		diff = diffFields(resp, shadowResp.Bytes)
+		if len(diff) > 0 {
+			result = resultResponseMismatch
+		}
+	}
+
+	service, method := protoutil.SplitMethodName(fullMethod)
+	shadowCalls.With(prometheus.Labels{
+		"grpc_service": strings.ToLower(service),
+		"grpc_method":  strings.ToLower(method),
+		"result":       result,
+	}).Inc()
+
+	if result != resultMatch && s.random() < s.p.LogRate {
+		fields := logrus.Fields{
+			"shadow_result": result,
+			"primary_code":  primaryCode.String(),
+			"shadow_code":   shadowCode.String(),
+		}
+		if len(diff) > 0 {
+			fields["diff_fields"] = diff
+		}
+		if err != nil {
+			fields["shadow_error"] = status.Convert(err).Message()
+		}
+		l.WithFields(fields).Warn("Shadow call differs from the primary call")
+	}
+}
+
+// diffFields returns the names of the top-level fields differing between the primary response
+// and the serialized shadow response, a nil slice if they're equal.
+func diffFields(resp interface{}, shadowResp []byte) []string {
+	m, ok := protoutil.Reflect(resp)
+	if !ok {
+		return nil
+	}
+
+	shadow := m.New()
+	if err := proto.Unmarshal(shadowResp, shadow.Interface()); err != nil {
+		return []string{"<unparsable>"}
+	}
+
+	return protoutil.DiffFields(m.Interface(), shadow.Interface())
+}
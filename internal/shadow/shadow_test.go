@@ -0,0 +1,206 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/metrictest"
+)
+
+const testMethod = "/template.v1.Echo/UnaryEcho"
+
+// shadowServer is the shadow target, it sends the metadata of the calls, upper cases the messages starting with "diff",
+// fails the messages starting with "fail", and blocks the messages starting with "slow". The messages starting with
+// "notfound" and "plain" fail with the statuses the grpcerr interceptor maps the domain and the plain errors into.
+type shadowServer struct {
+	templatev1.UnimplementedEchoServer
+
+	shadowed chan metadata.MD
+}
+
+func (s *shadowServer) UnaryEcho(ctx context.Context, req *templatev1.UnaryEchoRequest) (*templatev1.UnaryEchoResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.shadowed <- md
+
+	msg := req.GetMessage()
+	switch {
+	case strings.HasPrefix(msg, "diff"):
+		msg = strings.ToUpper(msg)
+	case strings.HasPrefix(msg, "fail"):
+		return nil, status.Error(codes.Internal, "failed")
+	case strings.HasPrefix(msg, "notfound"):
+		return nil, status.Error(codes.NotFound, "not found")
+	case strings.HasPrefix(msg, "plain"):
+		return nil, status.Error(codes.Internal, "InternalServerError")
+	case strings.HasPrefix(msg, "slow"):
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+
+	return &templatev1.UnaryEchoResponse{Message: msg}, nil
+}
+
+func dialShadow(t *testing.T, srv templatev1.EchoServer) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	templatev1.RegisterEchoServer(s, srv)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	srv := &shadowServer{shadowed: make(chan metadata.MD, 10)}
+	s, err := New(dialShadow(t, srv), Params{
+		Rate:         1,
+		Methods:      []string{testMethod},
+		MetadataKeys: []string{"accept-language"},
+		Timeout:      50 * time.Millisecond,
+		LogRate:      1,
+	})
+	require.NoError(t, err)
+	done := make(chan struct{}, 10)
+	s.done = func() {
+		done <- struct{}{}
+	}
+
+	primary := func(ctx context.Context, req interface{}) (interface{}, error) {
+		msg := req.(*templatev1.UnaryEchoRequest).GetMessage()
+		switch {
+		case strings.HasPrefix(msg, "error"):
+			return nil, status.Error(codes.Internal, "failed")
+		case strings.HasPrefix(msg, "notfound"):
+			return nil, fmt.Errorf("device: %w", grpcerr.ErrNotFound)
+		case strings.HasPrefix(msg, "plain"):
+			return nil, errors.New("pq: connection refused")
+		}
+
+		return &templatev1.UnaryEchoResponse{Message: msg}, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+
+	tests := []struct {
+		message string
+		result  string
+		failed  bool
+	}{
+		{message: "hello", result: resultMatch},
+		{message: "diff", result: resultResponseMismatch},
+		{message: "fail", result: resultCodeMismatch},
+		{message: "error", result: resultCodeMismatch, failed: true},
+		{message: "slow", result: resultCodeMismatch},
+		{message: "notfound", result: resultMatch, failed: true},
+		{message: "plain", result: resultMatch, failed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			c := shadowCalls.WithLabelValues("template.v1.echo", "unaryecho", tt.result)
+			before := metrictest.CounterValue(t, c)
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "en", "authorization", "Bearer secret"))
+			start := time.Now()
+			resp, err := s.UnaryServerInterceptor()(ctx, &templatev1.UnaryEchoRequest{Message: tt.message}, info, primary)
+			assert.Less(t, int64(time.Since(start)), int64(50*time.Millisecond), "Must not wait for the shadow call")
+			if tt.failed {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.message, resp.(*templatev1.UnaryEchoResponse).GetMessage(), "Must return the primary response")
+			}
+
+			select {
+			case md := <-srv.shadowed:
+				assert.Equal(t, []string{"true"}, md.Get(MetaKeyShadow))
+				assert.Equal(t, []string{"en"}, md.Get("accept-language"))
+				assert.Empty(t, md.Get("authorization"), "Must not forward the metadata not listed")
+			case <-time.After(time.Second):
+				require.Fail(t, "Shadow call not received")
+			}
+			<-done
+			assert.Equal(t, before+1, metrictest.CounterValue(t, c))
+		})
+	}
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	srv := &shadowServer{shadowed: make(chan metadata.MD, 10)}
+	s, err := New(dialShadow(t, srv), Params{
+		Rate:           1,
+		Methods:        []string{testMethod},
+		Timeout:        time.Minute,
+		MaxConcurrency: 1,
+	})
+	require.NoError(t, err)
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+	primary := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &templatev1.UnaryEchoResponse{}, nil
+	}
+	skipped := shadowSkipped.WithLabelValues("template.v1.echo", "unaryecho")
+	before := metrictest.CounterValue(t, skipped)
+
+	_, err = s.UnaryServerInterceptor()(context.Background(), &templatev1.UnaryEchoRequest{Message: "slow"}, info, primary)
+	require.NoError(t, err)
+	<-srv.shadowed
+
+	_, err = s.UnaryServerInterceptor()(context.Background(), &templatev1.UnaryEchoRequest{Message: "hello"}, info, primary)
+	require.NoError(t, err)
+	assert.Equal(t, before+1, metrictest.CounterValue(t, skipped))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, Params{Rate: 1})
+	assert.ErrorIs(t, err, ErrMethodsRequired)
+}
+
+func TestSelected(t *testing.T) {
+	s, err := New(nil, Params{Rate: 0.1, Methods: []string{testMethod}})
+	require.NoError(t, err)
+
+	s.random = func() float64 { return 0.05 }
+	assert.True(t, s.selected(testMethod))
+	assert.False(t, s.selected("/template.v1.Echo/Other"))
+
+	s.random = func() float64 { return 0.2 }
+	assert.False(t, s.selected(testMethod))
+
+	_, err = (*Shadow)(nil).UnaryServerInterceptor()(context.Background(), &templatev1.UnaryEchoRequest{}, &grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		})
+	assert.NoError(t, err, "Must be nil safe")
+}
+
+func TestDiffFields(t *testing.T) {
+	assert.Equal(t, []string{"message"}, diffFields(&templatev1.UnaryEchoResponse{Message: "a"}, nil))
+	assert.Nil(t, diffFields(&templatev1.UnaryEchoResponse{}, nil))
+	assert.Equal(t, []string{"<unparsable>"}, diffFields(&templatev1.UnaryEchoResponse{}, []byte{0xff}))
+	assert.Nil(t, diffFields(struct{}{}, nil), "Must skip the non proto responses")
+}
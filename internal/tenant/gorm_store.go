@@ -0,0 +1,134 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tenantUsage is the database model of the number of requests of a tenant in a day or a month.
+type tenantUsage struct {
+	Tenant string `gorm:"primaryKey;size:64"`
+	// Period is either a day "2021-03-01" or a month "2021-03".
+	Period    string `gorm:"primaryKey;size:10"`
+	Requests  int64  `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+func (tenantUsage) TableName() string {
+	return "tenant_usage"
+}
+
+// GormStore is a Store backed by the database, the usage is shared across the instances.
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore returns a new store backed by the database, and migrates the table.
+func NewGormStore(db *gorm.DB) (*GormStore, error) {
+	if err := db.AutoMigrate(&tenantUsage{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate tenant usage: %w", err)
+	}
+
+	return &GormStore{db: db}, nil
+}
+
+// Consume implements the Store interface.
+func (s *GormStore) Consume(ctx context.Context, tenant string, t time.Time, quota Quota) (Usage, error) {
+	day, month := periods(t)
+	limits := []struct {
+		period string
+		limit  int64
+		err    error
+	}{
+		{period: day, limit: quota.Daily, err: ErrDailyQuotaExceeded},
+		{period: month, limit: quota.Monthly, err: ErrMonthlyQuotaExceeded},
+	}
+
+	// The daily count is rolled back if the monthly quota is exceeded
+	exceeded := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, l := range limits {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tenantUsage{
+				Tenant: tenant,
+				Period: l.period,
+			}).Error
+			if err != nil {
+				return err
+			}
+
+			q := tx.Model(&tenantUsage{}).Where("tenant = ? AND period = ?", tenant, l.period)
+			if l.limit > 0 {
+				q = q.Where("requests < ?", l.limit)
+			}
+			res := q.Updates(map[string]interface{}{
+				"requests":   gorm.Expr("requests + 1"),
+				"updated_at": time.Now(),
+			})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return l.err
+			}
+		}
+
+		return nil
+	})
+	if exceeded != nil && !errors.Is(exceeded, ErrDailyQuotaExceeded) && !errors.Is(exceeded, ErrMonthlyQuotaExceeded) {
+		return Usage{}, exceeded
+	}
+
+	usages, err := s.Usage(ctx, t, tenant)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	return usages[0], exceeded
+}
+
+// Usage implements the Store interface.
+func (s *GormStore) Usage(ctx context.Context, t time.Time, tenants ...string) ([]Usage, error) {
+	day, month := periods(t)
+
+	q := s.db.WithContext(ctx).Where("period IN ?", []string{day, month})
+	if len(tenants) > 0 {
+		q = q.Where("tenant IN ?", tenants)
+	}
+
+	var rows []tenantUsage
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	usages := make(map[string]*Usage, len(tenants))
+	for _, tenant := range tenants {
+		usages[tenant] = &Usage{Tenant: tenant, Day: day, Month: month}
+	}
+	for _, r := range rows {
+		u, ok := usages[r.Tenant]
+		if !ok {
+			u = &Usage{Tenant: r.Tenant, Day: day, Month: month}
+			usages[r.Tenant] = u
+		}
+		if r.Period == day {
+			u.Daily = r.Requests
+		} else {
+			u.Monthly = r.Requests
+		}
+	}
+
+	result := make([]Usage, 0, len(usages))
+	for _, u := range usages {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Tenant < result[j].Tenant
+	})
+
+	return result, nil
+}
@@ -0,0 +1,281 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	grpcmiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/identity"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+// Periods of the quotas used in the metrics.
+const (
+	periodDaily   = "daily"
+	periodMonthly = "monthly"
+)
+
+// otherTenant is the metric label of the tenants not listed in the quotas.
+const otherTenant = "other"
+
+var (
+	tenantRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_tenant_requests_total",
+			Help: "Total number of RPCs completed on the server by tenant.",
+		},
+		[]string{"tenant", "grpc_service", "grpc_method", "grpc_code"},
+	)
+
+	tenantUsageGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "grpc_tenant_quota_used",
+			Help: "Number of requests counted in the current daily or monthly quota of the tenant.",
+		},
+		[]string{"tenant", "period"},
+	)
+
+	tenantQuotaExceeded = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_tenant_quota_exceeded_total",
+			Help: "Total number of calls rejected due to the daily or monthly quota of the tenant.",
+		},
+		[]string{"tenant", "period"},
+	)
+)
+
+// Params represents the parameters of the tenancy interceptors.
+type Params struct {
+	// Header is the request metadata key of the tenant, MetaKeyTenant is used if empty.
+	// The header of the anonymous callers is only accepted for the tenants listed in the quotas.
+	Header string
+	// Claim is the claim of the authenticated callers holding their tenant, DefaultClaim is used if empty.
+	// The claim takes precedence over the header, and the calls with a different header are rejected.
+	Claim string
+	// Required rejects the calls without a tenant, they're not counted in any quota otherwise.
+	Required bool
+	// Quotas are the request quotas of the tenants, the other tenants are reported as "other" in the metrics.
+	Quotas Quotas
+	// Store counts the requests of the tenants, the quotas are not enforced if nil.
+	Store Store
+}
+
+// Tenancy identifies the tenant of the calls and enforces their quotas.
+type Tenancy struct {
+	p Params
+
+	now func() time.Time
+}
+
+// New returns a new tenancy of the given params.
+func New(p Params) *Tenancy {
+	if p.Header == "" {
+		p.Header = MetaKeyTenant
+	}
+	if p.Claim == "" {
+		p.Claim = DefaultClaim
+	}
+
+	return &Tenancy{
+		p:   p,
+		now: time.Now,
+	}
+}
+
+// Usage returns the usage and the quotas of the tenants, all tenants having requests in the current month if empty.
+func (t *Tenancy) Usage(ctx context.Context, tenants ...string) ([]Usage, error) {
+	if t == nil || t.p.Store == nil {
+		return nil, nil
+	}
+
+	usages, err := t.p.Store.Usage(ctx, t.now(), tenants...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range usages {
+		usages[i].Quota = t.p.Quotas.Of(usages[i].Tenant)
+	}
+
+	return usages, nil
+}
+
+// UnaryServerInterceptor returns a unary interceptor that sets the tenant into the context and the entry log,
+// and rejects the calls exceeding the quota of the tenant.
+//
+// NOTE: Must be chained after the EntryLogs interceptor, the authentication and the validation, and before the cache.
+func (t *Tenancy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if t == nil {
+			return handler(ctx, req)
+		}
+
+		tenant, err := t.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tenant == "" {
+			return handler(ctx, req)
+		}
+
+		ctx = t.newContext(ctx, tenant)
+		_ = coremiddleware.AppendFieldIntoEntryLogger(ctx, "tenant", tenant)
+
+		var resp interface{}
+		err = t.consume(ctx, tenant)
+		if err == nil {
+			resp, err = handler(ctx, req)
+		}
+		countRequest(t.label(tenant), info.FullMethod, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that sets the tenant into the context,
+// and rejects the streams exceeding the quota of the tenant, a stream is counted as one request.
+//
+// NOTE: Must be chained after the authentication and the validation.
+func (t *Tenancy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if t == nil {
+			return handler(srv, ss)
+		}
+
+		tenant, err := t.resolve(ss.Context())
+		if err != nil {
+			return err
+		}
+		if tenant == "" {
+			return handler(srv, ss)
+		}
+
+		ctx := t.newContext(ss.Context(), tenant)
+		err = t.consume(ctx, tenant)
+		if err == nil {
+			wrapped := grpcmiddleware.WrapServerStream(ss)
+			wrapped.WrappedContext = ctx
+			err = handler(srv, wrapped)
+		}
+		countRequest(t.label(tenant), info.FullMethod, err)
+
+		return err
+	}
+}
+
+// resolve returns the tenant of the call from the claims of the caller or the request metadata,
+// empty if the call has no tenant and it's not required. The tenants of the anonymous callers must be listed in the quotas.
+func (t *Tenancy) resolve(ctx context.Context) (string, error) {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(t.p.Header); len(values) > 0 {
+			header = strings.TrimSpace(values[0])
+		}
+	}
+
+	tenant := identity.ClaimsFromContext(ctx)[t.p.Claim]
+	claimed := tenant != ""
+	switch {
+	case claimed && header != "" && header != tenant:
+		return "", grpcerr.New(grpcerr.ErrPermissionDenied, "TENANT_MISMATCH", "Tenant does not match the credentials")
+	case !claimed:
+		tenant = header
+	}
+
+	if tenant == "" {
+		if t.p.Required {
+			return "", grpcerr.New(grpcerr.ErrInvalidArgument, "TENANT_REQUIRED", "Tenant is required").
+				WithMetadata("header", t.p.Header)
+		}
+
+		return "", nil
+	}
+	if !validTenant.MatchString(tenant) {
+		return "", grpcerr.New(grpcerr.ErrInvalidArgument, "TENANT_INVALID", "Invalid tenant")
+	}
+	// Anyone can send the header, so only the known tenants are accepted from the anonymous callers
+	if _, ok := identity.FromContext(ctx); !ok && !claimed && !t.p.Quotas.Listed(tenant) {
+		return "", grpcerr.New(grpcerr.ErrPermissionDenied, "TENANT_UNKNOWN", "Unknown tenant")
+	}
+
+	return tenant, nil
+}
+
+// label returns the metric label of the tenant, otherTenant if it's not listed in the quotas.
+func (t *Tenancy) label(tenant string) string {
+	if t.p.Quotas.Listed(tenant) {
+		return tenant
+	}
+
+	return otherTenant
+}
+
+// newContext returns a new context which sets the tenant, and adds it into the logger.
+func (t *Tenancy) newContext(ctx context.Context, tenant string) context.Context {
+	ctx = NewContext(ctx, tenant)
+
+	return coremiddleware.NewContextWithLogger(ctx, coremiddleware.Logger(ctx).WithField("tenant", tenant))
+}
+
+// consume counts a request of the tenant, returns a ResourceExhausted error if the quota is exceeded.
+// The calls are allowed if the store fails.
+func (t *Tenancy) consume(ctx context.Context, tenant string) error {
+	if t.p.Store == nil {
+		return nil
+	}
+
+	now := t.now()
+	u, err := t.p.Store.Consume(ctx, tenant, now, t.p.Quotas.Of(tenant))
+
+	var period string
+	var reset time.Time
+	switch {
+	case errors.Is(err, ErrDailyQuotaExceeded):
+		period = periodDaily
+		y, m, d := now.UTC().Date()
+		reset = time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+	case errors.Is(err, ErrMonthlyQuotaExceeded):
+		period = periodMonthly
+		y, m, _ := now.UTC().Date()
+		reset = time.Date(y, m+1, 1, 0, 0, 0, 0, time.UTC)
+	case err != nil:
+		coremiddleware.Logger(ctx).WithError(err).Warn("Failed to count the request in the tenant quota")
+
+		return nil
+	}
+
+	// The usage of the other tenants is not meaningful as a single gauge.
+	if label := t.label(tenant); label != otherTenant {
+		tenantUsageGauge.WithLabelValues(label, periodDaily).Set(float64(u.Daily))
+		tenantUsageGauge.WithLabelValues(label, periodMonthly).Set(float64(u.Monthly))
+	}
+	if period == "" {
+		return nil
+	}
+
+	tenantQuotaExceeded.WithLabelValues(t.label(tenant), period).Inc()
+
+	return grpcerr.New(grpcerr.ErrResourceExhausted, "TENANT_QUOTA_EXCEEDED", "Tenant quota exceeded").
+		WithMetadata("period", period).
+		WithRetryDelay(reset.Sub(now))
+}
+
+// countRequest counts the request of the tenant, the handler errors are not converted into statuses yet,
+// so their code is resolved like the grpcerr interceptor does.
+func countRequest(tenant, fullMethod string, err error) {
+	service, method := protoutil.SplitMethodName(fullMethod)
+	tenantRequests.With(prometheus.Labels{
+		"tenant":       tenant,
+		"grpc_service": strings.ToLower(service),
+		"grpc_method":  strings.ToLower(method),
+		"grpc_code":    strings.ToLower(grpcerr.Code(err).String()),
+	}).Inc()
+}
@@ -0,0 +1,183 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/identity"
+	"github.com/sliide/template-grpc-service/internal/metrictest"
+)
+
+// failingStore is a Store failing all operations.
+type failingStore struct{}
+
+func (failingStore) Consume(context.Context, string, time.Time, Quota) (Usage, error) {
+	return Usage{}, errors.New("unavailable")
+}
+
+func (failingStore) Usage(context.Context, time.Time, ...string) ([]Usage, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	listed := Quotas{Tenants: map[string]Quota{"team-a": {}}}
+
+	tests := []struct {
+		name     string
+		params   Params
+		header   string
+		caller   string
+		claims   identity.Claims
+		expected string
+		code     codes.Code
+	}{
+		{name: "no tenant", expected: "", code: codes.OK},
+		{name: "required tenant", params: Params{Required: true}, code: codes.InvalidArgument},
+		{name: "tenant from header", params: Params{Quotas: listed}, header: "team-a", expected: "team-a", code: codes.OK},
+		{name: "unknown tenant from header", header: "team-a", code: codes.PermissionDenied},
+		{name: "unknown tenant from header of authenticated caller", header: "team-a", caller: "service-a", expected: "team-a", code: codes.OK},
+		{name: "tenant from claims", claims: identity.Claims{"tenant": "team-b"}, expected: "team-b", code: codes.OK},
+		{name: "same tenant in claims and header", header: "team-b", claims: identity.Claims{"tenant": "team-b"}, expected: "team-b", code: codes.OK},
+		{name: "different tenant in header", header: "team-a", claims: identity.Claims{"tenant": "team-b"}, code: codes.PermissionDenied},
+		{name: "custom claim", params: Params{Claim: "org"}, claims: identity.Claims{"org": "team-c"}, expected: "team-c", code: codes.OK},
+		{name: "invalid tenant", header: "team a", code: codes.InvalidArgument},
+		{name: "daily quota exceeded", params: Params{Quotas: Quotas{Tenants: map[string]Quota{"team-a": {Daily: 1}}}}, header: "team-a", code: codes.ResourceExhausted},
+		{name: "store unavailable", params: Params{Quotas: Quotas{Tenants: map[string]Quota{"team-a": {Daily: 1}}}, Store: failingStore{}}, header: "team-a", expected: "team-a", code: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.params.Store == nil {
+				tt.params.Store = NewMemoryStore()
+				// Exhaust the daily quota
+				_, _ = tt.params.Store.Consume(context.Background(), "team-a", time.Now(), tt.params.Quotas.Of("team-a"))
+			}
+
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetaKeyTenant, tt.header))
+			}
+			if tt.caller != "" {
+				ctx = identity.NewContext(ctx, tt.caller)
+			}
+			if tt.claims != nil {
+				ctx = identity.NewContextWithClaims(ctx, tt.claims)
+			}
+
+			var actual string
+			_, err := New(tt.params).UnaryServerInterceptor()(ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					actual, _ = FromContext(ctx)
+
+					return req, nil
+				})
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("Nil tenancy", func(t *testing.T) {
+		_, err := (*Tenancy)(nil).UnaryServerInterceptor()(context.Background(), struct{}{}, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				return req, nil
+			})
+		assert.NoError(t, err)
+	})
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	tenancy := New(Params{
+		Quotas: Quotas{Tenants: map[string]Quota{"team-a": {Daily: 2, Monthly: 1}}},
+		Store:  NewMemoryStore(),
+	})
+	tenancy.now = func() time.Time {
+		return time.Date(2021, 3, 31, 18, 0, 0, 0, time.UTC)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetaKeyTenant, "team-a"))
+	interceptor := tenancy.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	}
+
+	_, err := interceptor(ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}, handler)
+	require.NoError(t, err)
+
+	_, err = interceptor(ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, grpcerr.Details(err), grpcerr.RetryInfo{RetryDelay: 6 * time.Hour}, "Must retry next month")
+
+	usages, err := tenancy.Usage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Usage{{
+		Tenant:  "team-a",
+		Day:     "2021-03-31",
+		Daily:   1,
+		Month:   "2021-03",
+		Monthly: 1,
+		Quota:   Quota{Daily: 2, Monthly: 1},
+	}}, usages)
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	tenancy := New(Params{
+		Quotas: Quotas{Default: Quota{Daily: 1}},
+		Store:  NewMemoryStore(),
+	})
+	ss := &testServerStream{ctx: identity.NewContextWithClaims(context.Background(), identity.Claims{"tenant": "team-a"})}
+
+	var actual string
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		actual, _ = FromContext(stream.Context())
+
+		return nil
+	}
+
+	require.NoError(t, tenancy.StreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}, handler))
+	assert.Equal(t, "team-a", actual)
+
+	err := tenancy.StreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch"}, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestLabel(t *testing.T) {
+	tenancy := New(Params{Quotas: Quotas{Tenants: map[string]Quota{"team-a": {Daily: 1}}}})
+
+	assert.Equal(t, "team-a", tenancy.label("team-a"))
+	assert.Equal(t, otherTenant, tenancy.label("team-b"))
+}
+
+func TestCountRequest(t *testing.T) {
+	tenancy := New(Params{Quotas: Quotas{Tenants: map[string]Quota{"team-a": {}}}})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetaKeyTenant, "team-a"))
+	requests := tenantRequests.WithLabelValues("team-a", "test.service", "get", "notfound")
+
+	before := metrictest.CounterValue(t, requests)
+	_, err := tenancy.UnaryServerInterceptor()(ctx, struct{}{}, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("get: %w", grpcerr.New(grpcerr.ErrNotFound, "", "not found"))
+		})
+	require.Error(t, err)
+
+	assert.Equal(t, before+1, metrictest.CounterValue(t, requests), "Must count the code of the wrapped error")
+}
@@ -0,0 +1,140 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// dayLayout and monthLayout format the UTC periods of the quotas.
+	dayLayout   = "2006-01-02"
+	monthLayout = "2006-01"
+)
+
+var (
+	// ErrDailyQuotaExceeded is returned when the tenant has reached its daily quota.
+	ErrDailyQuotaExceeded = errors.New("daily quota exceeded")
+	// ErrMonthlyQuotaExceeded is returned when the tenant has reached its monthly quota.
+	ErrMonthlyQuotaExceeded = errors.New("monthly quota exceeded")
+)
+
+// Usage represents the numbers of requests of a tenant in the current UTC day and month.
+type Usage struct {
+	Tenant string
+	// Day is the current day, e.g. "2021-03-01".
+	Day   string
+	Daily int64
+	// Month is the current month, e.g. "2021-03".
+	Month   string
+	Monthly int64
+	// Quota is the quota of the tenant, it's only set by Tenancy.
+	Quota Quota
+}
+
+// Store persists the numbers of requests of the tenants.
+type Store interface {
+	// Consume counts a request of the tenant at the time if it doesn't exceed the quota,
+	// returns the usage including the request, or ErrDailyQuotaExceeded or ErrMonthlyQuotaExceeded
+	// with the current usage otherwise.
+	Consume(ctx context.Context, tenant string, t time.Time, quota Quota) (Usage, error)
+
+	// Usage returns the usage of the tenants at the time sorted by tenant, all tenants having requests if empty.
+	Usage(ctx context.Context, t time.Time, tenants ...string) ([]Usage, error)
+}
+
+// periods returns the UTC day and month of the time.
+func periods(t time.Time) (day, month string) {
+	t = t.UTC()
+
+	return t.Format(dayLayout), t.Format(monthLayout)
+}
+
+// MemoryStore is an in-memory Store, the usage is neither shared across the instances nor kept after a restart.
+type MemoryStore struct {
+	m sync.Mutex
+	// counts are the numbers of requests by period and by tenant.
+	counts map[string]map[string]int64
+}
+
+// NewMemoryStore returns a new in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counts: make(map[string]map[string]int64),
+	}
+}
+
+// Consume implements the Store interface.
+func (s *MemoryStore) Consume(_ context.Context, tenant string, t time.Time, quota Quota) (Usage, error) {
+	day, month := periods(t)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	// Drop the previous periods
+	for period := range s.counts {
+		if period != day && period != month {
+			delete(s.counts, period)
+		}
+	}
+
+	u := s.usage(tenant, day, month)
+	if quota.Daily > 0 && u.Daily >= quota.Daily {
+		return u, ErrDailyQuotaExceeded
+	}
+	if quota.Monthly > 0 && u.Monthly >= quota.Monthly {
+		return u, ErrMonthlyQuotaExceeded
+	}
+
+	for _, period := range []string{day, month} {
+		if s.counts[period] == nil {
+			s.counts[period] = make(map[string]int64)
+		}
+		s.counts[period][tenant]++
+	}
+	u.Daily++
+	u.Monthly++
+
+	return u, nil
+}
+
+// Usage implements the Store interface.
+func (s *MemoryStore) Usage(_ context.Context, t time.Time, tenants ...string) ([]Usage, error) {
+	day, month := periods(t)
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if len(tenants) == 0 {
+		seen := make(map[string]bool)
+		for _, period := range []string{day, month} {
+			for tenant := range s.counts[period] {
+				if !seen[tenant] {
+					seen[tenant] = true
+					tenants = append(tenants, tenant)
+				}
+			}
+		}
+	}
+	sort.Strings(tenants)
+
+	usages := make([]Usage, 0, len(tenants))
+	for _, tenant := range tenants {
+		usages = append(usages, s.usage(tenant, day, month))
+	}
+
+	return usages, nil
+}
+
+// usage returns the usage of the tenant, must be called with the lock held.
+func (s *MemoryStore) usage(tenant, day, month string) Usage {
+	return Usage{
+		Tenant:  tenant,
+		Day:     day,
+		Daily:   s.counts[day][tenant],
+		Month:   month,
+		Monthly: s.counts[month][tenant],
+	}
+}
@@ -0,0 +1,52 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	now := time.Date(2021, 3, 31, 23, 0, 0, 0, time.UTC)
+	quota := Quota{Daily: 2, Monthly: 3}
+
+	u, err := s.Consume(ctx, "team-a", now, quota)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Tenant: "team-a", Day: "2021-03-31", Daily: 1, Month: "2021-03", Monthly: 1}, u)
+
+	_, err = s.Consume(ctx, "team-a", now, quota)
+	require.NoError(t, err)
+	u, err = s.Consume(ctx, "team-a", now, quota)
+	assert.ErrorIs(t, err, ErrDailyQuotaExceeded)
+	assert.Equal(t, int64(2), u.Daily, "Must not count the rejected requests")
+
+	_, err = s.Consume(ctx, "team-b", now, Quota{})
+	require.NoError(t, err, "Must be unlimited without quota")
+
+	usages, err := s.Usage(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []Usage{
+		{Tenant: "team-a", Day: "2021-03-31", Daily: 2, Month: "2021-03", Monthly: 2},
+		{Tenant: "team-b", Day: "2021-03-31", Daily: 1, Month: "2021-03", Monthly: 1},
+	}, usages)
+
+	// The next day in the same month, in another time zone
+	nextDay := time.Date(2021, 3, 31, 21, 0, 0, 0, time.FixedZone("UTC-4", -4*3600))
+	u, err = s.Consume(ctx, "team-a", nextDay, quota)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{Tenant: "team-a", Day: "2021-04-01", Daily: 1, Month: "2021-04", Monthly: 1}, u)
+
+	u, err = s.Consume(ctx, "team-a", nextDay, Quota{Monthly: 1})
+	assert.ErrorIs(t, err, ErrMonthlyQuotaExceeded)
+	assert.Equal(t, int64(1), u.Monthly)
+
+	usages, err = s.Usage(ctx, nextDay, "team-c")
+	require.NoError(t, err)
+	assert.Equal(t, []Usage{{Tenant: "team-c", Day: "2021-04-01", Month: "2021-04"}}, usages)
+	assert.Len(t, s.counts, 2, "Must drop the previous periods")
+}
@@ -0,0 +1,110 @@
+// Package tenant identifies the tenant of the calls, and enforces the daily and monthly request quotas of the tenants.
+package tenant
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MetaKeyTenant represents the default meta key to get the tenant from client's request.
+	MetaKeyTenant = "x-tenant-id"
+
+	// DefaultClaim is the default claim of the authenticated callers holding their tenant.
+	DefaultClaim = "tenant"
+)
+
+// validTenant matches the valid tenant IDs, they're used in the metric labels and the database keys.
+var validTenant = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+type ctxTenantKey struct{}
+
+// NewContext returns a new context which sets the tenant of the call.
+func NewContext(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, ctxTenantKey{}, tenant)
+}
+
+// FromContext returns the tenant of the call from the context.
+func FromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(ctxTenantKey{}).(string)
+	if !ok || tenant == "" {
+		return "", false
+	}
+
+	return tenant, true
+}
+
+// Quota represents the maximum numbers of requests of a tenant per UTC day and month, unlimited if zero.
+type Quota struct {
+	Daily   int64 `yaml:"daily" json:"daily"`
+	Monthly int64 `yaml:"monthly" json:"monthly"`
+}
+
+// Quotas represents the request quotas of the tenants.
+type Quotas struct {
+	// Default is the quota of the tenants which are not listed.
+	Default Quota `yaml:"default" json:"default"`
+	// Tenants are the quotas by tenant.
+	Tenants map[string]Quota `yaml:"tenants" json:"tenants"`
+}
+
+// Of returns the quota of the tenant.
+func (q Quotas) Of(tenant string) Quota {
+	if quota, ok := q.Tenants[tenant]; ok {
+		return quota
+	}
+
+	return q.Default
+}
+
+// Listed returns true if the tenant has its own quota.
+func (q Quotas) Listed(tenant string) bool {
+	_, ok := q.Tenants[tenant]
+
+	return ok
+}
+
+// LoadQuotasFile loads the quotas from a YAML (or JSON) file in the following format.
+//
+//	default:
+//	  daily: 10000
+//	tenants:
+//	  team-a:
+//	    daily: 100000
+//	    monthly: 2000000
+func LoadQuotasFile(path string) (Quotas, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Quotas{}, fmt.Errorf("failed to read tenant quotas: %w", err)
+	}
+
+	q := Quotas{}
+
+	// The unknown keys are rejected, a misspelled quota would silently leave the tenant unlimited
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&q); err != nil && !errors.Is(err, io.EOF) {
+		return Quotas{}, fmt.Errorf("failed to parse tenant quotas: %w", err)
+	}
+
+	for tenant, quota := range q.Tenants {
+		if !validTenant.MatchString(tenant) {
+			return Quotas{}, fmt.Errorf("invalid tenant: %q", tenant)
+		}
+		if quota.Daily < 0 || quota.Monthly < 0 {
+			return Quotas{}, fmt.Errorf("negative quota of the tenant %s", tenant)
+		}
+	}
+	if q.Default.Daily < 0 || q.Default.Monthly < 0 {
+		return Quotas{}, fmt.Errorf("negative default quota")
+	}
+
+	return q, nil
+}
@@ -0,0 +1,83 @@
+package tenant
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	tenant, ok := FromContext(NewContext(context.Background(), "team-a"))
+	assert.True(t, ok)
+	assert.Equal(t, "team-a", tenant)
+}
+
+func TestLoadQuotasFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Quotas
+		hasError bool
+	}{
+		{
+			name: "valid quotas",
+			content: `
+default:
+  daily: 100
+tenants:
+  team-a:
+    daily: 1000
+    monthly: 20000
+`,
+			expected: Quotas{
+				Default: Quota{Daily: 100},
+				Tenants: map[string]Quota{"team-a": {Daily: 1000, Monthly: 20000}},
+			},
+		},
+		{
+			name:     "invalid tenant",
+			content:  "tenants:\n  'team a':\n    daily: 1\n",
+			hasError: true,
+		},
+		{
+			name:     "negative quota",
+			content:  "default:\n  monthly: -1\n",
+			hasError: true,
+		},
+		{
+			name:     "unknown key",
+			content:  "tenants:\n  team-a:\n    dialy: 1\n",
+			hasError: true,
+		},
+		{
+			name:     "invalid file",
+			content:  "- invalid",
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "quotas.yaml")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tt.content), 0o600))
+
+			q, err := LoadQuotasFile(path)
+			if tt.hasError {
+				assert.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, q)
+			assert.Equal(t, Quota{Daily: 1000, Monthly: 20000}, q.Of("team-a"))
+			assert.Equal(t, Quota{Daily: 100}, q.Of("team-b"))
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package validation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+// FieldRules binds a set of rules to a field of the request message.
+type FieldRules struct {
+	// Field is the path of the field, nested fields are separated by dots, e.g. "user.name".
+	Field string
+	Rules []Rule
+}
+
+// Field returns the rules of the given field path.
+func Field(path string, rules ...Rule) FieldRules {
+	return FieldRules{
+		Field: path,
+		Rules: rules,
+	}
+}
+
+// MethodRules maps the full gRPC method names, e.g. "/template.v2.Echo/UnaryEcho", to the field rules.
+type MethodRules map[string][]FieldRules
+
+// Merge returns new rules containing the rules of both r and other.
+func (r MethodRules) Merge(other MethodRules) MethodRules {
+	merged := make(MethodRules, len(r)+len(other))
+	for method, fields := range r {
+		merged[method] = append(merged[method], fields...)
+	}
+	for method, fields := range other {
+		merged[method] = append(merged[method], fields...)
+	}
+
+	return merged
+}
+
+// Check returns an error if a method isn't registered, or a field path doesn't resolve to a field of the request
+// message of its method, so a typo in the rules fails instead of disabling the validation silently.
+func (r MethodRules) Check() error {
+	methods := make([]string, 0, len(r))
+	for method := range r {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	for _, method := range methods {
+		input, err := requestDescriptor(method)
+		if err != nil {
+			return err
+		}
+		for _, f := range r[method] {
+			if err := checkField(input, f.Field); err != nil {
+				return fmt.Errorf("invalid rules of %s %s: %w", method, f.Field, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// requestDescriptor returns the descriptor of the request message of the full method name.
+func requestDescriptor(fullMethod string) (protoreflect.MessageDescriptor, error) {
+	md, err := protoutil.MethodDescriptor(fullMethod)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rules of %s: %w", fullMethod, err)
+	}
+
+	return md.Input(), nil
+}
+
+// checkField returns an error if the path doesn't resolve to a field of the message,
+// the nested fields must be singular messages as in resolveField.
+func checkField(m protoreflect.MessageDescriptor, path string) error {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := m.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("unknown field %s of %s", name, m.FullName())
+		}
+		if i == len(names)-1 {
+			break
+		}
+		if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+			return fmt.Errorf("field %s of %s is not a singular message", name, m.FullName())
+		}
+		m = fd.Message()
+	}
+
+	return nil
+}
+
+// fieldConfig represents the rules of a field in the configuration file.
+type fieldConfig struct {
+	Required  bool     `yaml:"required"`
+	MinLength int      `yaml:"min_length"`
+	MaxLength int      `yaml:"max_length"`
+	Pattern   string   `yaml:"pattern"`
+	Enum      []string `yaml:"enum"`
+	UTF8      bool     `yaml:"utf8"`
+}
+
+// LoadFile loads the rules from a YAML (or JSON) file in the following format,
+// returns an error if a method or a field of the rules is unknown, see MethodRules.Check.
+//
+//	/template.v2.Echo/UnaryEcho:
+//	  message:
+//	    required: true
+//	    min_length: 1
+//	    max_length: 499
+//	    pattern: "^[a-z-]+$"
+//	    enum: [hello, world]
+//	    utf8: true
+func LoadFile(path string) (MethodRules, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read validation rules: %w", err)
+	}
+
+	rules, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, rules.Check()
+}
+
+// Parse parses the rules from YAML (or JSON) content, see LoadFile for the format.
+func Parse(b []byte) (MethodRules, error) {
+	cfg := map[string]map[string]fieldConfig{}
+
+	// The unknown rules are rejected, a misspelled rule would silently enforce nothing
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse validation rules: %w", err)
+	}
+
+	rules := make(MethodRules, len(cfg))
+	for method, fields := range cfg {
+		// Sort the fields to keep the order of violations stable
+		paths := make([]string, 0, len(fields))
+		for path := range fields {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			fr, err := fields[path].fieldRules(path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rules of %s %s: %w", method, path, err)
+			}
+			rules[method] = append(rules[method], fr)
+		}
+	}
+
+	return rules, nil
+}
+
+func (c fieldConfig) fieldRules(path string) (FieldRules, error) {
+	var rules []Rule
+	if c.Required {
+		rules = append(rules, Required{})
+	}
+	if c.MinLength > 0 || c.MaxLength > 0 {
+		rules = append(rules, Length{Min: c.MinLength, Max: c.MaxLength})
+	}
+	if c.Pattern != "" {
+		p, err := NewPattern(c.Pattern)
+		if err != nil {
+			return FieldRules{}, err
+		}
+		rules = append(rules, p)
+	}
+	if len(c.Enum) > 0 {
+		rules = append(rules, Enum{Values: c.Enum})
+	}
+	if c.UTF8 {
+		rules = append(rules, UTF8{})
+	}
+
+	return Field(path, rules...), nil
+}
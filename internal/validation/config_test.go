@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		rules, err := Parse([]byte(`
+/test.Service/Method:
+  name:
+    required: true
+    max_length: 10
+    pattern: "^[a-z]+$"
+  label:
+    enum: [A, B]
+    utf8: true
+`))
+
+		require.NoError(t, err)
+		assert.Equal(t, MethodRules{
+			"/test.Service/Method": {
+				Field("label", Enum{Values: []string{"A", "B"}}, UTF8{}),
+				Field("name", Required{}, Length{Max: 10}, MustPattern("^[a-z]+$")),
+			},
+		}, rules)
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := Parse([]byte(`
+/test.Service/Method:
+  name:
+    pattern: "("
+`))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown rule", func(t *testing.T) {
+		_, err := Parse([]byte(`
+/test.Service/Method:
+  name:
+    max_lenght: 10
+`))
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max_lenght")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		rules, err := Parse(nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		_, err := Parse([]byte(`- a`))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"/template.v1.Echo/UnaryEcho": {"message": {"required": true}}}`), 0o600))
+
+	rules, err := LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, MethodRules{"/template.v1.Echo/UnaryEcho": {Field("message", Required{})}}, rules)
+
+	typo := filepath.Join(t.TempDir(), "typo.json")
+	require.NoError(t, ioutil.WriteFile(typo, []byte(`{"/template.v1.Echo/UnaryEcho": {"mesage": {"required": true}}}`), 0o600))
+	_, err = LoadFile(typo)
+	assert.EqualError(t, err, "invalid rules of /template.v1.Echo/UnaryEcho mesage: unknown field mesage of template.v1.UnaryEchoRequest")
+
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestMethodRulesCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules MethodRules
+		valid bool
+	}{
+		{name: "Valid", rules: MethodRules{"/template.v1.Echo/UnaryEcho": {Field("message", Required{})}}, valid: true},
+		{name: "Unknown field", rules: MethodRules{"/template.v1.Echo/UnaryEcho": {Field("name", Required{})}}},
+		{name: "Nested field of a scalar", rules: MethodRules{"/template.v1.Echo/UnaryEcho": {Field("message.length", Required{})}}},
+		{name: "Unknown method", rules: MethodRules{"/template.v1.Echo/Unknown": {Field("message", Required{})}}},
+		{name: "Unknown service", rules: MethodRules{"/test.Service/Method": {Field("name", Required{})}}},
+		{name: "Not a service", rules: MethodRules{"/template.v1.UnaryEchoRequest/Method": {Field("message", Required{})}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.Check()
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestMethodRulesMerge(t *testing.T) {
+	a := MethodRules{"/a/A": {Field("x", Required{})}}
+	b := MethodRules{"/a/A": {Field("y", UTF8{})}, "/b/B": {Field("z", Required{})}}
+
+	assert.Equal(t, MethodRules{
+		"/a/A": {Field("x", Required{}), Field("y", UTF8{})},
+		"/b/B": {Field("z", Required{})},
+	}, a.Merge(b))
+	assert.Len(t, a["/a/A"], 1, "Must not modify the receiver")
+}
@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Rule validates the value of a message field.
+//
+// The rules except Required are only checked when the field is set,
+// and they are checked against every element of a repeated field.
+type Rule interface {
+	// Check returns the description of the violation, or an empty string if the value is valid.
+	Check(fd protoreflect.FieldDescriptor, v protoreflect.Value) string
+}
+
+// Required checks the field is set, which means non-zero for proto3 scalars and non-empty for repeated fields.
+type Required struct{}
+
+// Check implements the Rule interface, Required is handled by the Validator because it needs the field presence.
+func (Required) Check(protoreflect.FieldDescriptor, protoreflect.Value) string {
+	return ""
+}
+
+// Length checks the length in bytes of a string or bytes field, zero means no limit.
+type Length struct {
+	Min int
+	Max int
+}
+
+// Check implements the Rule interface.
+func (r Length) Check(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	s, ok := stringValue(fd, v)
+	if !ok {
+		return ""
+	}
+
+	if r.Min > 0 && len(s) < r.Min {
+		return fmt.Sprintf("must be at least %d bytes long", r.Min)
+	}
+	if r.Max > 0 && len(s) > r.Max {
+		return fmt.Sprintf("must be at most %d bytes long", r.Max)
+	}
+
+	return ""
+}
+
+// Pattern checks a string or bytes field matches the regular expression.
+type Pattern struct {
+	re *regexp.Regexp
+}
+
+// NewPattern returns a Pattern rule of the given regular expression.
+func NewPattern(expr string) (Pattern, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return Pattern{}, fmt.Errorf("invalid pattern %q: %w", expr, err)
+	}
+
+	return Pattern{re: re}, nil
+}
+
+// MustPattern is like NewPattern but panics if the expression cannot be parsed.
+func MustPattern(expr string) Pattern {
+	p, err := NewPattern(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Check implements the Rule interface.
+func (r Pattern) Check(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	s, ok := stringValue(fd, v)
+	if !ok || r.re == nil {
+		return ""
+	}
+
+	if !r.re.MatchString(s) {
+		return fmt.Sprintf("must match the pattern %q", r.re.String())
+	}
+
+	return ""
+}
+
+// Enum checks the field is one of the given values, the names are used for enum fields.
+type Enum struct {
+	Values []string
+}
+
+// Check implements the Rule interface.
+func (r Enum) Check(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	s, ok := stringValue(fd, v)
+	if !ok {
+		return ""
+	}
+
+	for _, allowed := range r.Values {
+		if s == allowed {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("must be one of: %s", strings.Join(r.Values, ", "))
+}
+
+// UTF8 checks a string or bytes field is valid UTF-8.
+type UTF8 struct{}
+
+// Check implements the Rule interface.
+func (UTF8) Check(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	s, ok := stringValue(fd, v)
+	if !ok {
+		return ""
+	}
+
+	if !utf8.ValidString(s) {
+		return "must be a valid UTF-8 string"
+	}
+
+	return ""
+}
+
+// stringValue returns the value as a string for the string, bytes and enum fields.
+func stringValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (string, bool) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return v.String(), true
+	case protoreflect.BytesKind:
+		return string(v.Bytes()), true
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name()), true
+		}
+
+		return fmt.Sprintf("%d", v.Enum()), true
+	default:
+		return "", false
+	}
+}
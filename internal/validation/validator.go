@@ -0,0 +1,179 @@
+// Package validation validates the gRPC request messages against declarative rules bound to the method fields.
+package validation
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+var validationFailures = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "grpc_validation_failures_total",
+		Help: "Total number of request field validation failures.",
+	},
+	[]string{"grpc_service", "grpc_method", "field"},
+)
+
+// Validator validates the requests against the rules of the methods.
+type Validator struct {
+	rules MethodRules
+}
+
+// NewValidator returns a new validator of the given rules.
+func NewValidator(rules MethodRules) *Validator {
+	return &Validator{
+		rules: rules,
+	}
+}
+
+// Validate validates the request of the full method name,
+// returns an invalid argument error with all the field violations if any rule failed.
+func (v *Validator) Validate(fullMethod string, req interface{}) error {
+	fields, ok := v.rules[fullMethod]
+	if !ok {
+		return nil
+	}
+
+	m, ok := protoutil.Reflect(req)
+	if !ok {
+		return nil
+	}
+
+	violations := validateMessage(m, fields)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	service, method := protoutil.SplitMethodName(fullMethod)
+	for _, fv := range violations {
+		validationFailures.With(prometheus.Labels{
+			"grpc_service": strings.ToLower(service),
+			"grpc_method":  strings.ToLower(method),
+			"field":        fv.Field,
+		}).Inc()
+	}
+
+	return grpcerr.NewBadRequest("Request validation failed", violations...)
+}
+
+// UnaryServerInterceptor returns a unary interceptor that validates the requests.
+func (v *Validator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := v.Validate(info.FullMethod, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream interceptor that validates every message received from the stream.
+func (v *Validator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := v.rules[info.FullMethod]; !ok {
+			return handler(srv, ss)
+		}
+
+		return handler(srv, &validatingStream{
+			ServerStream: ss,
+			validator:    v,
+			fullMethod:   info.FullMethod,
+		})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+
+	validator  *Validator
+	fullMethod string
+}
+
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.validator.Validate(s.fullMethod, m)
+}
+
+func validateMessage(m protoreflect.Message, fields []FieldRules) []grpcerr.FieldViolation {
+	var violations []grpcerr.FieldViolation
+	for _, f := range fields {
+		fd, values, present := resolveField(m, f.Field)
+		if fd == nil {
+			continue
+		}
+
+		for _, rule := range f.Rules {
+			if _, ok := rule.(Required); ok {
+				if !present {
+					violations = append(violations, grpcerr.FieldViolation{Field: f.Field, Description: "is required"})
+				}
+
+				continue
+			}
+
+			for _, value := range values {
+				if desc := rule.Check(fd, value); desc != "" {
+					violations = append(violations, grpcerr.FieldViolation{Field: f.Field, Description: desc})
+
+					break
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// resolveField returns the descriptor and the values of the field path,
+// the values are empty when the field is not set, or contain every element for a repeated field.
+func resolveField(m protoreflect.Message, path string) (protoreflect.FieldDescriptor, []protoreflect.Value, bool) {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, nil, false
+		}
+
+		present := m.Has(fd)
+		if i < len(names)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return nil, nil, false
+			}
+			if !present {
+				return fd, nil, false
+			}
+			m = m.Get(fd).Message()
+
+			continue
+		}
+
+		if !present {
+			return fd, nil, false
+		}
+
+		if fd.IsList() {
+			list := m.Get(fd).List()
+			values := make([]protoreflect.Value, 0, list.Len())
+			for j := 0; j < list.Len(); j++ {
+				values = append(values, list.Get(j))
+			}
+
+			return fd, values, true
+		}
+
+		return fd, []protoreflect.Value{m.Get(fd)}, true
+	}
+
+	return nil, nil, false
+}
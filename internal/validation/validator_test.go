@@ -0,0 +1,182 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	templatev1 "github.com/sliide/template-grpc-service/api/template/v1"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/metrictest"
+)
+
+const testMethod = "/test.Service/Method"
+
+func TestValidatorValidate(t *testing.T) {
+	v := NewValidator(MethodRules{
+		testMethod: {
+			Field("name", Required{}, Length{Min: 2, Max: 5}, MustPattern("^[a-z]+$")),
+			Field("label", Enum{Values: []string{"LABEL_OPTIONAL", "LABEL_REQUIRED"}}),
+			Field("options.packed", Required{}),
+			Field("json_name", UTF8{}),
+		},
+	})
+
+	tests := []struct {
+		name     string
+		req      *descriptorpb.FieldDescriptorProto
+		expected []grpcerr.FieldViolation
+	}{
+		{
+			name: "valid",
+			req: &descriptorpb.FieldDescriptorProto{
+				Name:    proto.String("abc"),
+				Label:   descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Options: &descriptorpb.FieldOptions{Packed: proto.Bool(true)},
+			},
+		},
+		{
+			name: "all violations are returned",
+			req: &descriptorpb.FieldDescriptorProto{
+				Name:     proto.String("ABCDEF"),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				JsonName: proto.String("\xff"),
+			},
+			expected: []grpcerr.FieldViolation{
+				{Field: "name", Description: "must be at most 5 bytes long"},
+				{Field: "name", Description: `must match the pattern "^[a-z]+$"`},
+				{Field: "label", Description: "must be one of: LABEL_OPTIONAL, LABEL_REQUIRED"},
+				{Field: "options.packed", Description: "is required"},
+				{Field: "json_name", Description: "must be a valid UTF-8 string"},
+			},
+		},
+		{
+			name: "rules other than required are skipped for unset fields",
+			req: &descriptorpb.FieldDescriptorProto{
+				Options: &descriptorpb.FieldOptions{Packed: proto.Bool(false)},
+			},
+			expected: []grpcerr.FieldViolation{
+				{Field: "name", Description: "is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(testMethod, tt.req)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Contains(t, grpcerr.Details(err), grpcerr.BadRequest{FieldViolations: tt.expected})
+		})
+	}
+
+	t.Run("repeated field", func(t *testing.T) {
+		v := NewValidator(MethodRules{
+			testMethod: {Field("dependency", Required{}, Length{Max: 3})},
+		})
+
+		assert.NoError(t, v.Validate(testMethod, &descriptorpb.FileDescriptorProto{Dependency: []string{"a", "b"}}))
+
+		err := v.Validate(testMethod, &descriptorpb.FileDescriptorProto{Dependency: []string{"a", "long"}})
+		assert.Contains(t, grpcerr.Details(err), grpcerr.BadRequest{FieldViolations: []grpcerr.FieldViolation{
+			{Field: "dependency", Description: "must be at most 3 bytes long"},
+		}})
+
+		err = v.Validate(testMethod, &descriptorpb.FileDescriptorProto{})
+		assert.Contains(t, grpcerr.Details(err), grpcerr.BadRequest{FieldViolations: []grpcerr.FieldViolation{
+			{Field: "dependency", Description: "is required"},
+		}})
+	})
+
+	t.Run("method without rules", func(t *testing.T) {
+		assert.NoError(t, v.Validate("/test.Service/Other", &descriptorpb.FieldDescriptorProto{}))
+	})
+
+	t.Run("failures are counted", func(t *testing.T) {
+		counter := validationFailures.With(prometheus.Labels{
+			"grpc_service": "test.service",
+			"grpc_method":  "method",
+			"field":        "options.packed",
+		})
+		before := metrictest.CounterValue(t, counter)
+
+		_ = v.Validate(testMethod, &descriptorpb.FieldDescriptorProto{Name: proto.String("abc")})
+
+		assert.Equal(t, before+1, metrictest.CounterValue(t, counter))
+	})
+}
+
+func TestValidatorUnaryServerInterceptor(t *testing.T) {
+	v := NewValidator(MethodRules{
+		"/template.v1.Echo/UnaryEcho": {Field("message", Required{})},
+	})
+	info := &grpc.UnaryServerInfo{FullMethod: "/template.v1.Echo/UnaryEcho"}
+
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+
+		return req, nil
+	}
+
+	_, err := v.UnaryServerInterceptor()(context.Background(), &templatev1.UnaryEchoRequest{}, info, handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.False(t, called, "Must not call the handler with an invalid request")
+
+	_, err = v.UnaryServerInterceptor()(context.Background(), &templatev1.UnaryEchoRequest{Message: "hi"}, info, handler)
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestValidatorStreamServerInterceptor(t *testing.T) {
+	v := NewValidator(MethodRules{
+		"/template.v1.Echo/ClientStreamingEcho": {Field("message", Required{})},
+	})
+	info := &grpc.StreamServerInfo{FullMethod: "/template.v1.Echo/ClientStreamingEcho"}
+	ss := &fakeServerStream{messages: []string{"first", ""}}
+
+	var received []string
+	err := v.StreamServerInterceptor()(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+		for {
+			m := &templatev1.ClientStreamingEchoRequest{}
+			if err := stream.RecvMsg(m); err != nil {
+				return err
+			}
+			received = append(received, m.GetMessage())
+		}
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, []string{"first"}, received)
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+
+	messages []string
+}
+
+func (s *fakeServerStream) RecvMsg(m interface{}) error {
+	if len(s.messages) == 0 {
+		return status.Error(codes.OutOfRange, "EOF")
+	}
+
+	m.(*templatev1.ClientStreamingEchoRequest).Message = s.messages[0]
+	s.messages = s.messages[1:]
+
+	return nil
+}
@@ -1,26 +1,46 @@
 package main
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"os"
 	"os/signal"
 	"runtime"
-	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 	_ "google.golang.org/grpc/encoding/gzip"
 	"gorm.io/gorm"
 
 	"github.com/sliide/logstash"
 	healthcheck "github.com/sliide/service-healthcheck"
 	"github.com/sliide/shared-go-libs/metric/prometheus"
+	"github.com/sliide/template-grpc-service/internal/accesslog"
+	"github.com/sliide/template-grpc-service/internal/admin"
+	"github.com/sliide/template-grpc-service/internal/audit"
+	"github.com/sliide/template-grpc-service/internal/cache"
+	"github.com/sliide/template-grpc-service/internal/capture"
+	"github.com/sliide/template-grpc-service/internal/chaos"
+	"github.com/sliide/template-grpc-service/internal/clientip"
 	"github.com/sliide/template-grpc-service/internal/configs"
+	"github.com/sliide/template-grpc-service/internal/deprecation"
+	"github.com/sliide/template-grpc-service/internal/geo"
 	"github.com/sliide/template-grpc-service/internal/grpcd"
+	"github.com/sliide/template-grpc-service/internal/inflight"
+	"github.com/sliide/template-grpc-service/internal/ipfilter"
+	"github.com/sliide/template-grpc-service/internal/logsampling"
+	"github.com/sliide/template-grpc-service/internal/payloadlog"
+	"github.com/sliide/template-grpc-service/internal/rotate"
+	"github.com/sliide/template-grpc-service/internal/rpcz"
+	"github.com/sliide/template-grpc-service/internal/shadow"
+	"github.com/sliide/template-grpc-service/internal/tenant"
+	"github.com/sliide/template-grpc-service/internal/validation"
 )
 
 const (
@@ -37,15 +57,70 @@ var (
 type resources struct {
 	// this db is used to access datastore(s) used by this service only.
 	db *gorm.DB
+
+	// accessLog writes the access log of the server and the monitoring endpoints, nil if disabled.
+	accessLog *accesslog.Logger
+
+	// rpcz keeps the recent calls of the server shown in the monitoring endpoints, nil if disabled.
+	rpcz *rpcz.Store
+
+	// inflight keeps the calls of the server currently running.
+	inflight *inflight.Registry
+
+	// ipFilter enforces the IP allow and deny lists of the listeners, nil if disabled.
+	ipFilter *ipfilter.Filter
+
+	// tenancy identifies the tenants of the calls and enforces their quotas, nil if disabled.
+	tenancy *tenant.Tenancy
+
+	// chaos injects faults into the calls, nil if disabled.
+	chaos *chaos.Injector
+
+	// shadow mirrors the unary calls to the shadow target, nil if disabled.
+	shadow *shadow.Shadow
+
+	// capture records a sample of the unary calls into the capture file, nil if disabled.
+	capture *capture.Recorder
+
+	// geoIP looks up the geo locations of the client IPs, nil if disabled.
+	geoIP *geo.DB
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == auditVerifyCommand {
+		if err := runAuditVerify(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == replayCommand {
+		if err := runReplay(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == mockCommand {
+		if err := runMock(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	sys, err := configs.Load()
 	if err != nil {
 		logrus.WithError(err).Fatalf("Failed to load system config")
 	}
 
-	if err := initLogstash(sys); err != nil {
+	sampler, err := initLogstash(sys)
+	if err != nil {
 		logrus.WithError(err).Fatalf("Failed to initialise logstash")
 	}
 
@@ -59,11 +134,15 @@ func main() {
 		logrus.WithError(err).Fatalf("Failed to initialise server")
 	}
 
-	if err := initMonitoring(sys, s, res); err != nil {
+	ops := newOperations(sys, s)
+
+	if err := initMonitoring(sys, s, res, ops); err != nil {
 		logrus.WithError(err).Fatalf("Failed to initialise monitoring")
 	}
 
-	// Run the server
+	as := initAdminServer(sys, res, ops)
+
+	// Run the servers
 	go func() {
 		err := s.ListenAndServe()
 		if err != nil {
@@ -71,27 +150,163 @@ func main() {
 		}
 	}()
 
-	// Wait terminal signal
+	if as != nil {
+		go func() {
+			err := as.ListenAndServe()
+			if err != nil {
+				logrus.WithError(err).Fatalf("Failed to listen and serve the admin server")
+			}
+		}()
+	}
+
+	// Wait terminal signal, or the drain triggered by admin
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
-	<-signals
+	select {
+	case <-signals:
+	case <-ops.drained:
+	}
 
 	logrus.Info("Stopping server")
 
 	s.GracefulStop()
+	if as != nil {
+		as.GracefulStop()
+	}
+
+	// The summary of the last sampling window is logged once the calls are completed.
+	sampler.Stop()
 }
 
-func initLogstash(sys configs.Config) error {
-	return logstash.InitWithOutput(
+// initLogstash initialises the logs, and returns the sampler of the logs, nil if the sampling is disabled.
+func initLogstash(sys configs.Config) (*logsampling.Sampler, error) {
+	if err := logstash.InitWithOutput(
 		sys.LogLevel,
 		sys.Env,
 		sys.Service,
 		os.Stdout,
-	)
+	); err != nil {
+		return nil, err
+	}
+
+	if sys.LogSamplingFirst <= 0 {
+		return nil, nil
+	}
+
+	return logsampling.Wrap(logrus.StandardLogger(), logsampling.Params{
+		First:      sys.LogSamplingFirst,
+		Thereafter: sys.LogSamplingThereafter,
+		Interval:   sys.LogSamplingInterval,
+	}), nil
 }
 
 func initResources(sys configs.Config) (*resources, error) {
-	return &resources{}, nil
+	res := &resources{
+		inflight: inflight.NewRegistry(),
+	}
+
+	if needsDB(sys) {
+		db, err := openDB(sys.RdsURL)
+		if err != nil {
+			return nil, err
+		}
+		res.db = db
+	}
+
+	if sys.RPCZSize > 0 {
+		res.rpcz = rpcz.NewStore(sys.RPCZSize)
+	}
+
+	if sys.IPFilterFile != "" {
+		cfg, err := ipfilter.LoadFile(sys.IPFilterFile)
+		if err != nil {
+			return nil, err
+		}
+
+		res.ipFilter, err = ipfilter.NewFilter(cfg)
+		if err != nil {
+			return nil, err
+		}
+		go res.ipFilter.Watch(sys.IPFilterFile, sys.IPFilterReloadInterval, nil)
+	}
+
+	if sys.TenantEnabled {
+		tenancy, err := initTenancy(sys, res.db)
+		if err != nil {
+			return nil, err
+		}
+		res.tenancy = tenancy
+	}
+
+	if sys.ChaosEnabled {
+		injector, err := initChaos(sys)
+		if err != nil {
+			return nil, err
+		}
+		res.chaos = injector
+	}
+
+	if sys.ShadowTarget != "" {
+		// The connection is lazy, the shadow target doesn't need to be up when starting
+		conn, err := grpc.Dial(sys.ShadowTarget, grpc.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial the shadow target: %w", err)
+		}
+		res.shadow, err = shadow.New(conn, shadow.Params{
+			Rate:           sys.ShadowRate,
+			Methods:        sys.ShadowMethods,
+			MetadataKeys:   sys.ShadowMetadataKeys,
+			Timeout:        sys.ShadowTimeout,
+			MaxConcurrency: sys.ShadowMaxConcurrency,
+			LogRate:        sys.ShadowLogRate,
+		})
+		if err != nil {
+			_ = conn.Close()
+
+			return nil, err
+		}
+	}
+
+	if sys.CaptureFile != "" {
+		f, err := rotate.Open(sys.CaptureFile, sys.CaptureMaxSize)
+		if err != nil {
+			return nil, err
+		}
+
+		res.capture = capture.NewRecorder(capture.Params{
+			Output:       f,
+			Rate:         sys.CaptureRate,
+			Methods:      sys.CaptureMethods,
+			MetadataKeys: sys.CaptureMetadataKeys,
+		})
+	}
+
+	if sys.GeoIPDBFile != "" {
+		db, err := geo.Open(sys.GeoIPDBFile)
+		if err != nil {
+			return nil, err
+		}
+		res.geoIP = db
+		go res.geoIP.Watch(sys.GeoIPDBFile, sys.GeoIPReloadInterval, nil)
+	}
+
+	if sys.AccessLogFile != "" {
+		f, err := rotate.Open(sys.AccessLogFile, sys.AccessLogMaxSize)
+		if err != nil {
+			return nil, err
+		}
+
+		res.accessLog, err = accesslog.NewLogger(accesslog.Params{
+			Output:     f,
+			Format:     sys.AccessLogFormat,
+			SampleRate: sys.AccessLogSampleRate,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
 }
 
 func initServer(sys configs.Config, res *resources) (*grpcd.Server, error) {
@@ -102,21 +317,189 @@ func initServer(sys configs.Config, res *resources) (*grpcd.Server, error) {
 		ListenAddr: listenAddr,
 		DB:         res.db,
 	}
-	cfg := grpcd.NewServerConfigs(params,
-		grpcd.SetLogger(l.WithField("service_version", fmt.Sprintf("%s (%s)", Version, runtime.Version()))))
+	opts := []grpcd.ServerConfigsOpts{
+		grpcd.SetLogger(l.WithField("service_version", fmt.Sprintf("%s (%s)", Version, runtime.Version()))),
+		grpcd.SetTenancy(res.tenancy),
+		grpcd.SetIPFilter(res.ipFilter),
+		grpcd.SetAccessLogger(res.accessLog),
+		grpcd.SetRPCZ(res.rpcz),
+		grpcd.SetInFlightRegistry(res.inflight),
+		grpcd.SetChaos(res.chaos),
+		grpcd.SetShadow(res.shadow),
+		grpcd.SetCapture(res.capture),
+		grpcd.SetIdempotentMethods(sys.IdempotentMethods...),
+		grpcd.SetIdempotencyTTL(sys.IdempotencyTTL),
+		grpcd.SetIdempotencyLease(sys.IdempotencyLease),
+		grpcd.SetCacheMethods(sys.CacheMethods...),
+		grpcd.SetCacheTTL(sys.CacheTTL),
+		grpcd.SetCacheMetadataKeys(sys.CacheMetadataKeys...),
+		grpcd.SetCacheShared(sys.CacheShared),
+		grpcd.SetCacheBackend(cache.NewLRU(sys.CacheMaxEntries, sys.CacheMaxBytes, cache.CountEviction)),
+	}
+
+	sunsets, err := deprecation.ParseSunsets(sys.DeprecationSunsets)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpcd.SetSunsets(sunsets), grpcd.SetDeprecationUserAgents(sys.DeprecationUserAgents...))
+
+	methodRates, err := payloadlog.ParseMethodRates(sys.PayloadLogMethodRates)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, grpcd.SetPayloadLogging(payloadlog.Params{
+		Rate:         sys.PayloadLogRate,
+		MethodRates:  methodRates,
+		RedactFields: sys.PayloadLogRedactFields,
+		MaxBytes:     sys.PayloadLogMaxBytes,
+	}))
+
+	listeners, err := initListeners(sys)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range listeners {
+		opts = append(opts, grpcd.AddListener(l))
+	}
+
+	opts = append(opts, grpcd.SetAuditMethods(sys.AuditMethods...), grpcd.SetAuditKey([]byte(sys.AuditKey)))
+	if sys.AuditFile != "" {
+		sink, err := audit.NewFileSink(sys.AuditFile, []byte(sys.AuditKey))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpcd.SetAuditSink(sink))
+	}
+
+	if sys.ValidationRulesFile != "" {
+		rules, err := validation.LoadFile(sys.ValidationRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpcd.AddValidationRules(rules))
+	}
+
+	// Keep the GeoIP database option unset if disabled, the interface must not hold a nil *geo.DB
+	if res.geoIP != nil {
+		opts = append(opts, grpcd.SetGeoIPDB(res.geoIP))
+	}
+	if sys.GeoIPCountryRulesFile != "" {
+		rules, err := geo.LoadRulesFile(sys.GeoIPCountryRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpcd.SetCountryRules(rules))
+	}
+
+	cfg := grpcd.NewServerConfigs(params, opts...)
 
 	logrus.WithFields(logrus.Fields{
-		"listen_addr":  listenAddr,
-		"version":      Version,
-		"go_version":   runtime.Version(),
-		"git_revision": GitRevision,
-		"git_branch":   GitBranch,
+		"listen_addr":          listenAddr,
+		"internal_listen_addr": sys.InternalListenAddr,
+		"version":              Version,
+		"go_version":           runtime.Version(),
+		"git_revision":         GitRevision,
+		"git_branch":           GitBranch,
 	}).Info("Starting server")
 
 	return grpcd.NewServer(cfg)
 }
 
-func initMonitoring(sys configs.Config, s *grpcd.Server, res *resources) error {
+// needsDB returns true if an enabled feature stores its state in the DB, the DB isn't opened otherwise.
+func needsDB(sys configs.Config) bool {
+	return sys.TenantEnabled || len(sys.IdempotentMethods) > 0 || (len(sys.AuditMethods) > 0 && sys.AuditFile == "")
+}
+
+// initTenancy returns the tenancy of the server, the usage is persisted in the DB.
+func initTenancy(sys configs.Config, db *gorm.DB) (*tenant.Tenancy, error) {
+	store, err := tenant.NewGormStore(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotas tenant.Quotas
+	if sys.TenantQuotasFile != "" {
+		if quotas, err = tenant.LoadQuotasFile(sys.TenantQuotasFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return tenant.New(tenant.Params{
+		Header:   sys.TenantHeader,
+		Claim:    sys.TenantClaim,
+		Required: sys.TenantRequired,
+		Quotas:   quotas,
+		Store:    store,
+	}), nil
+}
+
+// initChaos returns the fault injector, or nil if refused in production.
+func initChaos(sys configs.Config) (*chaos.Injector, error) {
+	var cfg chaos.Config
+	if sys.ChaosRulesFile != "" {
+		var err error
+		if cfg, err = chaos.LoadFile(sys.ChaosRulesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	injector, err := chaos.New(chaos.Params{
+		Env:       sys.Env,
+		AllowProd: sys.ChaosAllowProd,
+		Config:    cfg,
+	})
+	if errors.Is(err, chaos.ErrProdNotAllowed) {
+		logrus.WithError(err).Error("Fault injection disabled, set CHAOS_ALLOW_PROD to enable it in production")
+
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithField("rules", len(cfg.Rules)).Warn("Fault injection enabled")
+
+	return injector, nil
+}
+
+// initListeners returns the listeners of the server, a single listener trusting the trace IDs from the requests,
+// or a public listener and a trusted internal listener if the internal listener is configured.
+func initListeners(sys configs.Config) ([]grpcd.Listener, error) {
+	trustedProxies, err := clientip.ParseCIDRs(sys.TrustedProxyCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	public := grpcd.DefaultListener(sys.ListenAddr)
+	public.TrustedProxies = trustedProxies
+	public.ProxyProtocol = sys.ProxyProtocol
+	public.Services = sys.PublicServices
+	if sys.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(sys.TLSCertFile, sys.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public.TLS = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	if sys.InternalListenAddr == "" {
+		return []grpcd.Listener{public}, nil
+	}
+
+	public.Name = "public"
+	public.Entry.AllowTraceIDFromRequest = false
+
+	internal := grpcd.DefaultListener(sys.InternalListenAddr)
+	internal.Name = "internal"
+	internal.TrustedProxies = trustedProxies
+	internal.Services = sys.InternalServices
+
+	return []grpcd.Listener{public, internal}, nil
+}
+
+func initMonitoring(sys configs.Config, s *grpcd.Server, res *resources, ops *operations) error {
 	// We don't need to check the monitoring endpoints are working or not,
 	// the external monitoring tools (e.g. Sensu) will raise warnings
 	// if cannot access these endpoints.
@@ -125,9 +508,6 @@ func initMonitoring(sys configs.Config, s *grpcd.Server, res *resources) error {
 	// We only check the service starts serving or not,
 	// do not need to care the service is 100% healthy,
 	// or all dependencies are working fine.
-	isReady := &atomic.Value{}
-	isReady.Store(false)
-
 	go func() {
 		for {
 			if s.Serving() {
@@ -136,7 +516,7 @@ func initMonitoring(sys configs.Config, s *grpcd.Server, res *resources) error {
 			time.Sleep(checkingInterval)
 		}
 		time.Sleep(1 * time.Second)
-		isReady.Store(true)
+		ops.isReady.Store(true)
 	}()
 
 	if sys.PprofEnabled {
@@ -164,26 +544,28 @@ func initMonitoring(sys configs.Config, s *grpcd.Server, res *resources) error {
 		GitBranch:   GitBranch,
 	})
 
-	logrus.AddHook(prometheus.NewLogsMetrics().Hook())
+	logrus.AddHook(logsampling.ExcludeSummaries(prometheus.NewLogsMetrics().Hook()))
 
 	go func() {
 		h := mux.NewRouter()
+		h.Use(res.accessLog.Middleware)
+
 		// Readiness endpoint for k8s
-		h.Handle("/ready", healthcheck.Readiness(isReady))
+		h.Handle("/ready", healthcheck.Readiness(ops.isReady))
 
 		// Health check endpoint
 		h.Handle("/", http.RedirectHandler("/healthcheck", http.StatusTemporaryRedirect))
-		h.Handle("/healthcheck", func() http.Handler {
-			hc := healthcheck.New(healthcheck.Params{
-				Service:     sys.Service,
-				Environment: sys.Env,
-				Version:     Version,
-			})
+		h.Handle("/healthcheck", healthcheck.HandlerWithLogger(ops.hc, logrus.NewEntry(logrus.StandardLogger())))
 
-			hc.AddCheck("http server", healthcheck.DaemonServingCheck(s))
+		// Recent calls debug pages
+		if res.rpcz != nil {
+			h.Handle("/debug/rpcz", res.rpcz.Handler())
+		}
 
-			return healthcheck.HandlerWithLogger(hc, logrus.NewEntry(logrus.StandardLogger()))
-		}())
+		// Admin endpoints
+		if sys.AdminToken != "" {
+			admin.RegisterHTTPHandlers(h, sys.AdminToken, res.inflight)
+		}
 
 		// Prometheus metrics endpoint
 		h.Handle("/metrics", prometheus.Handler())
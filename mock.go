@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/sliide/template-grpc-service/api"
+	"github.com/sliide/template-grpc-service/internal/grpcd"
+	"github.com/sliide/template-grpc-service/internal/mock"
+)
+
+// mockCommand is the command serving the mocks of the services.
+const mockCommand = "mock"
+
+// runMock serves the services of the API answering from the fixtures file given in the args, e.g.
+// `mock -addr 0.0.0.0:8080 -fixtures fixtures.yaml`, until it's interrupted.
+// The calls matching no fixture get the default responses, thus the fixtures are optional.
+func runMock(args []string) error {
+	fs := flag.NewFlagSet(mockCommand, flag.ContinueOnError)
+	addr := fs.String("addr", "0.0.0.0:8080", "address of the listener")
+	fixtures := fs.String("fixtures", "", "path of the YAML (or JSON) fixtures file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := mock.Config{}
+	if *fixtures != "" {
+		var err error
+		if cfg, err = mock.LoadFile(*fixtures); err != nil {
+			return fmt.Errorf("%s: %w", mockCommand, err)
+		}
+	}
+
+	services, err := api.Services()
+	if err != nil {
+		return fmt.Errorf("%s: %w", mockCommand, err)
+	}
+
+	// Allows the keepalive pings of the client package, as the service does
+	s := grpc.NewServer(grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+		MinTime:             grpcd.KeepaliveMinTime,
+		PermitWithoutStream: true,
+	}))
+	if err := mock.Register(s, services, cfg); err != nil {
+		return fmt.Errorf("%s: %w", mockCommand, err)
+	}
+	reflection.Register(s)
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("%s: failed to listen: %w", mockCommand, err)
+	}
+
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+
+		logrus.Info("Stopping mock server")
+		s.GracefulStop()
+	}()
+
+	logrus.WithField("addr", listener.Addr().String()).
+		WithField("fixtures", len(cfg.Fixtures)).
+		Info("Serving the mocks of the services")
+
+	return s.Serve(listener)
+}
@@ -0,0 +1,176 @@
+// Package client is the Go client of the service, dialing it with the recommended options: the default service config
+// retrying the transient failures, the opt-in hedging of the latency sensitive calls, the propagation of the trace ID,
+// the client-side Prometheus metrics and the errors decoded from the status details.
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+)
+
+// DefaultKeepalive pings the server after 30s without activity, within the enforcement policy of the server.
+var DefaultKeepalive = keepalive.ClientParameters{
+	Time:    30 * time.Second,
+	Timeout: 10 * time.Second,
+}
+
+// Client represents a connection to the service, with the clients of its services.
+type Client struct {
+	templatev2.EchoClient
+
+	conn *grpc.ClientConn
+}
+
+// Dial returns a client of the service at the target, e.g. "template-grpc-service:8080".
+// The connection is established in the background, the calls wait for it until their deadline.
+func Dial(target string, opts ...DialOpts) (*Client, error) {
+	cfg := newDialConfigs(opts...)
+
+	conn, err := grpc.Dial(target, cfg.dialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", target, err)
+	}
+
+	return &Client{
+		EchoClient: templatev2.NewEchoClient(conn),
+		conn:       conn,
+	}, nil
+}
+
+// Conn returns the connection of the client, e.g. to create the clients of other services of the same server.
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+// Close closes the connection, the pending calls are cancelled.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// dialConfigs represents the options of the connection.
+type dialConfigs struct {
+	tls           *tls.Config
+	keepalive     keepalive.ClientParameters
+	compression   bool
+	serviceConfig string
+	hedging       HedgingPolicy
+	userAgent     string
+	metrics       *Metrics
+	extra         []grpc.DialOption
+}
+
+// DialOpts represents an option of the connection.
+type DialOpts func(cfg *dialConfigs)
+
+func newDialConfigs(opts ...DialOpts) *dialConfigs {
+	cfg := &dialConfigs{
+		keepalive:     DefaultKeepalive,
+		serviceConfig: DefaultServiceConfig,
+		metrics:       defaultMetrics,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// SetTLS connects with TLS, the connection is insecure unless set.
+func SetTLS(tlsConfig *tls.Config) DialOpts {
+	return func(cfg *dialConfigs) {
+		cfg.tls = tlsConfig
+	}
+}
+
+// SetKeepalive overrides the DefaultKeepalive parameters, the server closes the connections pinging more often
+// than every 20s.
+func SetKeepalive(params keepalive.ClientParameters) DialOpts {
+	return func(cfg *dialConfigs) {
+		cfg.keepalive = params
+	}
+}
+
+// SetCompression compresses the requests with gzip, the server responds with the same compression.
+func SetCompression(enabled bool) DialOpts {
+	return func(cfg *dialConfigs) {
+		cfg.compression = enabled
+	}
+}
+
+// SetServiceConfig overrides the DefaultServiceConfig, e.g. to change the timeouts or the retry policy.
+// The service config is in the JSON format of https://github.com/grpc/grpc/blob/master/doc/service_config.md.
+func SetServiceConfig(serviceConfig string) DialOpts {
+	return func(cfg *dialConfigs) {
+		cfg.serviceConfig = serviceConfig
+	}
+}
+
+// SetHedging hedges the calls with the policy, e.g. the DefaultHedgingPolicy, the calls aren't hedged by default.
+func SetHedging(policy HedgingPolicy) DialOpts {
+	return func(cfg *dialConfigs) {
+		cfg.hedging = policy
+	}
+}
+
+// SetUserAgent sets the user agent of the calls, e.g. "my-app/1.2.3", identifying the caller in the metrics
+// of the server, such as the calls of the deprecated methods.
+func SetUserAgent(userAgent string) DialOpts {
+	return func(cfg *dialConfigs) {
+		cfg.userAgent = userAgent
+	}
+}
+
+// SetMetrics overrides the metrics of the calls, e.g. to register them into another registry.
+func SetMetrics(m *Metrics) DialOpts {
+	return func(cfg *dialConfigs) {
+		cfg.metrics = m
+	}
+}
+
+// SetDialOptions appends extra gRPC dial options, e.g. more interceptors, applied after the ones of the client.
+func SetDialOptions(opts ...grpc.DialOption) DialOpts {
+	return func(cfg *dialConfigs) {
+		cfg.extra = append(cfg.extra, opts...)
+	}
+}
+
+func (cfg *dialConfigs) dialOptions() []grpc.DialOption {
+	creds := grpc.WithInsecure()
+	if cfg.tls != nil {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(cfg.tls))
+	}
+
+	// The errors are decoded by the outermost interceptors, so the metrics and the hedging see the statuses
+	opts := []grpc.DialOption{
+		creds,
+		grpc.WithKeepaliveParams(cfg.keepalive),
+		grpc.WithDefaultServiceConfig(cfg.serviceConfig),
+		grpc.WithChainUnaryInterceptor(
+			unaryErrorInterceptor,
+			unaryTraceInterceptor,
+			cfg.metrics.UnaryClientInterceptor(),
+			cfg.hedging.UnaryClientInterceptor(),
+		),
+		grpc.WithChainStreamInterceptor(
+			streamErrorInterceptor,
+			streamTraceInterceptor,
+			cfg.metrics.StreamClientInterceptor(),
+		),
+	}
+	if cfg.compression {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	}
+	if cfg.userAgent != "" {
+		opts = append(opts, grpc.WithUserAgent(cfg.userAgent))
+	}
+
+	return append(opts, cfg.extra...)
+}
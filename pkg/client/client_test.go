@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	templatev2 "github.com/sliide/template-grpc-service/api/template/v2"
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+	"github.com/sliide/template-grpc-service/internal/metrictest"
+)
+
+// echoServer answers by message: "trace" returns the trace ID, "slow-first" delays the first call for 1s,
+// "in-progress" delays the first call for 200ms and aborts the others like a pending idempotency key,
+// "not-found" and "bad" fail with the error details, the other messages are echoed.
+type echoServer struct {
+	templatev2.UnimplementedEchoServer
+
+	calls int32
+}
+
+func (s *echoServer) UnaryEcho(ctx context.Context, req *templatev2.UnaryEchoRequest) (*templatev2.UnaryEchoResponse, error) {
+	call := atomic.AddInt32(&s.calls, 1)
+	_ = grpc.SetHeader(ctx, metadata.Pairs("call", string(rune('0'+call))))
+
+	switch req.GetMessage() {
+	case "trace":
+		md, _ := metadata.FromIncomingContext(ctx)
+		return &templatev2.UnaryEchoResponse{Message: md.Get(coremiddleware.MetaKeyTraceID)[0]}, nil
+	case "slow-first":
+		if call == 1 {
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+				return nil, status.FromContextError(ctx.Err()).Err()
+			}
+		}
+	case "in-progress":
+		if call > 1 {
+			return nil, grpcerr.New(grpcerr.ErrAborted, "IDEMPOTENCY_KEY_IN_PROGRESS", "request in progress")
+		}
+		time.Sleep(200 * time.Millisecond)
+	case "not-found":
+		return nil, grpcerr.New(grpcerr.ErrNotFound, "ITEM_NOT_FOUND", "item not found").
+			WithMetadata("id", "1").
+			WithRetryDelay(time.Second).
+			WithLocalizedMessage("en-US", "The item doesn't exist")
+	case "bad":
+		return nil, grpcerr.NewBadRequest("bad request", grpcerr.FieldViolation{Field: "message", Description: "bad"})
+	}
+
+	return &templatev2.UnaryEchoResponse{Message: req.GetMessage()}, nil
+}
+
+func (s *echoServer) ServerStreamingEcho(req *templatev2.ServerStreamingEchoRequest, stream templatev2.Echo_ServerStreamingEchoServer) error {
+	if err := stream.Send(&templatev2.ServerStreamingEchoResponse{Message: req.GetMessage()}); err != nil {
+		return err
+	}
+	if req.GetMessage() == "fail" {
+		return grpcerr.New(grpcerr.ErrUnavailable, "", "stream failed")
+	}
+
+	return nil
+}
+
+func dialEcho(t *testing.T, opts ...DialOpts) (*Client, *echoServer, *Metrics) {
+	listener := bufconn.Listen(1024 * 1024)
+	srv := &echoServer{}
+	s := grpc.NewServer()
+	templatev2.RegisterEchoServer(s, srv)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	opts = append([]DialOpts{
+		SetMetrics(metrics),
+		SetDialOptions(grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		})),
+	}, opts...)
+
+	c, err := Dial("bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+
+	return c, srv, metrics
+}
+
+func TestDial(t *testing.T) {
+	c, _, _ := dialEcho(t, SetCompression(true), SetUserAgent("client-test/1.0.0"))
+
+	resp, err := c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp.GetMessage())
+
+	_, err = Dial("bufnet", SetServiceConfig(`{"methodConfig": "invalid"}`))
+	assert.Error(t, err)
+}
+
+func TestTraceID(t *testing.T) {
+	c, _, _ := dialEcho(t)
+
+	resp, err := c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "trace"})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetMessage(), 36, "a new UUID is expected")
+
+	traceID := "a0e7c5c4-9b8f-4f6c-8d0c-7a1b2c3d4e5f"
+	ctx := coremiddleware.NewContextWithTraceID(context.Background(), traceID)
+	resp, err = c.UnaryEcho(ctx, &templatev2.UnaryEchoRequest{Message: "trace"})
+	require.NoError(t, err)
+	assert.Equal(t, traceID, resp.GetMessage())
+}
+
+func TestErrors(t *testing.T) {
+	c, _, _ := dialEcho(t)
+
+	_, err := c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "not-found"})
+	e, ok := FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, &Error{
+		Code:             codes.NotFound,
+		Message:          "item not found",
+		Reason:           "ITEM_NOT_FOUND",
+		Metadata:         map[string]string{"id": "1"},
+		RetryDelay:       time.Second,
+		Locale:           "en-US",
+		LocalizedMessage: "The item doesn't exist",
+		status:           e.status,
+	}, e)
+
+	_, err = c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "bad"})
+	e, ok = FromError(err)
+	require.True(t, ok)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+	assert.Equal(t, "INVALID_ARGUMENT", e.Reason)
+	assert.Equal(t, []FieldViolation{{Field: "message", Description: "bad"}}, e.FieldViolations)
+
+	t.Run("Stream", func(t *testing.T) {
+		stream, err := c.ServerStreamingEcho(context.Background(), &templatev2.ServerStreamingEchoRequest{Message: "fail"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		e, ok := FromError(err)
+		require.True(t, ok)
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.Equal(t, "stream failed", e.Message)
+	})
+
+	t.Run("End of stream", func(t *testing.T) {
+		stream, err := c.ServerStreamingEcho(context.Background(), &templatev2.ServerStreamingEchoRequest{Message: "ok"})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestHedging(t *testing.T) {
+	policy := HedgingPolicy{
+		Methods:       []string{"/template.v2.Echo/"},
+		MaxAttempts:   3,
+		Delay:         20 * time.Millisecond,
+		NonFatalCodes: []codes.Code{codes.Unavailable},
+	}
+
+	t.Run("Hedged", func(t *testing.T) {
+		c, srv, _ := dialEcho(t, SetHedging(policy))
+
+		start := time.Now()
+		var header metadata.MD
+		resp, err := c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "slow-first"}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond))
+		assert.Equal(t, "slow-first", resp.GetMessage())
+		assert.Equal(t, []string{"2"}, header.Get("call"), "the header of the hedged attempt is expected")
+		assert.Equal(t, int32(2), atomic.LoadInt32(&srv.calls))
+	})
+
+	t.Run("Fatal error", func(t *testing.T) {
+		c, srv, _ := dialEcho(t, SetHedging(policy))
+
+		_, err := c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "not-found"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&srv.calls))
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		c, srv, _ := dialEcho(t, SetHedging(DefaultHedgingPolicy))
+
+		var header metadata.MD
+		resp, err := c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "in-progress"}, grpc.Header(&header))
+		require.NoError(t, err, "Must wait for the pending attempt")
+		assert.Equal(t, "in-progress", resp.GetMessage())
+		assert.Equal(t, []string{"1"}, header.Get("call"))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&srv.calls))
+	})
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		c, srv, _ := dialEcho(t)
+
+		start := time.Now()
+		_, err := c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "slow-first"})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Second))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&srv.calls))
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	c, _, metrics := dialEcho(t)
+
+	_, err := c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "hello"})
+	require.NoError(t, err)
+	_, err = c.UnaryEcho(context.Background(), &templatev2.UnaryEchoRequest{Message: "not-found"})
+	require.Error(t, err)
+
+	stream, err := c.ServerStreamingEcho(context.Background(), &templatev2.ServerStreamingEchoRequest{Message: "fail"})
+	require.NoError(t, err)
+	for err == nil {
+		_, err = stream.Recv()
+	}
+
+	count := func(method, code string) float64 {
+		return metrictest.CounterValue(t, metrics.total.WithLabelValues("template.v2.echo", method, code))
+	}
+	assert.Equal(t, float64(1), count("unaryecho", "ok"))
+	assert.Equal(t, float64(1), count("unaryecho", "notfound"))
+	assert.Equal(t, float64(1), count("serverstreamingecho", "unavailable"))
+}
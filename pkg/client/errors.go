@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sliide/template-grpc-service/internal/grpcerr"
+)
+
+// The error kinds of the failed calls, by their gRPC codes, e.g. `errors.Is(err, client.ErrNotFound)`.
+var (
+	ErrInvalidArgument    = grpcerr.ErrInvalidArgument
+	ErrNotFound           = grpcerr.ErrNotFound
+	ErrAlreadyExists      = grpcerr.ErrAlreadyExists
+	ErrPermissionDenied   = grpcerr.ErrPermissionDenied
+	ErrUnauthenticated    = grpcerr.ErrUnauthenticated
+	ErrFailedPrecondition = grpcerr.ErrFailedPrecondition
+	ErrAborted            = grpcerr.ErrAborted
+	ErrResourceExhausted  = grpcerr.ErrResourceExhausted
+	ErrUnavailable        = grpcerr.ErrUnavailable
+)
+
+// FieldViolation describes a bad field of the request.
+type FieldViolation = grpcerr.FieldViolation
+
+// Error represents a failed call, with the details decoded from its status.
+// The errors returned by the client are of this type, except the ones not coming from a status, thus
+// `status.Code(err)` still works on them.
+type Error struct {
+	Code    codes.Code
+	Message string
+
+	// Reason is the cause of the error in UPPER_SNAKE_CASE, from the google.rpc.ErrorInfo detail.
+	Reason string
+	// Domain is the service returning the error, from the google.rpc.ErrorInfo detail.
+	Domain string
+	// Metadata is the structured details of the error, from the google.rpc.ErrorInfo detail.
+	Metadata map[string]string
+	// FieldViolations are the bad fields of the request, from the google.rpc.BadRequest detail.
+	FieldViolations []FieldViolation
+	// RetryDelay is the time to wait before retrying, from the google.rpc.RetryInfo detail, zero if not set.
+	RetryDelay time.Duration
+	// Locale and LocalizedMessage are the message which could be shown to the end user, from the
+	// google.rpc.LocalizedMessage detail.
+	Locale           string
+	LocalizedMessage string
+
+	status *status.Status
+}
+
+// FromError returns the Error of the failed call, false if the error isn't the status of a call.
+func FromError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+
+	return nil, false
+}
+
+// decodeError returns the Error of the status error, or the error as is if it isn't a status.
+func decodeError(err error) error {
+	s, ok := status.FromError(err)
+	if err == nil || !ok {
+		return err
+	}
+
+	e := &Error{
+		Code:    s.Code(),
+		Message: s.Message(),
+		status:  s,
+	}
+	for _, d := range grpcerr.Details(err) {
+		switch d := d.(type) {
+		case grpcerr.ErrorInfo:
+			e.Reason, e.Domain, e.Metadata = d.Reason, d.Domain, d.Metadata
+		case grpcerr.BadRequest:
+			e.FieldViolations = append(e.FieldViolations, d.FieldViolations...)
+		case grpcerr.RetryInfo:
+			e.RetryDelay = d.RetryDelay
+		case grpcerr.LocalizedMessage:
+			e.Locale, e.LocalizedMessage = d.Locale, d.Message
+		}
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.status.Err().Error()
+}
+
+// Unwrap returns the error kind of the code, nil if none.
+func (e *Error) Unwrap() error {
+	return grpcerr.KindOfCode(e.Code)
+}
+
+// GRPCStatus returns the status of the call, which makes status.FromError and status.Code work on the error.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status
+}
+
+func unaryErrorInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return decodeError(invoker(ctx, method, req, reply, cc, opts...))
+}
+
+func streamErrorInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+	streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, decodeError(err)
+	}
+
+	return &decodedStream{ClientStream: stream}, nil
+}
+
+// decodedStream decodes the errors of the stream, the io.EOF ends are returned as is.
+type decodedStream struct {
+	grpc.ClientStream
+}
+
+func (s *decodedStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return decodeError(err)
+}
+
+func (s *decodedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return decodeError(err)
+}
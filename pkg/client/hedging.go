@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	protov1 "github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+// DefaultHedgingPolicy is the recommended policy of the UnaryEcho calls, which are idempotent and latency sensitive.
+// The calls aren't hedged by default, the policy must be set with SetHedging. The Aborted attempts are not fatal,
+// so an attempt conflicting with the idempotency key of the pending one waits for its response.
+var DefaultHedgingPolicy = HedgingPolicy{
+	Methods:       []string{"/template.v2.Echo/UnaryEcho"},
+	MaxAttempts:   2,
+	Delay:         50 * time.Millisecond,
+	NonFatalCodes: []codes.Code{codes.Unavailable, codes.Aborted},
+}
+
+// HedgingPolicy sends the unary calls again when they don't respond in time, and returns the first response,
+// cutting the tail latency at the cost of more load. Only the idempotent methods must be hedged.
+// It follows the hedging policy of the service config, which grpc-go doesn't implement.
+//
+// Every attempt is retried on its own by the retryPolicy of the service config, so a call reaches the server up to
+// MaxAttempts times the maxAttempts of the retryPolicy, e.g. 6 times with the DefaultServiceConfig and the
+// DefaultHedgingPolicy.
+type HedgingPolicy struct {
+	// Methods are the full method names, or the service prefixes ending with a slash, e.g. "/template.v2.Echo/".
+	Methods []string
+	// MaxAttempts is the maximum number of attempts of a call, including the first one, the calls aren't hedged if
+	// lower than 2.
+	MaxAttempts int
+	// Delay is the time waited for a response before sending the next attempt.
+	Delay time.Duration
+	// NonFatalCodes are the codes sending the next attempt immediately, the other codes fail the call.
+	NonFatalCodes []codes.Code
+}
+
+// applies returns true if the calls of the method are hedged.
+func (p HedgingPolicy) applies(fullMethod string) bool {
+	if p.MaxAttempts < 2 {
+		return false
+	}
+	for _, m := range p.Methods {
+		if m == fullMethod || (strings.HasSuffix(m, "/") && strings.HasPrefix(fullMethod, m)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p HedgingPolicy) nonFatal(err error) bool {
+	code := status.Code(err)
+	for _, c := range p.NonFatalCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attempt represents the result of an attempt of a hedged call.
+type attempt struct {
+	reply   proto.Message
+	header  metadata.MD
+	trailer metadata.MD
+	err     error
+}
+
+// UnaryClientInterceptor returns the interceptor hedging the unary calls of the methods of the policy.
+// Every attempt gets its own reply, header and trailer, the ones of the returned attempt are copied to the call.
+func (p HedgingPolicy) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		replyV1, ok := reply.(protov1.Message)
+		if !ok || !p.applies(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		// Cancels the pending attempts once the call returns
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		results := make(chan attempt, p.MaxAttempts)
+		send := func() {
+			a := attempt{reply: protov1.MessageV2(replyV1).ProtoReflect().New().Interface()}
+			attemptOpts := append(attemptCallOptions(opts), grpc.Header(&a.header), grpc.Trailer(&a.trailer))
+			a.err = invoker(ctx, method, req, protov1.MessageV1(a.reply), cc, attemptOpts...)
+			results <- a
+		}
+
+		timer := time.NewTimer(p.Delay)
+		defer timer.Stop()
+
+		var hedge <-chan time.Time
+		sent, pending := 0, 0
+		next := func() {
+			sent++
+			pending++
+			go send()
+
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			hedge = nil
+			if sent < p.MaxAttempts {
+				timer.Reset(p.Delay)
+				hedge = timer.C
+			}
+		}
+
+		next()
+		for {
+			select {
+			case <-hedge:
+				hedgedAttempts.WithLabelValues(protoutil.SplitMethodName(strings.ToLower(method))).Inc()
+				next()
+			case a := <-results:
+				pending--
+				if a.err != nil && p.nonFatal(a.err) {
+					if sent < p.MaxAttempts {
+						next()
+
+						continue
+					}
+					if pending > 0 {
+						continue
+					}
+				}
+
+				return finish(a, replyV1, opts)
+			}
+		}
+	}
+}
+
+// attemptCallOptions returns the call options without the header and trailer ones, which are set per attempt.
+func attemptCallOptions(opts []grpc.CallOption) []grpc.CallOption {
+	filtered := make([]grpc.CallOption, 0, len(opts)+2)
+	for _, o := range opts {
+		switch o.(type) {
+		case grpc.HeaderCallOption, grpc.TrailerCallOption:
+		default:
+			filtered = append(filtered, o)
+		}
+	}
+
+	return filtered
+}
+
+// finish copies the reply, header and trailer of the attempt to the call, returns the error of the attempt.
+func finish(a attempt, reply protov1.Message, opts []grpc.CallOption) error {
+	for _, o := range opts {
+		switch o := o.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = a.header
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = a.trailer
+		}
+	}
+	if a.err != nil {
+		return a.err
+	}
+
+	dst := protov1.MessageV2(reply)
+	proto.Reset(dst)
+	proto.Merge(dst, a.reply)
+
+	return nil
+}
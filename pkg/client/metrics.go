@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/sliide/template-grpc-service/internal/protoutil"
+)
+
+var (
+	defaultMetrics = NewMetrics(prometheus.DefaultRegisterer)
+
+	hedgedAttempts = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_client_hedged_attempts_total",
+			Help: "Total number of the extra attempts of the hedged gRPC calls.",
+		},
+		[]string{"grpc_service", "grpc_method"},
+	)
+)
+
+// Metrics represents the client-side metrics of the calls, labelled like the server-side ones.
+type Metrics struct {
+	total    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewMetrics returns the metrics of the calls registered into the registerer, the Dial uses the metrics registered
+// into the default registerer unless set with SetMetrics.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		total: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_client_requests_total",
+				Help: "Total number of gRPC calls made by the client, by their status code.",
+			},
+			[]string{"grpc_service", "grpc_method", "grpc_code"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_client_requests_duration_seconds",
+				Help:    "The gRPC call latencies in seconds seen by the client, until the end of the streams.",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"grpc_service", "grpc_method"},
+		),
+	}
+	registerer.MustRegister(m.total, m.duration)
+
+	return m
+}
+
+func (m *Metrics) observe(method string, start time.Time, err error) {
+	// The labels are lower-cased as in the server-side metrics, e.g. "template.v2.echo" and "unaryecho"
+	service, name := protoutil.SplitMethodName(strings.ToLower(method))
+	m.total.WithLabelValues(service, name, strings.ToLower(status.Code(err).String())).Inc()
+	m.duration.WithLabelValues(service, name).Observe(time.Since(start).Seconds())
+}
+
+// UnaryClientInterceptor returns the interceptor observing the unary calls.
+func (m *Metrics) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.observe(method, start, err)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor returns the interceptor observing the streams, when they end.
+// The streams abandoned without receiving their end are not observed.
+func (m *Metrics) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			m.observe(method, start, err)
+
+			return nil, err
+		}
+
+		return &observedStream{ClientStream: stream, desc: desc, metrics: m, method: method, start: start}, nil
+	}
+}
+
+// observedStream observes the stream when receiving its end or an error, or the response of the client streams.
+type observedStream struct {
+	grpc.ClientStream
+
+	desc     *grpc.StreamDesc
+	metrics  *Metrics
+	method   string
+	start    time.Time
+	observed bool
+}
+
+func (s *observedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if (err != nil || !s.desc.ServerStreams) && !s.observed {
+		s.observed = true
+		observed := err
+		if errors.Is(err, io.EOF) {
+			observed = nil
+		}
+		s.metrics.observe(s.method, s.start, observed)
+	}
+
+	return err
+}
@@ -0,0 +1,27 @@
+package client
+
+// DefaultServiceConfig is the default service config of the connections: the calls time out after 5s unless their
+// context has an earlier deadline, and the UNAVAILABLE failures are retried twice with an exponential backoff.
+// The retries are throttled while most of the calls fail, not to overload a struggling server.
+//
+// The retries of grpc-go v1.35 are disabled unless the GRPC_GO_RETRY=on env variable is set, the later versions enable
+// them by default. grpc-go doesn't implement the hedging policies of the service config, see HedgingPolicy instead,
+// the retries multiply the attempts of the hedged calls.
+const DefaultServiceConfig = `{
+  "loadBalancingConfig": [{"round_robin": {}}],
+  "methodConfig": [{
+    "name": [{"service": "template.v2.Echo"}, {"service": "template.v1.Echo"}],
+    "timeout": "5s",
+    "retryPolicy": {
+      "maxAttempts": 3,
+      "initialBackoff": "0.1s",
+      "maxBackoff": "1s",
+      "backoffMultiplier": 2,
+      "retryableStatusCodes": ["UNAVAILABLE"]
+    }
+  }],
+  "retryThrottling": {
+    "maxTokens": 10,
+    "tokenRatio": 0.5
+  }
+}`
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	coremiddleware "github.com/sliide/shared-go-libs/grpcd"
+	"google.golang.org/grpc"
+)
+
+// withTraceID returns the context with a new trace ID, unless it has one already: set by the Entry interceptor of the
+// services calling this one, or by the caller with `coremiddleware.NewContextWithTraceID`.
+func withTraceID(ctx context.Context) context.Context {
+	if coremiddleware.TraceID(ctx) != "" {
+		return ctx
+	}
+
+	return coremiddleware.NewContextWithTraceID(ctx, uuid.New().String())
+}
+
+func unaryTraceInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withTraceID(ctx), method, req, reply, cc, opts...)
+}
+
+func streamTraceInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+	streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withTraceID(ctx), desc, cc, method, opts...)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/sliide/template-grpc-service/internal/capture"
+)
+
+// replayCommand is the command replaying the capture files to an instance.
+const replayCommand = "replay"
+
+// runReplay replays the capture files given in the args to the target, e.g.
+// `replay -target localhost:8080 /var/log/capture.log`, returns an error if any call doesn't match the capture.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet(replayCommand, flag.ContinueOnError)
+	target := fs.String("target", "", "address of the instance receiving the calls")
+	timeout := fs.Duration("timeout", 5*time.Second, "time limit of each call")
+	verbose := fs.Bool("v", false, "print the matching calls too")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *target == "" || fs.NArg() == 0 {
+		return fmt.Errorf("%s: the -target flag and at least one capture file are required", replayCommand)
+	}
+
+	conn, err := grpc.Dial(*target, grpc.WithInsecure())
+	if err != nil {
+		return fmt.Errorf("%s: failed to dial the target: %w", replayCommand, err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	total, mismatches := 0, 0
+	for _, path := range fs.Args() {
+		records, err := capture.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", replayCommand, err)
+		}
+
+		for _, rec := range records {
+			ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+			res := capture.Replay(ctx, conn, rec)
+			cancel()
+
+			total++
+			if !res.Matched() {
+				mismatches++
+			}
+			if !res.Matched() || *verbose {
+				fmt.Println(res)
+			}
+		}
+	}
+	fmt.Printf("%d calls replayed, %d mismatches\n", total, mismatches)
+
+	if mismatches > 0 {
+		return fmt.Errorf("%s: %d of %d calls don't match the capture", replayCommand, mismatches, total)
+	}
+
+	return nil
+}